@@ -0,0 +1,208 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+
+	"gobox/internal/export"
+	"gobox/internal/probe"
+)
+
+// certOptions regroupe les options de certificat communes à wipe et erase.
+type certOptions struct {
+	dir      *string
+	key      *string
+	operator *string
+	disabled *bool
+}
+
+func addCertFlags(flags *flag.FlagSet) certOptions {
+	return certOptions{
+		dir:      flags.String("cert-dir", ".", "dossier des certificats d'effacement"),
+		key:      flags.String("key", export.DefaultSigningKeyPath(), "clé privée Ed25519 (créée si absente)"),
+		operator: flags.String("operator", defaultOperator(), "opérateur inscrit sur le certificat"),
+		disabled: flags.Bool("no-cert", false, "ne pas émettre de certificat"),
+	}
+}
+
+// defaultOperator est l'utilisateur ayant lancé sudo, sinon l'utilisateur courant.
+func defaultOperator() string {
+	if name := os.Getenv("SUDO_USER"); name != "" {
+		return name
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return ""
+}
+
+// diskIdentity relève l'identité du support avant effacement. Les liens
+// (/dev/disk/by-id/…) sont résolus comme pour l'effacement lui-même ; un
+// fichier image n'a que son chemin, la taille est complétée par le résultat.
+func diskIdentity(target string) probe.DiskInfo {
+	name := target
+	if strings.Contains(target, "/") {
+		resolved, err := filepath.EvalSymlinks(target)
+		if err != nil {
+			return probe.DiskInfo{Name: target}
+		}
+		if abs, err := filepath.Abs(resolved); err != nil || filepath.Dir(abs) != "/dev" {
+			return probe.DiskInfo{Name: target}
+		}
+		name = filepath.Base(resolved)
+	}
+	info, err := probe.GetDiskInfo(name)
+	if err != nil {
+		return probe.DiskInfo{Name: name}
+	}
+	return *info
+}
+
+// emit signe le certificat puis écrit gobox-cert-<série>-<id>.json et .pdf.
+func (o certOptions) emit(cert *export.ErasureCertificate) error {
+	key, err := export.LoadOrCreateSigningKey(*o.key)
+	if err != nil {
+		return err
+	}
+	if err := cert.Sign(key); err != nil {
+		return err
+	}
+
+	base := "gobox-cert-" + cert.ID
+	if cert.Disk.Serial != "" {
+		base = "gobox-cert-" + sanitizeFileName(cert.Disk.Serial) + "-" + cert.ID[:8]
+	}
+	if err := os.MkdirAll(*o.dir, 0o755); err != nil {
+		return err
+	}
+
+	jsonPath := filepath.Join(*o.dir, base+".json")
+	pdfPath := filepath.Join(*o.dir, base+".pdf")
+	if err := writeFileWith(jsonPath, func(f *os.File) error { return export.WriteCertificateJSON(f, cert) }); err != nil {
+		return err
+	}
+	if err := writeFileWith(pdfPath, func(f *os.File) error { return export.WriteCertificatePDF(f, cert) }); err != nil {
+		return err
+	}
+
+	fmt.Printf("Certificat signé : %s (+ %s)\n", jsonPath, filepath.Base(pdfPath))
+	fmt.Printf("Empreinte de clé : %s\n", cert.Signature.Fingerprint)
+	return nil
+}
+
+func writeFileWith(path string, write func(f *os.File) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return fmt.Errorf("écriture %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+func sanitizeFileName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == ' ' || r < 0x20 {
+			return '_'
+		}
+		return r
+	}, s)
+}
+
+// runVerifyCert implémente "gobox verify-cert -pubkey fichier
+// <certificat.json>". Sans clé de confiance, n'importe qui pourrait signer
+// un certificat avec sa propre clé : il faut alors -insecure, et seule
+// l'intégrité du document est vérifiée.
+func runVerifyCert(args []string) error {
+	flags := flag.NewFlagSet("verify-cert", flag.ExitOnError)
+	pubkeyPath := flags.String("pubkey", "", "clé publique de confiance (base64, voir \"gobox pubkey\")")
+	insecure := flags.Bool("insecure", false, "accepter toute clé : seule l'intégrité du document est vérifiée")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return errors.New("usage: gobox verify-cert -pubkey fichier|-insecure <certificat.json>")
+	}
+	if *pubkeyPath == "" && !*insecure {
+		return errors.New("clé de confiance requise (-pubkey), ou -insecure pour ne vérifier que l'intégrité")
+	}
+
+	f, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	cert, err := export.ReadCertificateJSON(f)
+	if err != nil {
+		return err
+	}
+
+	var trusted []ed25519.PublicKey
+	if *pubkeyPath != "" {
+		key, err := export.ReadPublicKey(*pubkeyPath)
+		if err != nil {
+			return err
+		}
+		trusted = append(trusted, key)
+	}
+
+	if err := cert.Verify(trusted...); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Certificat %s valide\n", cert.ID)
+	disk := strings.Join(strings.Fields(cert.Disk.Vendor+" "+cert.Disk.Model), " ")
+	if disk == "" {
+		disk = cert.Disk.Name
+	}
+	if cert.Disk.Serial != "" {
+		disk += " (n° " + cert.Disk.Serial + ")"
+	}
+	fmt.Printf("   Disque      : %s\n", disk)
+	fmt.Printf("   Effacement  : %s %s, vérification %s, terminé le %s\n",
+		cert.Sanitization, cert.Erasure.Method, cert.Erasure.Verification,
+		cert.Erasure.EndedAt.Format("02/01/2006 15:04"))
+	fmt.Printf("   Clé publique: %s\n", cert.Signature.PublicKey)
+	if len(trusted) == 0 {
+		fmt.Println("⚠️  Aucune clé de confiance fournie (-insecure) : l'origine du certificat n'est pas vérifiée.")
+	}
+	return nil
+}
+
+// runPubkey implémente "gobox pubkey [-key fichier] [-o fichier]" : exporte
+// la clé publique de signature du poste, à fournir à verify-cert -pubkey.
+func runPubkey(args []string) error {
+	flags := flag.NewFlagSet("pubkey", flag.ExitOnError)
+	keyPath := flags.String("key", export.DefaultSigningKeyPath(), "clé privée Ed25519 (créée si absente)")
+	output := flags.String("o", "", "fichier de sortie (défaut : sortie standard)")
+	flags.Parse(args)
+
+	if flags.NArg() != 0 {
+		return errors.New("usage: gobox pubkey [-key fichier] [-o fichier]")
+	}
+
+	key, err := export.LoadOrCreateSigningKey(*keyPath)
+	if err != nil {
+		return err
+	}
+	pub := key.Public().(ed25519.PublicKey)
+	encoded := export.EncodePublicKey(pub) + "\n"
+
+	if *output == "" {
+		fmt.Print(encoded)
+		return nil
+	}
+	if err := os.WriteFile(*output, []byte(encoded), 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("Clé publique : %s (empreinte %s)\n", *output, export.KeyFingerprint(pub))
+	return nil
+}
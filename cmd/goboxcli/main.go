@@ -1,9 +1,27 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"net"
 	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
 
+	"gobox/internal/capture"
+	"gobox/internal/diagnostic"
+	diagBattery "gobox/internal/diagnostic/battery"
+	diagDisk "gobox/internal/diagnostic/disk"
+	"gobox/internal/diagnostic/disk/operations"
+	"gobox/internal/diagnostic/network"
+	"gobox/internal/export"
+	"gobox/internal/probe"
 	display "gobox/internal/ui/display"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -38,7 +56,763 @@ func (m model) View() string {
 	return fmt.Sprintf("Count: %d\n", m.count)
 }
 
+// runCapture implémente "gobox capture [-o archive.tar.gz]".
+func runCapture(args []string) error {
+	flags := flag.NewFlagSet("capture", flag.ExitOnError)
+	output := flags.String("o", "gobox-capture.tar.gz", "archive de sortie")
+	flags.Parse(args)
+
+	if err := capture.WriteFile(*output); err != nil {
+		return err
+	}
+	fmt.Printf("Capture écrite : %s\n", *output)
+	return nil
+}
+
+// openReplay ouvre une archive, y redirige les sondes et présente son origine.
+func openReplay(archive string) (*capture.Replay, error) {
+	replay, err := capture.Open(archive)
+	if err != nil {
+		return nil, err
+	}
+	replay.Activate()
+
+	fmt.Printf("Rejeu de %s (capturé le %s sur %s, noyau %s)\n",
+		archive, replay.Meta.CapturedAt.Format("02/01/2006 15:04"),
+		replay.Meta.Hostname, replay.Meta.Kernel)
+	for _, w := range replay.Warnings {
+		fmt.Println("⚠️ ", w)
+	}
+	return replay, nil
+}
+
+// runReplay exécute toutes les sondes contre une archive, puis les
+// diagnostics par défaut valables hors ligne (batterie, disques).
+func runReplay(archive string) error {
+	replay, err := openReplay(archive)
+	if err != nil {
+		return err
+	}
+	defer replay.Close()
+
+	if err := display.DisplaySystemInfo(); err != nil {
+		fmt.Println("Erreur:", err)
+	}
+	display.DisplayCPUInfo()
+	display.DisplayRamInfo()
+	display.DisplayGPUInfo()
+	display.DiskInfo()
+	display.PrintNetworkInterfaces()
+	display.PrintBluetoothAdapters()
+	probe.PrintUSBInfo()
+	if err := display.DisplayBatteryReport(); err != nil {
+		fmt.Println("Erreur:", err)
+	}
+
+	plan, err := diagnostic.Plan()
+	if err != nil {
+		return err
+	}
+	fmt.Println("\nDiagnostics hors ligne")
+	if plan = offlinePlan(plan); len(plan) == 0 {
+		return nil
+	}
+	printResultSet(runPlan(context.Background(), plan, diagnostic.Options{}))
+	return nil
+}
+
+// runDiag implémente "gobox diag [-list] [-parallel] [-timeout d] [-replay archive] [id...]".
+func runDiag(args []string) error {
+	flags := flag.NewFlagSet("diag", flag.ExitOnError)
+	list := flags.Bool("list", false, "lister les diagnostics disponibles")
+	parallel := flags.Bool("parallel", false, "exécuter les diagnostics en parallèle")
+	timeout := flags.Duration("timeout", 0, "délai par diagnostic (défaut : 2× la durée estimée)")
+	replayArchive := flags.String("replay", "", "diagnostiquer une archive produite par \"capture\" (diagnostics hors ligne uniquement)")
+	flags.Parse(args)
+
+	if *list {
+		for _, d := range diagnostic.All() {
+			privileges := ""
+			if p := d.Privileges(); len(p) > 0 {
+				privileges = fmt.Sprintf(" [%v]", p)
+			}
+			if diagnostic.IsOptIn(d) {
+				privileges += " (sur demande)"
+			}
+			fmt.Printf("  %-10s %s (~%s)%s\n", d.ID(), d.Description(), d.EstimatedDuration(), privileges)
+		}
+		return nil
+	}
+
+	plan, err := diagnostic.Plan(flags.Args()...)
+	if err != nil {
+		return err
+	}
+
+	if *replayArchive != "" {
+		replay, err := openReplay(*replayArchive)
+		if err != nil {
+			return err
+		}
+		defer replay.Close()
+
+		if plan = offlinePlan(plan); len(plan) == 0 {
+			return fmt.Errorf("aucun diagnostic exécutable en rejeu")
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	printResultSet(runPlan(ctx, plan, diagnostic.Options{Parallel: *parallel, Timeout: *timeout}))
+	return nil
+}
+
+// printResultSet affiche un résultat par ligne, ses problèmes et la note globale.
+func printResultSet(set diagnostic.ResultSet) {
+	for _, r := range set.Results {
+		grade := string(r.Grade)
+		if grade == "" {
+			grade = "-"
+		}
+		fmt.Printf("%-10s %-9s %-2s %s (%s)\n", r.ID, r.Status, grade, r.Summary, r.Duration.Round(time.Millisecond))
+		if r.Err != nil {
+			fmt.Printf("           ↳ %v\n", r.Err)
+		}
+		for _, issue := range r.Issues {
+			fmt.Printf("           • %s\n", issue)
+		}
+	}
+	if set.Overall != "" {
+		incomplete := ""
+		if set.Incomplete {
+			incomplete = " (incomplète : tests sautés ou interrompus)"
+		}
+		fmt.Printf("\nNote globale : %s%s\n", set.Overall, incomplete)
+	}
+}
+
+// offlinePlan garde les diagnostics valables en rejeu. Stress, mesures et
+// scans exigent le matériel : ils sont écartés plutôt que notés F sur une
+// machine absente.
+func offlinePlan(plan []diagnostic.Diagnostic) []diagnostic.Diagnostic {
+	var offline []diagnostic.Diagnostic
+	for _, d := range plan {
+		if diagnostic.IsOffline(d) {
+			offline = append(offline, d)
+		} else {
+			fmt.Printf("%-10s ignoré en rejeu (matériel requis)\n", d.ID())
+		}
+	}
+	return offline
+}
+
+// runPlan exécute un plan en affichant l'avancement sur une seule ligne.
+func runPlan(ctx context.Context, plan []diagnostic.Diagnostic, opts diagnostic.Options) diagnostic.ResultSet {
+	opts.OnProgress = func(p diagnostic.Progress) {
+		if p.Fraction >= 0 {
+			fmt.Printf("\r[%s] %5.1f%% %-40s", p.ID, p.Fraction*100, p.Message)
+		} else {
+			fmt.Printf("\r[%s] %-48s", p.ID, p.Message)
+		}
+	}
+	set := diagnostic.Run(ctx, plan, opts)
+	fmt.Print("\r", strings.Repeat(" ", 60), "\r")
+	return set
+}
+
+// runExport implémente "gobox export [-format json|csv|pdf|all] [-o base] [-append] [-schema] [-diag ids]".
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	format := flags.String("format", "all", "format de sortie (json|csv|pdf|all)")
+	output := flags.String("o", "gobox-report", "chemin de sortie sans extension")
+	appendCSV := flags.Bool("append", false, "ajouter la ligne CSV à un fichier existant (sans en-tête)")
+	printSchema := flags.Bool("schema", false, "afficher le JSON Schema du rapport et quitter")
+	replayArchive := flags.String("replay", "", "exporter une archive produite par \"capture\"")
+	diagIDs := flags.String("diag", "", "diagnostics à exécuter et reporter dans le rapport (ex: cpu,ram,network,disk-surface)")
+	flags.Parse(args)
+
+	if *printSchema {
+		_, err := os.Stdout.Write(export.Schema)
+		return err
+	}
+
+	writers := map[string]func(path string, r *export.Report) error{
+		"json": func(path string, r *export.Report) error {
+			return writeFileWith(path, func(f *os.File) error { return export.WriteJSON(f, r) })
+		},
+		"csv": func(path string, r *export.Report) error {
+			if !*appendCSV {
+				return writeFileWith(path, func(f *os.File) error { return export.WriteCSV(f, true, r) })
+			}
+			f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+			if err != nil {
+				return err
+			}
+			info, err := f.Stat()
+			if err == nil {
+				err = export.WriteCSV(f, info.Size() == 0, r)
+			}
+			return errors.Join(err, f.Close())
+		},
+		"pdf": func(path string, r *export.Report) error {
+			return writeFileWith(path, func(f *os.File) error { return export.WritePDF(f, r) })
+		},
+	}
+
+	formats := []string{*format}
+	if *format == "all" {
+		formats = []string{"json", "csv", "pdf"}
+	}
+	for _, f := range formats {
+		if writers[f] == nil {
+			return fmt.Errorf("format inconnu %q", f)
+		}
+	}
+
+	if *replayArchive != "" {
+		replay, err := openReplay(*replayArchive)
+		if err != nil {
+			return err
+		}
+		defer replay.Close()
+	}
+
+	var results []diagnostic.Result
+	if *diagIDs != "" {
+		plan, err := diagnostic.Plan(strings.Split(*diagIDs, ",")...)
+		if err != nil {
+			return err
+		}
+		if *replayArchive != "" {
+			plan = offlinePlan(plan)
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		set := runPlan(ctx, plan, diagnostic.Options{})
+		stop()
+		for _, r := range set.Results {
+			if r.Err != nil {
+				fmt.Printf("⚠️  %s: %v\n", r.ID, r.Err)
+			}
+		}
+		results = set.Results
+	}
+
+	report := export.CollectReport(results...)
+	for _, e := range report.Errors {
+		fmt.Println("⚠️ ", e)
+	}
+
+	for _, f := range formats {
+		path := *output + "." + f
+		if err := writers[f](path, report); err != nil {
+			return err
+		}
+		fmt.Printf("Rapport écrit : %s\n", path)
+	}
+	return nil
+}
+
+// runWipe implémente "gobox wipe [-method zero|random|dod3] [-no-verify] <disque|fichier>".
+func runWipe(args []string) error {
+	names := make([]string, 0, len(operations.Methods))
+	for name := range operations.Methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	flags := flag.NewFlagSet("wipe", flag.ExitOnError)
+	methodName := flags.String("method", operations.MethodZero.Name, "méthode ("+strings.Join(names, "|")+")")
+	noVerify := flags.Bool("no-verify", false, "sauter la relecture finale")
+	certs := addCertFlags(flags)
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: gobox wipe [-method %s] [-no-verify] <disque|fichier>", strings.Join(names, "|"))
+	}
+	target := flags.Arg(0)
+
+	method, ok := operations.Methods[*methodName]
+	if !ok {
+		return fmt.Errorf("méthode inconnue %q", *methodName)
+	}
+
+	fmt.Printf("⚠️  Toutes les données de %s seront détruites (%s, %d passe(s)).\n",
+		target, method.Name, len(method.Passes))
+	fmt.Printf("Retapez %q pour confirmer : ", target)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if strings.TrimSpace(answer) != target {
+		return fmt.Errorf("confirmation refusée, rien n'a été écrit")
+	}
+
+	system, _ := probe.GetSystemInfo()
+	disk := diskIdentity(target)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	result, err := operations.Wipe(ctx, target, operations.Options{
+		Method: method,
+		Verify: !*noVerify,
+		OnProgress: func(p operations.Progress) {
+			pct := 0.0
+			if p.BytesTotal > 0 {
+				pct = float64(p.BytesDone) * 100 / float64(p.BytesTotal)
+			}
+			fmt.Printf("\r[%d/%d] %-6s %-6s %5.1f%%  %7.1f MB/s  ETA %-10s",
+				p.Pass, p.Passes, p.Phase, p.Pattern, pct,
+				p.BytesPerSec/1e6, p.ETA.Round(time.Second))
+		},
+	})
+	fmt.Println()
+
+	for i, pass := range result.Passes {
+		fmt.Printf("Passe %d (%s) : %d octets en %s (%.1f MB/s)\n",
+			i+1, pass.Pattern, pass.Bytes, pass.Duration.Round(time.Second), pass.BytesPerSec/1e6)
+	}
+	if result.Completed {
+		status := "non vérifié"
+		switch {
+		case result.Verified:
+			status = "vérifié"
+		case result.VerifyFailures > 0:
+			status = fmt.Sprintf("%d bloc(s) non conformes, premier écart à l'offset %d",
+				result.VerifyFailures, result.FirstMismatch)
+		}
+		fmt.Printf("Effacement de %s terminé (%s, O_DIRECT: %t)\n", result.Target, status, result.Direct)
+
+		switch {
+		case *certs.disabled:
+		case result.VerifyFailures > 0:
+			// Pas de preuve de destruction signée pour un support non conforme
+			fmt.Println("⚠️  Aucun certificat émis : la relecture a trouvé des blocs non effacés.")
+		default:
+			if certErr := certs.emit(export.NewWipeCertificate(system, disk, result, *certs.operator)); certErr != nil {
+				return errors.Join(err, fmt.Errorf("certificat: %w", certErr))
+			}
+		}
+	}
+	return err
+}
+
+// runErase implémente "gobox erase [-method ...] <disque>" : effacement
+// matériel (ATA Security Erase, NVMe Format/Sanitize). Sans -method,
+// affiche les méthodes supportées par le disque.
+func runErase(args []string) error {
+	flags := flag.NewFlagSet("erase", flag.ExitOnError)
+	methodName := flags.String("method", "", "méthode matérielle (voir la liste sans -method)")
+	certs := addCertFlags(flags)
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: gobox erase [-method ata-erase|ata-enhanced|nvme-format|nvme-format-crypto|nvme-sanitize|nvme-sanitize-crypto] <disque>")
+	}
+	disk := flags.Arg(0)
+
+	caps, err := operations.DetectFirmwareErase(disk)
+	if err != nil {
+		return err
+	}
+
+	if *methodName == "" {
+		fmt.Printf("Effacements matériels pour %s (%s) :\n", disk, caps.Transport)
+		for _, m := range caps.Methods {
+			fmt.Printf("  - %s\n", m)
+		}
+		if len(caps.Methods) == 0 {
+			fmt.Println("  aucun")
+		}
+		if caps.ATA != nil {
+			if caps.ATA.Frozen {
+				fmt.Printf("⚠️  Disque frozen : %s\n", operations.FrozenHint)
+			}
+			if caps.ATA.EraseTime > 0 {
+				fmt.Printf("Durée estimée : %s (enhanced : %s)\n", caps.ATA.EraseTime, caps.ATA.EnhancedTime)
+			}
+		}
+		return nil
+	}
+
+	method := operations.FirmwareMethod(*methodName)
+	fmt.Printf("⚠️  Toutes les données de %s seront détruites par le firmware (%s).\n", disk, method)
+	fmt.Printf("Retapez %q pour confirmer : ", disk)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if strings.TrimSpace(answer) != disk {
+		return fmt.Errorf("confirmation refusée, rien n'a été envoyé au disque")
+	}
+
+	system, _ := probe.GetSystemInfo()
+	identity := diskIdentity(disk)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	result, err := operations.FirmwareErase(ctx, disk, method, func(p operations.FirmwareProgress) {
+		progress := "en cours"
+		if p.Progress >= 0 {
+			progress = fmt.Sprintf("%5.1f%%", p.Progress*100)
+		}
+		fmt.Printf("\r%s : %s, écoulé %s / estimé %s   ", p.Method, progress,
+			p.Elapsed.Round(time.Second), p.Estimate)
+	})
+	fmt.Println()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Effacement matériel de %s terminé en %s\n",
+		result.Disk, result.EndedAt.Sub(result.StartedAt).Round(time.Second))
+
+	if *certs.disabled {
+		return nil
+	}
+	return certs.emit(export.NewFirmwareCertificate(system, identity, result, *certs.operator))
+}
+
+// runBench implémente "gobox bench [-write] [-yes] <disque>" : benchmark
+// O_DIRECT reporté sur la note du disque. -write ajoute l'écriture
+// séquentielle, qui détruit le début du disque.
+func runBench(args []string) error {
+	flags := flag.NewFlagSet("bench", flag.ExitOnError)
+	write := flags.Bool("write", false, "mesurer aussi l'écriture (DESTRUCTIF, disque démonté)")
+	yes := flags.Bool("yes", false, "ne pas demander de confirmation")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: gobox bench [-write] [-yes] <disque>")
+	}
+	disk := flags.Arg(0)
+
+	if *write && !*yes {
+		fmt.Printf("⚠️  Le début de %s sera écrasé par le test d'écriture.\n", disk)
+		fmt.Printf("Retapez %q pour confirmer : ", disk)
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if strings.TrimSpace(answer) != disk {
+			return fmt.Errorf("confirmation refusée, rien n'a été écrit")
+		}
+	}
+
+	test, err := diagDisk.RunDiskTest(disk)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	opts := diagDisk.DefaultBenchmarkOptions()
+	opts.AllowWrite = *write
+	opts.OnProgress = func(phase string, fraction float64) {
+		fmt.Printf("\r%-12s %5.1f%%", phase, fraction*100)
+	}
+	bench, err := diagDisk.RunBenchmark(ctx, disk, opts)
+	fmt.Println()
+	if err != nil {
+		return err
+	}
+
+	diagDisk.ApplyBenchmark(&test, bench)
+	display.DisplayBenchmark(&test)
+	return nil
+}
+
+// runSurface implémente "gobox surface [-chunk octets] [-state fichier] <disque>" :
+// scan de surface en lecture seule. Interrompu (Ctrl+C), il enregistre son
+// état dans -state et reprend de là au lancement suivant.
+func runSurface(args []string) error {
+	flags := flag.NewFlagSet("surface", flag.ExitOnError)
+	chunk := flags.Int("chunk", diagDisk.DefaultSurfaceChunkSize, "taille d'une lecture en octets")
+	statePath := flags.String("state", "", "fichier d'état pour la reprise (défaut : gobox-surface-<disque>.json)")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: gobox surface [-chunk octets] [-state fichier] <disque>")
+	}
+	disk := flags.Arg(0)
+	if *statePath == "" {
+		*statePath = diagDisk.SurfaceStatePath(sanitizeFileName(disk))
+	}
+
+	opts := diagDisk.SurfaceScanOptions{
+		ChunkSize: *chunk,
+		OnProgress: func(p diagDisk.SurfaceScanProgress) {
+			fmt.Printf("\r%5.1f%%  %7.1f MB/s  ETA %-10s  lentes %d  très lentes %d  illisibles %d   ",
+				float64(p.BytesDone)*100/float64(max(p.BytesTotal, 1)), p.BytesPerSec/1e6,
+				p.ETA.Round(time.Second), p.Slow, p.VerySlow, p.Unreadable)
+		},
+	}
+	prev, err := diagDisk.LoadSurfaceState(*statePath)
+	if err != nil {
+		return err
+	}
+	if prev != nil {
+		opts.Resume = prev
+		fmt.Printf("Reprise du scan à %.1f %% (%s)\n",
+			float64(prev.NextOffset)*100/float64(max(prev.SizeBytes, 1)), *statePath)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	result, err := diagDisk.RunSurfaceScan(ctx, disk, opts)
+	fmt.Println()
+	if errors.Is(err, context.Canceled) {
+		if saveErr := diagDisk.SaveSurfaceState(*statePath, result); saveErr != nil {
+			return errors.Join(err, saveErr)
+		}
+		fmt.Printf("Scan interrompu, état enregistré : %s (relancer pour reprendre)\n", *statePath)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	os.Remove(*statePath)
+	display.DisplaySurfaceScan(&result)
+	return nil
+}
+
+// runDischarge implémente "gobox discharge [-battery BAT0] [-duration d]
+// [-interval d] [-min %] [-cpu] [-o base]" : mesure de la capacité réelle,
+// secteur débranché. La courbe est écrite en JSON et en CSV.
+func runDischarge(args []string) error {
+	def := diagBattery.DefaultDischargeOptions()
+	flags := flag.NewFlagSet("discharge", flag.ExitOnError)
+	name := flags.String("battery", "", "batterie à mesurer (défaut : la première)")
+	duration := flags.Duration("duration", def.Duration, "durée maximale de la mesure")
+	interval := flags.Duration("interval", def.Interval, "période d'échantillonnage")
+	minCapacity := flags.Int("min", def.MinCapacity, "arrêt sous ce niveau de charge (%)")
+	cpuLoad := flags.Bool("cpu", false, "charger le CPU pendant la mesure")
+	output := flags.String("o", "gobox-discharge", "chemin de la courbe sans extension")
+	flags.Parse(args)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	result, err := diagBattery.RunDischargeTest(ctx, diagBattery.DischargeOptions{
+		Battery:     *name,
+		Duration:    *duration,
+		Interval:    *interval,
+		MinCapacity: *minCapacity,
+		CPULoad:     *cpuLoad,
+		OnSample: func(s diagBattery.DischargeSample) {
+			fmt.Printf("\r%-10s %3d%%  %6.2f Wh  %5.1f W  %6.3f V   ",
+				s.Elapsed.Round(time.Second), s.Capacity, s.EnergyWh, s.PowerW, s.VoltageV)
+		},
+	})
+	fmt.Println()
+	if err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	if len(result.Samples) > 0 {
+		saveErr := errors.Join(
+			writeFileWith(*output+".json", func(f *os.File) error {
+				enc := json.NewEncoder(f)
+				enc.SetIndent("", "  ")
+				return enc.Encode(result)
+			}),
+			writeFileWith(*output+".csv", func(f *os.File) error {
+				return export.WriteDischargeCSV(f, result)
+			}),
+		)
+		if saveErr != nil {
+			return saveErr
+		}
+		fmt.Printf("Courbe écrite : %s.json, %s.csv\n", *output, *output)
+	}
+	display.DisplayDischarge(&result)
+	return nil
+}
+
+// runCharge implémente "gobox charge [-battery BAT0] [-start %] [-end %]
+// [-behaviour auto|inhibit-charge|force-discharge] [-yes]". Sans réglage,
+// affiche l'état ; sinon demande confirmation avant d'écrire.
+func runCharge(args []string) error {
+	flags := flag.NewFlagSet("charge", flag.ExitOnError)
+	name := flags.String("battery", "", "batterie à piloter (défaut : la première)")
+	start := flags.Int("start", -1, "seuil de reprise de charge (%)")
+	end := flags.Int("end", -1, "seuil d'arrêt de charge (%), ex: 60 pour le stockage")
+	behaviour := flags.String("behaviour", "", "comportement (auto|inhibit-charge|force-discharge)")
+	yes := flags.Bool("yes", false, "ne pas demander de confirmation")
+	flags.Parse(args)
+
+	if *name == "" {
+		info, err := probe.GetBatteryInfo()
+		if err != nil {
+			return err
+		}
+		*name = info.Name
+	}
+	control, err := probe.GetChargeControl(*name)
+	if err != nil {
+		return err
+	}
+	display.DisplayChargeControl(*name, control)
+	if *end < 0 && *start < 0 && *behaviour == "" {
+		return nil
+	}
+	if !control.Supported() {
+		return fmt.Errorf("%s : aucun réglage de charge exposé par le pilote", *name)
+	}
+
+	var changes []string
+	if *end >= 0 || *start >= 0 {
+		if *end < 0 {
+			*end = 100
+			if control.EndThreshold != nil {
+				*end = *control.EndThreshold
+			}
+		}
+		if *start < 0 {
+			*start = 0
+			if control.StartThreshold != nil {
+				*start = min(*control.StartThreshold, *end-1)
+			}
+		}
+		changes = append(changes, fmt.Sprintf("seuils %d–%d %%", *start, *end))
+	}
+	if *behaviour != "" {
+		changes = append(changes, "comportement "+*behaviour)
+	}
+
+	if !*yes {
+		fmt.Printf("Appliquer à %s : %s ? [o/N] ", *name, strings.Join(changes, ", "))
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "o" && a != "oui" {
+			return fmt.Errorf("confirmation refusée, rien n'a été écrit")
+		}
+	}
+
+	if *end >= 0 {
+		if err := probe.SetChargeThresholds(*name, *start, *end); err != nil {
+			return err
+		}
+	}
+	if *behaviour != "" {
+		if err := probe.SetChargeBehaviour(*name, *behaviour); err != nil {
+			return err
+		}
+	}
+	if control, err = probe.GetChargeControl(*name); err == nil {
+		display.DisplayChargeControl(*name, control)
+	}
+	return err
+}
+
+// runCalibrate implémente "gobox calibrate [-battery BAT0] [-cutoff %]
+// [-interval d] [-o base]" : charge complète, décharge, recharge.
+func runCalibrate(args []string) error {
+	def := diagBattery.DefaultCalibrationOptions()
+	flags := flag.NewFlagSet("calibrate", flag.ExitOnError)
+	name := flags.String("battery", "", "batterie à calibrer (défaut : la première)")
+	cutOff := flags.Int("cutoff", def.CutOff, "fin de la décharge (%)")
+	interval := flags.Duration("interval", def.Interval, "période de journalisation")
+	output := flags.String("o", "gobox-calibration", "chemin du journal sans extension")
+	flags.Parse(args)
+
+	fmt.Printf("⚠️  La calibration charge à 100 %%, décharge jusqu'à %d %% puis recharge : plusieurs heures.\n", *cutOff)
+	fmt.Printf("Les seuils de charge sont levés puis restaurés. Continuer ? [o/N] ")
+	stdin := bufio.NewReader(os.Stdin)
+	answer, _ := stdin.ReadString('\n')
+	if a := strings.ToLower(strings.TrimSpace(answer)); a != "o" && a != "oui" {
+		return fmt.Errorf("calibration annulée")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	result, err := diagBattery.RunCalibration(ctx, diagBattery.CalibrationOptions{
+		Battery:  *name,
+		CutOff:   *cutOff,
+		Interval: *interval,
+		Prompt: func(message string) error {
+			fmt.Printf("\n👉 %s, puis Entrée : ", message)
+			_, err := stdin.ReadString('\n')
+			return err
+		},
+		OnSample: func(s diagBattery.CalibrationSample) {
+			fmt.Printf("\r%-9s %-10s %3d%%  %-12s %6.2f Wh  %5.1f W   ",
+				s.Phase, s.Elapsed.Round(time.Second), s.Capacity, s.Status, s.EnergyWh, s.PowerW)
+		},
+	})
+	fmt.Println()
+
+	if len(result.Samples) > 0 {
+		saveErr := writeFileWith(*output+".json", func(f *os.File) error {
+			enc := json.NewEncoder(f)
+			enc.SetIndent("", "  ")
+			return enc.Encode(result)
+		})
+		if saveErr != nil {
+			return errors.Join(err, saveErr)
+		}
+		fmt.Printf("Journal écrit : %s.json\n", *output)
+	}
+	display.DisplayCalibration(&result)
+	return err
+}
+
+// runNetserver implémente "gobox netserver [-listen :8765]", le pair des
+// tests de débit du diagnostic réseau.
+func runNetserver(args []string) error {
+	flags := flag.NewFlagSet("netserver", flag.ExitOnError)
+	listen := flags.String("listen", fmt.Sprintf(":%d", network.DefaultServerPort), "adresse d'écoute")
+	flags.Parse(args)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	_, port, err := net.SplitHostPort(*listen)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("gobox netserver à l'écoute sur %s (Ctrl+C pour arrêter)\n", *listen)
+	fmt.Printf("Côté poste testé : %s=<ip>:%s gobox diag network\n", network.EnvServer, port)
+	return network.Serve(ctx, *listen)
+}
+
+// subcommands sont les commandes reconnues en premier argument.
+var subcommands = map[string]func(args []string) error{
+	"bench":       runBench,
+	"calibrate":   runCalibrate,
+	"capture":     runCapture,
+	"charge":      runCharge,
+	"diag":        runDiag,
+	"discharge":   runDischarge,
+	"erase":       runErase,
+	"export":      runExport,
+	"netserver":   runNetserver,
+	"pubkey":      runPubkey,
+	"surface":     runSurface,
+	"verify-cert": runVerifyCert,
+	"wipe":        runWipe,
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			if err := run(os.Args[2:]); err != nil {
+				fmt.Println("Erreur:", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
+	replay := flag.String("replay", "", "rejouer une archive produite par \"capture\" au lieu du système réel")
+	flag.Parse()
+
+	if *replay != "" {
+		if err := runReplay(*replay); err != nil {
+			fmt.Println("Erreur:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := display.DisplaySystemInfo(); err != nil {
+		fmt.Println("Erreur:", err)
+	}
 	display.DisplayBatteryReport()
 	p := tea.NewProgram(NewModel())
 	if err := p.Start(); err != nil {
@@ -0,0 +1,105 @@
+package blockdev
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+
+	"gobox/internal/sysfs"
+)
+
+const devRoot = "/dev"
+
+// Ioctl issues a raw ioctl and returns the syscall's return value.
+//
+// It is a variable so that command encoding can be exercised against a
+// fake layer without touching real hardware.
+var Ioctl = func(fd, req uintptr, arg unsafe.Pointer) (uintptr, error) {
+	r, _, errno := unix.Syscall(unix.SYS_IOCTL, fd, req, uintptr(arg))
+	if errno != 0 {
+		return r, errno
+	}
+	return r, nil
+}
+
+// Device is an open block device node (/dev/<name>).
+type Device struct {
+	Name string
+	file *os.File
+}
+
+// Open opens /dev/<name> with the given flags (e.g. os.O_RDONLY).
+//
+// The name is validated like a sysfs entry: no path separators or traversal.
+func Open(name string, flag int) (*Device, error) {
+	if err := sysfs.ValidateSysfsName(name); err != nil {
+		return nil, fmt.Errorf("invalid device name: %w", err)
+	}
+	path := filepath.Join(devRoot, name)
+	f, err := os.OpenFile(path, flag, 0)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", path, err)
+	}
+	return &Device{Name: name, file: f}, nil
+}
+
+// NewDevice wraps an already open file (regular file, loop device...).
+func NewDevice(name string, f *os.File) *Device {
+	return &Device{Name: name, file: f}
+}
+
+// File returns the underlying file.
+func (d *Device) File() *os.File {
+	return d.file
+}
+
+// Fd returns the file descriptor used for ioctls.
+func (d *Device) Fd() uintptr {
+	return d.file.Fd()
+}
+
+// Close closes the device node.
+func (d *Device) Close() error {
+	return d.file.Close()
+}
+
+// Size returns the device size in bytes (BLKGETSIZE64), falling back to
+// the file size for regular files.
+func (d *Device) Size() (int64, error) {
+	var size uint64
+	if _, err := Ioctl(d.Fd(), unix.BLKGETSIZE64, unsafe.Pointer(&size)); err == nil {
+		return int64(size), nil
+	}
+	info, err := d.file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	if !info.Mode().IsRegular() {
+		return 0, fmt.Errorf("%s: cannot determine size", d.Name)
+	}
+	return info.Size(), nil
+}
+
+// LogicalBlockSize returns the logical sector size (BLKSSZGET), or 512
+// when the ioctl is not supported (regular files).
+func (d *Device) LogicalBlockSize() int {
+	var size int32
+	if _, err := Ioctl(d.Fd(), unix.BLKSSZGET, unsafe.Pointer(&size)); err != nil || size <= 0 {
+		return 512
+	}
+	return int(size)
+}
+
+// AlignedBuffer allocates a buffer whose start address is a multiple of
+// align, as required by O_DIRECT I/O.
+func AlignedBuffer(size, align int) []byte {
+	raw := make([]byte, size+align)
+	shift := align - int(uintptr(unsafe.Pointer(&raw[0]))%uintptr(align))
+	if shift == align {
+		shift = 0
+	}
+	return raw[shift : shift+size]
+}
@@ -0,0 +1,139 @@
+package blockdev
+
+import (
+	"fmt"
+	"runtime"
+	"unsafe"
+)
+
+// ═══════════════════════════════════════════════════════════════════
+// NVMe ADMIN PASSTHROUGH
+// ═══════════════════════════════════════════════════════════════════
+
+const (
+	nvmeIoctlID       = 0x4E40     // _IO('N', 0x40)
+	nvmeIoctlAdminCmd = 0xC0484E41 // _IOWR('N', 0x41, struct nvme_admin_cmd)
+)
+
+// NVMe admin opcodes used by gobox.
+const (
+	NVMeOpGetLogPage = 0x02
+	NVMeOpIdentify   = 0x06
+	NVMeOpFormatNVM  = 0x80
+	NVMeOpSanitize   = 0x84
+)
+
+// NVMe log page identifiers.
+const (
+	NVMeLogSMART    = 0x02
+	NVMeLogSanitize = 0x81
+)
+
+// NVMeNSAll addresses every namespace (controller-wide log pages).
+const NVMeNSAll = 0xFFFFFFFF
+
+// NVMeAdminCommand mirrors struct nvme_passthru_cmd from <linux/nvme_ioctl.h>.
+type NVMeAdminCommand struct {
+	Opcode      uint8
+	Flags       uint8
+	Rsvd1       uint16
+	NSID        uint32
+	CDW2        uint32
+	CDW3        uint32
+	Metadata    uint64
+	Addr        uint64
+	MetadataLen uint32
+	DataLen     uint32
+	CDW10       uint32
+	CDW11       uint32
+	CDW12       uint32
+	CDW13       uint32
+	CDW14       uint32
+	CDW15       uint32
+	TimeoutMs   uint32
+	Result      uint32
+}
+
+// NVMeStatusError is a command completed by the controller with a
+// non-zero status field.
+type NVMeStatusError struct {
+	Opcode uint8
+	Status uint16
+}
+
+func (e *NVMeStatusError) Error() string {
+	return fmt.Sprintf("nvme opcode 0x%02x: status 0x%04x", e.Opcode, e.Status)
+}
+
+// NVMeAdmin submits an admin command; data (if any) is the transfer buffer.
+// Returns the completion dword 0.
+func (d *Device) NVMeAdmin(cmd *NVMeAdminCommand, data []byte) (uint32, error) {
+	if len(data) > 0 {
+		// Addr is an integer the GC does not see: pin the buffer so it
+		// is heap-allocated and cannot move with a growing stack.
+		var pinner runtime.Pinner
+		pinner.Pin(&data[0])
+		defer pinner.Unpin()
+		cmd.Addr = uint64(uintptr(unsafe.Pointer(&data[0])))
+		cmd.DataLen = uint32(len(data))
+	}
+
+	r, err := Ioctl(d.Fd(), nvmeIoctlAdminCmd, unsafe.Pointer(cmd))
+	if err != nil {
+		return 0, fmt.Errorf("nvme admin %s: %w", d.Name, err)
+	}
+	if r != 0 {
+		return 0, &NVMeStatusError{Opcode: cmd.Opcode, Status: uint16(r)}
+	}
+	return cmd.Result, nil
+}
+
+// NVMeGetLogPage reads log page lid into buf (length multiple of 4).
+func (d *Device) NVMeGetLogPage(lid uint8, nsid uint32, buf []byte) error {
+	numd := uint32(len(buf)/4 - 1)
+	cmd := NVMeAdminCommand{
+		Opcode: NVMeOpGetLogPage,
+		NSID:   nsid,
+		CDW10:  uint32(lid) | (numd&0xFFFF)<<16,
+		CDW11:  numd >> 16,
+	}
+	_, err := d.NVMeAdmin(&cmd, buf)
+	return err
+}
+
+// NVMeIdentifyController reads the 4096-byte Identify Controller structure.
+func (d *Device) NVMeIdentifyController() ([]byte, error) {
+	buf := make([]byte, 4096)
+	cmd := NVMeAdminCommand{
+		Opcode: NVMeOpIdentify,
+		CDW10:  1, // CNS 01h : controller
+	}
+	if _, err := d.NVMeAdmin(&cmd, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// NVMeIdentifyNamespace reads the 4096-byte Identify Namespace structure.
+func (d *Device) NVMeIdentifyNamespace(nsid uint32) ([]byte, error) {
+	buf := make([]byte, 4096)
+	cmd := NVMeAdminCommand{
+		Opcode: NVMeOpIdentify,
+		NSID:   nsid,
+		CDW10:  0, // CNS 00h : namespace
+	}
+	if _, err := d.NVMeAdmin(&cmd, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// NVMeNamespaceID returns the namespace ID of a namespace block device
+// (NVME_IOCTL_ID).
+func (d *Device) NVMeNamespaceID() (uint32, error) {
+	r, err := Ioctl(d.Fd(), nvmeIoctlID, nil)
+	if err != nil {
+		return 0, fmt.Errorf("nvme namespace id %s: %w", d.Name, err)
+	}
+	return uint32(r), nil
+}
@@ -0,0 +1,206 @@
+package blockdev
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+	"unsafe"
+)
+
+// ═══════════════════════════════════════════════════════════════════
+// SCSI GENERIC (SG_IO)
+// ═══════════════════════════════════════════════════════════════════
+
+const (
+	sgIO          = 0x2285 // SG_IO ioctl request
+	sgInterfaceID = 'S'
+	senseLen      = 32
+)
+
+// Direction is the SG_IO data transfer direction.
+type Direction int32
+
+const (
+	DirNone       Direction = -1 // SG_DXFER_NONE
+	DirToDevice   Direction = -2 // SG_DXFER_TO_DEV
+	DirFromDevice Direction = -3 // SG_DXFER_FROM_DEV
+)
+
+// sgIOHdr mirrors struct sg_io_hdr from <scsi/sg.h>.
+type sgIOHdr struct {
+	InterfaceID    int32
+	DxferDirection int32
+	CmdLen         uint8
+	MxSbLen        uint8
+	IovecCount     uint16
+	DxferLen       uint32
+	Dxferp         unsafe.Pointer
+	Cmdp           unsafe.Pointer
+	Sbp            unsafe.Pointer
+	Timeout        uint32
+	Flags          uint32
+	PackID         int32
+	UsrPtr         unsafe.Pointer
+	Status         uint8
+	MaskedStatus   uint8
+	MsgStatus      uint8
+	SbLenWr        uint8
+	HostStatus     uint16
+	DriverStatus   uint16
+	Resid          int32
+	Duration       uint32
+	Info           uint32
+}
+
+// SenseError reports a SCSI command that completed with a non-good status.
+type SenseError struct {
+	Status       uint8
+	HostStatus   uint16
+	DriverStatus uint16
+	Sense        []byte
+}
+
+func (e *SenseError) Error() string {
+	return fmt.Sprintf("scsi status 0x%02x host 0x%04x driver 0x%04x sense % x",
+		e.Status, e.HostStatus, e.DriverStatus, e.Sense)
+}
+
+// SGIO sends a SCSI CDB through SG_IO.
+//
+// On CHECK CONDITION the returned error is a *[SenseError]; the sense buffer
+// is also returned because ATA pass-through reports registers through it.
+func (d *Device) SGIO(cdb []byte, dir Direction, data []byte, timeout time.Duration) ([]byte, error) {
+	sense := make([]byte, senseLen)
+	hdr := sgIOHdr{
+		InterfaceID:    sgInterfaceID,
+		DxferDirection: int32(dir),
+		CmdLen:         uint8(len(cdb)),
+		MxSbLen:        senseLen,
+		DxferLen:       uint32(len(data)),
+		Cmdp:           unsafe.Pointer(&cdb[0]),
+		Sbp:            unsafe.Pointer(&sense[0]),
+		Timeout:        uint32(timeout / time.Millisecond),
+	}
+	if len(data) > 0 {
+		hdr.Dxferp = unsafe.Pointer(&data[0])
+	}
+
+	_, err := Ioctl(d.Fd(), sgIO, unsafe.Pointer(&hdr))
+	runtime.KeepAlive(cdb)
+	runtime.KeepAlive(data)
+	if err != nil {
+		return nil, fmt.Errorf("SG_IO %s: %w", d.Name, err)
+	}
+
+	sense = sense[:hdr.SbLenWr]
+	if hdr.Status != 0 || hdr.HostStatus != 0 || hdr.DriverStatus&0x0F != 0 {
+		return sense, &SenseError{
+			Status:       hdr.Status,
+			HostStatus:   hdr.HostStatus,
+			DriverStatus: hdr.DriverStatus,
+			Sense:        sense,
+		}
+	}
+	return sense, nil
+}
+
+// ═══════════════════════════════════════════════════════════════════
+// ATA PASS-THROUGH (SAT, 16-byte CDB)
+// ═══════════════════════════════════════════════════════════════════
+
+// ATA pass-through protocols (SAT-4 table 137).
+const (
+	ATAProtoNonData = 3
+	ATAProtoPIOIn   = 4
+	ATAProtoPIOOut  = 5
+)
+
+// ATACommand is an ATA taskfile sent through SCSI/ATA Translation.
+type ATACommand struct {
+	Command  uint8
+	Features uint8
+	Count    uint8
+	LBA      uint32 // 28-bit LBA (low/mid/high + device bits 0-3)
+	Device   uint8
+	Protocol uint8 // ATAProto*
+}
+
+// CDB encodes the command as an ATA PASS-THROUGH (16) CDB.
+//
+// Data transfers are expressed in 512-byte blocks counted by the Count
+// field (T_LENGTH=2, BYT_BLOK=1).
+func (c ATACommand) CDB() []byte {
+	cdb := make([]byte, 16)
+	cdb[0] = 0x85
+	cdb[1] = c.Protocol << 1
+
+	switch c.Protocol {
+	case ATAProtoPIOIn:
+		cdb[2] = 0x0E // T_DIR=1 (from device), BYT_BLOK=1, T_LENGTH=2
+	case ATAProtoPIOOut:
+		cdb[2] = 0x06 // T_DIR=0, BYT_BLOK=1, T_LENGTH=2
+	default:
+		cdb[2] = 0x20 // CK_COND=1 : renvoie les registres ATA en sense
+	}
+
+	cdb[4] = c.Features
+	cdb[6] = c.Count
+	cdb[8] = uint8(c.LBA)
+	cdb[10] = uint8(c.LBA >> 8)
+	cdb[12] = uint8(c.LBA >> 16)
+	cdb[13] = c.Device | uint8(c.LBA>>24)&0x0F
+	cdb[14] = c.Command
+	return cdb
+}
+
+// ATAPassThrough sends an ATA command through SG_IO.
+func (d *Device) ATAPassThrough(cmd ATACommand, data []byte, timeout time.Duration) ([]byte, error) {
+	dir := DirNone
+	switch cmd.Protocol {
+	case ATAProtoPIOIn:
+		dir = DirFromDevice
+	case ATAProtoPIOOut:
+		dir = DirToDevice
+	}
+	sense, err := d.SGIO(cmd.CDB(), dir, data, timeout)
+	// Avec CK_COND=1, un succès remonte en CHECK CONDITION « recovered ».
+	if err != nil && cmd.Protocol == ATAProtoNonData && ataStatusOK(sense) {
+		return sense, nil
+	}
+	return sense, err
+}
+
+// ataStatusOK inspects the ATA Status Return descriptor (0x09) of a
+// descriptor-format sense buffer and reports whether ERR/DF are clear.
+func ataStatusOK(sense []byte) bool {
+	if len(sense) < 8 || sense[0]&0x7F != 0x72 {
+		return false
+	}
+	desc := sense[8:]
+	for len(desc) >= 2 {
+		l := int(desc[1]) + 2
+		if l > len(desc) {
+			return false
+		}
+		if desc[0] == 0x09 && l >= 14 {
+			status := desc[13]
+			return status&0x21 == 0 // ERR (bit 0) et DF (bit 5)
+		}
+		desc = desc[l:]
+	}
+	return false
+}
+
+// ATAIdentify reads the 512-byte IDENTIFY DEVICE data (256 little-endian words).
+func (d *Device) ATAIdentify() ([]byte, error) {
+	buf := make([]byte, 512)
+	cmd := ATACommand{
+		Command:  0xEC, // IDENTIFY DEVICE
+		Count:    1,
+		Protocol: ATAProtoPIOIn,
+	}
+	if _, err := d.ATAPassThrough(cmd, buf, 10*time.Second); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
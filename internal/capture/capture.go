@@ -0,0 +1,271 @@
+package capture
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// FormatVersion est la version du format d'archive produit par [Write].
+	// v2 : tables SMBIOS brutes à la place de la sortie de dmidecode.
+	FormatVersion = 2
+
+	metaDir      = "gobox"
+	metaFile     = metaDir + "/capture.json"
+	cmdDir       = metaDir + "/cmd"
+	maxFileBytes = 1 << 20 // 1 MB : largement au-dessus de tout fichier sysfs/procfs utile
+)
+
+// Root est une arborescence à capturer.
+type Root struct {
+	Path  string // chemin logique ou motif glob (ex: "/sys/block")
+	Depth int    // profondeur de descente sous Path (0 = Path seul)
+}
+
+// Command est une commande externe dont la sortie est capturée.
+type Command struct {
+	Name string   // ex: "lspci"
+	Args []string // ex: ["-mm", "-nn", "-D"]
+	File string   // nom du fichier dans l'archive (ex: "lspci.txt")
+}
+
+// Metadata décrit une archive de capture.
+type Metadata struct {
+	Version    int       `json:"version"`
+	Hostname   string    `json:"hostname"`
+	Kernel     string    `json:"kernel"`
+	CapturedAt time.Time `json:"captured_at"`
+	Commands   []Command `json:"commands"`
+	Skipped    []string  `json:"skipped,omitempty"` // commandes en échec
+}
+
+// DefaultRoots liste exactement les chemins lus par les sondes.
+var DefaultRoots = []Root{
+	{Path: "/proc/cpuinfo"},
+	{Path: "/sys/devices/system/cpu/cpu[0-9]*/cache", Depth: 2},
+	{Path: "/sys/devices/system/cpu/cpu[0-9]*/cpufreq", Depth: 1},
+	{Path: "/sys/block", Depth: 3},
+	{Path: "/sys/class/power_supply", Depth: 2},
+	{Path: "/sys/class/drm", Depth: 3},
+	{Path: "/sys/bus/usb/devices", Depth: 2},
+	{Path: "/sys/bus/pci/devices", Depth: 2},
+	{Path: "/sys/class/typec", Depth: 2},
+	{Path: "/sys/class/tty", Depth: 3},
+	{Path: "/sys/class/net", Depth: 3},
+	{Path: "/sys/class/bluetooth", Depth: 3},
+	{Path: "/sys/class/rfkill", Depth: 2},
+	{Path: "/sys/module/i915/version"},
+	{Path: "/sys/module/amdgpu/version"},
+	{Path: "/proc/driver/nvidia/version"},
+	{Path: "/sys/firmware/dmi/tables", Depth: 1},
+	{Path: "/sys/class/dmi/id", Depth: 1},
+}
+
+// DefaultCommands liste les sorties de commandes nécessaires au rejeu.
+var DefaultCommands = []Command{
+	{Name: "lspci", Args: []string{"-mm", "-nn", "-D"}, File: "lspci.txt"},
+}
+
+// followLinks sont les liens symboliques suivis en profondeur en plus de la
+// racine et de ses entrées directes. Les autres (subsystem, driver...) sont
+// enregistrés tels quels sans être parcourus, pour ne pas aspirer /sys entier.
+var followLinks = map[string]bool{
+	"device":  true,
+	"cpufreq": true,
+}
+
+// WriteFile capture la machine courante dans une archive tar.gz.
+func WriteFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("création %s: %w", path, err)
+	}
+	if err := Write(f, DefaultRoots, DefaultCommands); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Write capture les arborescences et sorties de commandes dans w (tar.gz).
+//
+// Les fichiers illisibles (droits, attributs en écriture seule, EIO) sont
+// ignorés silencieusement : une capture partielle reste exploitable.
+func Write(w io.Writer, roots []Root, commands []Command) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	c := &capturer{tw: tw, seen: make(map[string]bool)}
+
+	for _, root := range roots {
+		matches, err := filepath.Glob(root.Path)
+		if err != nil {
+			return fmt.Errorf("motif %s: %w", root.Path, err)
+		}
+		for _, m := range matches {
+			if err := c.walk(m, root.Depth, 0); err != nil {
+				return err
+			}
+		}
+	}
+
+	meta := Metadata{
+		Version:    FormatVersion,
+		CapturedAt: time.Now().UTC(),
+	}
+	meta.Hostname, _ = os.Hostname()
+	if kernel, err := os.ReadFile("/proc/sys/kernel/osrelease"); err == nil {
+		meta.Kernel = strings.TrimSpace(string(kernel))
+	}
+
+	for _, cmd := range commands {
+		out, err := exec.Command(cmd.Name, cmd.Args...).Output()
+		if err != nil {
+			meta.Skipped = append(meta.Skipped, fmt.Sprintf("%s: %v", cmd.Name, err))
+			continue
+		}
+		if err := c.writeData(cmdDir+"/"+cmd.File, out); err != nil {
+			return err
+		}
+		meta.Commands = append(meta.Commands, cmd)
+	}
+
+	metaData, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encodage métadonnées: %w", err)
+	}
+	if err := c.writeData(metaFile, metaData); err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("fermeture tar: %w", err)
+	}
+	return gz.Close()
+}
+
+type capturer struct {
+	tw   *tar.Writer
+	seen map[string]bool
+}
+
+// walk enregistre path puis descend de depth niveaux.
+// level est la distance à la racine capturée : la racine (0) et ses entrées
+// directes (1) voient toujours leurs liens suivis.
+func (c *capturer) walk(path string, depth, level int) error {
+	if c.seen[path] {
+		return nil
+	}
+	c.seen[path] = true
+
+	info, err := os.Lstat(path)
+	if err != nil {
+		return nil
+	}
+
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(path)
+		if err != nil || filepath.IsAbs(target) {
+			return nil
+		}
+		if err := c.writeHeader(&tar.Header{
+			Typeflag: tar.TypeSymlink,
+			Name:     archiveName(path),
+			Linkname: target,
+			Mode:     0o777,
+			ModTime:  info.ModTime(),
+		}); err != nil {
+			return err
+		}
+		if level > 1 && !followLinks[filepath.Base(path)] {
+			return nil
+		}
+		resolved, err := filepath.EvalSymlinks(path)
+		if err != nil {
+			return nil
+		}
+		return c.walk(resolved, depth, level)
+
+	case info.IsDir():
+		if err := c.writeHeader(&tar.Header{
+			Typeflag: tar.TypeDir,
+			Name:     archiveName(path) + "/",
+			Mode:     0o755,
+			ModTime:  info.ModTime(),
+		}); err != nil {
+			return err
+		}
+		if depth <= 0 {
+			return nil
+		}
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil
+		}
+		for _, entry := range entries {
+			if err := c.walk(filepath.Join(path, entry.Name()), depth-1, level+1); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case info.Mode().IsRegular():
+		if info.Mode().Perm()&0o444 == 0 {
+			return nil // attribut en écriture seule
+		}
+		data, err := readLimited(path)
+		if err != nil {
+			return nil
+		}
+		return c.writeData(archiveName(path), data)
+	}
+
+	return nil
+}
+
+func (c *capturer) writeHeader(hdr *tar.Header) error {
+	if err := c.tw.WriteHeader(hdr); err != nil {
+		return fmt.Errorf("écriture %s: %w", hdr.Name, err)
+	}
+	return nil
+}
+
+func (c *capturer) writeData(name string, data []byte) error {
+	if err := c.writeHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0o444,
+		Size:     int64(len(data)),
+		ModTime:  time.Now(),
+	}); err != nil {
+		return err
+	}
+	if _, err := c.tw.Write(data); err != nil {
+		return fmt.Errorf("écriture %s: %w", name, err)
+	}
+	return nil
+}
+
+// readLimited lit un fichier sysfs/procfs (taille déclarée souvent 0 ou 4096).
+func readLimited(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(io.LimitReader(f, maxFileBytes))
+}
+
+// archiveName convertit un chemin absolu en nom d'entrée tar relatif.
+func archiveName(path string) string {
+	return strings.TrimPrefix(filepath.ToSlash(path), "/")
+}
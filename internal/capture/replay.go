@@ -0,0 +1,214 @@
+package capture
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gobox/internal/probe"
+	"gobox/internal/sysfs"
+)
+
+// Replay est une archive de capture extraite et prête à être rejouée.
+type Replay struct {
+	Dir      string   // répertoire temporaire d'extraction
+	FS       sysfs.FS // racine à passer aux sondes
+	Meta     Metadata
+	Warnings []string // limites d'une archive d'un format antérieur
+}
+
+// Open extrait une archive produite par [Write] dans un répertoire temporaire.
+// L'appelant doit appeler [Replay.Close] pour le supprimer.
+func Open(archive string) (*Replay, error) {
+	f, err := os.Open(archive)
+	if err != nil {
+		return nil, fmt.Errorf("ouverture %s: %w", archive, err)
+	}
+	defer f.Close()
+
+	dir, err := os.MkdirTemp("", "gobox-replay-")
+	if err != nil {
+		return nil, fmt.Errorf("répertoire temporaire: %w", err)
+	}
+
+	if err := extract(f, dir); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("extraction %s: %w", archive, err)
+	}
+
+	r := &Replay{Dir: dir, FS: sysfs.NewFS(dir)}
+
+	metaData, err := os.ReadFile(filepath.Join(dir, metaFile))
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("archive sans %s: %w", metaFile, err)
+	}
+	if err := json.Unmarshal(metaData, &r.Meta); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("lecture %s: %w", metaFile, err)
+	}
+	if r.Meta.Version > FormatVersion {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("version d'archive %d non supportée (max %d)",
+			r.Meta.Version, FormatVersion)
+	}
+	if r.Meta.Version < 2 {
+		r.Warnings = append(r.Warnings,
+			"archive v1 sans tables SMBIOS : mémoire et identité système non rejouables, recapturer la machine")
+	}
+
+	return r, nil
+}
+
+// Activate redirige toutes les sondes vers l'archive.
+func (r *Replay) Activate() {
+	probe.SetFS(r.FS)
+	probe.SetCommandRunner(r.Run)
+}
+
+// Close restaure le système réel et supprime l'extraction.
+func (r *Replay) Close() error {
+	probe.SetFS(sysfs.Host)
+	probe.SetCommandRunner(nil)
+	return os.RemoveAll(r.Dir)
+}
+
+// Run rejoue la sortie capturée d'une commande externe.
+//
+// "lspci -s <slot>" est servi en filtrant la sortie complète capturée,
+// les autres commandes doivent correspondre exactement à [DefaultCommands].
+func (r *Replay) Run(name string, args ...string) ([]byte, error) {
+	if name == "lspci" {
+		if i := slices.Index(args, "-s"); i >= 0 && i+1 < len(args) {
+			out, err := r.output("lspci", slices.Delete(slices.Clone(args), i, i+2))
+			if err != nil {
+				return nil, err
+			}
+			return filterLines(out, args[i+1]+" "), nil
+		}
+	}
+	return r.output(name, args)
+}
+
+func (r *Replay) output(name string, args []string) ([]byte, error) {
+	for _, cmd := range r.Meta.Commands {
+		if cmd.Name == name && slices.Equal(cmd.Args, args) {
+			return os.ReadFile(filepath.Join(r.Dir, cmdDir, cmd.File))
+		}
+	}
+	return nil, fmt.Errorf("commande non capturée: %s %s", name, strings.Join(args, " "))
+}
+
+func filterLines(data []byte, prefix string) []byte {
+	var out bytes.Buffer
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if strings.HasPrefix(scanner.Text(), prefix) {
+			out.WriteString(scanner.Text())
+			out.WriteByte('\n')
+		}
+	}
+	return out.Bytes()
+}
+
+// extract décompresse le tar.gz dans dir en refusant toute sortie de dir.
+func extract(r io.Reader, dir string) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	if real, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = real
+	}
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target, err := safeJoin(dir, hdr.Name)
+		if err != nil {
+			return err
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0o755); err != nil {
+				return err
+			}
+
+		case tar.TypeSymlink:
+			if filepath.IsAbs(hdr.Linkname) {
+				return fmt.Errorf("lien absolu refusé: %s -> %s", hdr.Name, hdr.Linkname)
+			}
+			parent := filepath.Dir(target)
+			if real, err := filepath.EvalSymlinks(parent); err == nil {
+				parent = real
+			}
+			if !within(dir, filepath.Join(parent, hdr.Linkname)) {
+				return fmt.Errorf("lien hors archive refusé: %s -> %s", hdr.Name, hdr.Linkname)
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			if err := os.Symlink(hdr.Linkname, target); err != nil && !os.IsExist(err) {
+				return err
+			}
+
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			if err := writeRegular(target, tr, hdr.Size); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func writeRegular(path string, r io.Reader, size int64) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.CopyN(f, r, size); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// safeJoin rattache un nom d'entrée tar à dir, sans traversée de chemin
+// et sans écrire à travers un lien symbolique déjà extrait.
+func safeJoin(dir, name string) (string, error) {
+	target := filepath.Join(dir, filepath.Clean("/"+name))
+	if !within(dir, target) {
+		return "", fmt.Errorf("entrée hors archive refusée: %s", name)
+	}
+	parent, err := filepath.EvalSymlinks(filepath.Dir(target))
+	if err == nil && !within(dir, parent) {
+		return "", fmt.Errorf("entrée hors archive refusée: %s", name)
+	}
+	return target, nil
+}
+
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
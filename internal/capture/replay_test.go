@@ -0,0 +1,246 @@
+package capture
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// tarEntry décrit une entrée d'archive de test : fichier si link est vide,
+// lien symbolique sinon.
+type tarEntry struct {
+	name string
+	link string
+	body string
+}
+
+func makeArchive(t *testing.T, entries []tarEntry) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: 0o644, Size: int64(len(e.body)), Typeflag: tar.TypeReg}
+		if e.link != "" {
+			hdr = &tar.Header{Name: e.name, Mode: 0o777, Linkname: e.link, Typeflag: tar.TypeSymlink}
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(e.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []tarEntry
+		want    string // fichier attendu sous dir, relatif
+		wantErr bool
+	}{
+		{
+			// Le nom est ramené sous la racine plutôt que de sortir de dir
+			name:    "dot-dot entry",
+			entries: []tarEntry{{name: "../../escape", body: "x"}},
+			want:    "escape",
+		},
+		{
+			name:    "dot-dot inside name",
+			entries: []tarEntry{{name: "sys/../../../escape", body: "x"}},
+			want:    "escape",
+		},
+		{
+			name:    "absolute name",
+			entries: []tarEntry{{name: "/tmp/escape", body: "x"}},
+			want:    "tmp/escape",
+		},
+		{
+			name: "relative link inside archive",
+			entries: []tarEntry{
+				{name: "sys/devices/pci0/vendor", body: "0x8086"},
+				{name: "sys/class/net/eth0/device", link: "../../../devices/pci0"},
+			},
+			want: "sys/class/net/eth0/device/vendor",
+		},
+		{
+			name:    "absolute link",
+			entries: []tarEntry{{name: "sys/evil", link: "/etc"}},
+			wantErr: true,
+		},
+		{
+			name:    "relative link leaving archive",
+			entries: []tarEntry{{name: "sys/class/evil", link: "../../.."}},
+			wantErr: true,
+		},
+		{
+			// Un lien interne vers un répertoire ne permet pas de remonter
+			// plus haut que la racine depuis sa cible
+			name: "link chained out of archive",
+			entries: []tarEntry{
+				{name: "a/b/c", body: "x"},
+				{name: "up", link: "a/b"},
+				{name: "up/evil", link: "../../.."},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent := t.TempDir()
+			dir := filepath.Join(parent, "replay")
+			if err := os.Mkdir(dir, 0o755); err != nil {
+				t.Fatal(err)
+			}
+
+			err := extract(makeArchive(t, tt.entries), dir)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("extract: want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("extract: %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(dir, tt.want)); err != nil {
+				t.Errorf("%s absent: %v", tt.want, err)
+			}
+			if _, err := os.Lstat(filepath.Join(parent, "escape")); err == nil {
+				t.Error("fichier écrit hors du répertoire d'extraction")
+			}
+		})
+	}
+}
+
+func TestExtractRefusesWriteThroughLink(t *testing.T) {
+	parent := t.TempDir()
+	outside := filepath.Join(parent, "outside")
+	dir := filepath.Join(parent, "replay")
+	for _, d := range []string{outside, dir} {
+		if err := os.Mkdir(d, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Lien déjà présent (extraction précédente, course) pointant hors de dir
+	if err := os.Symlink(outside, filepath.Join(dir, "sys")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := safeJoin(dir, "sys/passwd"); err == nil {
+		t.Error("safeJoin: want error for a parent resolving outside dir")
+	}
+	if _, err := safeJoin(dir, "sys"); err != nil {
+		t.Errorf("safeJoin on the link itself: %v", err)
+	}
+
+	err := extract(makeArchive(t, []tarEntry{{name: "sys/passwd", body: "x"}}), dir)
+	if err == nil {
+		t.Fatal("extract: want error")
+	}
+	if _, err := os.Lstat(filepath.Join(outside, "passwd")); err == nil {
+		t.Error("fichier écrit à travers le lien")
+	}
+}
+
+func TestCaptureReplayRoundTrip(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"sys/devices/pci0000:00/0000:00:1f.6/vendor": "0x8086\n",
+		"sys/devices/pci0000:00/0000:00:1f.6/device": "0x15d7\n",
+		"sys/class/net/eth0/address":                 "54:e1:ad:00:00:01\n",
+		"sys/class/net/eth0/operstate":               "up\n",
+	}
+	for name, body := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink("../../../devices/pci0000:00/0000:00:1f.6",
+		filepath.Join(root, "sys/class/net/eth0/device")); err != nil {
+		t.Fatal(err)
+	}
+	// Lien absolu : non capturé, donc jamais rejoué
+	if err := os.Symlink("/etc", filepath.Join(root, "sys/class/net/eth0/evil")); err != nil {
+		t.Fatal(err)
+	}
+
+	archive := filepath.Join(t.TempDir(), "capture.tar.gz")
+	f, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := []Root{{Path: filepath.Join(root, "sys/class/net"), Depth: 3}}
+	commands := []Command{{Name: "echo", Args: []string{"capture"}, File: "echo.txt"}}
+	if err := Write(f, roots, commands); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	replay, err := Open(archive)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer replay.Close()
+
+	if replay.Meta.Version != FormatVersion {
+		t.Errorf("version = %d, want %d", replay.Meta.Version, FormatVersion)
+	}
+
+	// Les sondes lisent les chemins logiques d'origine sous la racine rejouée
+	net := filepath.Join(root, "sys/class/net/eth0")
+	for path, want := range map[string]string{
+		net + "/address":       "54:e1:ad:00:00:01",
+		net + "/operstate":     "up",
+		net + "/device/vendor": "0x8086",
+		net + "/device/device": "0x15d7",
+	} {
+		got, err := replay.FS.ReadFile(path)
+		if err != nil {
+			t.Errorf("ReadFile(%s): %v", path, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("ReadFile(%s) = %q, want %q", path, got, want)
+		}
+	}
+	if _, err := replay.FS.Readlink(net + "/evil"); err == nil {
+		t.Error("lien absolu rejoué")
+	}
+
+	out, err := replay.Run("echo", "capture")
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if string(out) != "capture\n" {
+		t.Errorf("Run = %q, want %q", out, "capture\n")
+	}
+	if _, err := replay.Run("echo", "autre"); err == nil {
+		t.Error("Run: want error for a command that was not captured")
+	}
+
+	dir := replay.Dir
+	if err := replay.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(dir); err == nil {
+		t.Error("extraction non supprimée par Close")
+	}
+}
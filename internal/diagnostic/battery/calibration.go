@@ -0,0 +1,199 @@
+package battery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"gobox/internal/probe"
+)
+
+// DefaultCalibrationOptions : coupure à 5 %, un point toutes les 30 s.
+func DefaultCalibrationOptions() CalibrationOptions {
+	return CalibrationOptions{
+		CutOff:   5,
+		Interval: 30 * time.Second,
+	}
+}
+
+// RunCalibration recale la jauge : charge complète, décharge jusqu'à
+// opts.CutOff puis recharge complète, en journalisant la courbe. Les seuils
+// de charge sont levés le temps de la calibration ; la décharge passe par
+// charge_behaviour=force-discharge quand le pilote le permet, sinon
+// l'opérateur débranche le secteur. Les réglages d'origine sont restaurés
+// en sortie, même en cas d'annulation. Compter plusieurs heures.
+func RunCalibration(ctx context.Context, opts CalibrationOptions) (result CalibrationResult, err error) {
+	if !probe.FS().IsHost() {
+		return CalibrationResult{}, errors.New("calibration impossible en mode rejeu")
+	}
+	def := DefaultCalibrationOptions()
+	if opts.CutOff <= 0 {
+		opts.CutOff = def.CutOff
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = def.Interval
+	}
+	if opts.Prompt == nil {
+		opts.Prompt = func(message string) error {
+			return fmt.Errorf("action opérateur requise : %s", message)
+		}
+	}
+	if opts.OnSample == nil {
+		opts.OnSample = func(CalibrationSample) {}
+	}
+
+	batteries, err := probe.ListBatteries()
+	if err != nil {
+		return CalibrationResult{}, err
+	}
+	info, err := selectBattery(batteries, opts.Battery)
+	if err != nil {
+		return CalibrationResult{}, err
+	}
+	control, err := probe.GetChargeControl(info.Name)
+	if err != nil {
+		return CalibrationResult{}, err
+	}
+
+	result = CalibrationResult{
+		Battery:      info.Name,
+		Phases:       map[CalibrationPhase]time.Duration{},
+		FullBeforeWh: info.FullWh,
+		DesignWh:     info.DesignWh,
+		Timestamp:    time.Now(),
+	}
+	start := time.Now()
+	defer func() {
+		result.Duration = time.Since(start)
+		err = errors.Join(err, restoreChargeControl(info.Name, control))
+	}()
+
+	// Lever les seuils (0–100) : la calibration exige une charge à 100 %,
+	// et un seuil de début conservé bloquerait la recharge après la
+	// décharge. restoreChargeControl remet les valeurs d'origine.
+	if control.EndThreshold != nil &&
+		(*control.EndThreshold < 100 || control.StartThreshold != nil && *control.StartThreshold > 0) {
+		if err := probe.SetChargeThresholds(info.Name, 0, 100); err != nil {
+			return result, err
+		}
+	}
+	if control.Behaviour != "" && control.Behaviour != probe.ChargeBehaviourAuto {
+		if err := probe.SetChargeBehaviour(info.Name, probe.ChargeBehaviourAuto); err != nil {
+			return result, err
+		}
+	}
+
+	c := calibration{opts: opts, result: &result, start: start}
+
+	// 1. Charge complète
+	if info.Status == StatusDischarging {
+		if err := opts.Prompt("brancher le secteur pour la charge complète"); err != nil {
+			return result, err
+		}
+	}
+	if err := c.waitFor(ctx, PhaseCharge, isFull); err != nil {
+		return result, err
+	}
+
+	// 2. Décharge jusqu'au seuil de coupure
+	if slices.Contains(control.Behaviours, probe.ChargeBehaviourForceDischarge) {
+		if err := probe.SetChargeBehaviour(info.Name, probe.ChargeBehaviourForceDischarge); err != nil {
+			return result, err
+		}
+		result.ForcedDischarge = true
+	} else if err := opts.Prompt("débrancher le secteur pour la décharge"); err != nil {
+		return result, err
+	}
+	if err := c.waitFor(ctx, PhaseDischarge, func(b probe.BatteryInfo) bool {
+		return b.Capacity <= opts.CutOff
+	}); err != nil {
+		return result, err
+	}
+
+	// 3. Recharge complète
+	if result.ForcedDischarge {
+		if err := probe.SetChargeBehaviour(info.Name, probe.ChargeBehaviourAuto); err != nil {
+			return result, err
+		}
+	} else if err := opts.Prompt("rebrancher le secteur pour la recharge"); err != nil {
+		return result, err
+	}
+	if err := c.waitFor(ctx, PhaseRecharge, isFull); err != nil {
+		return result, err
+	}
+
+	after, err := probe.ReadBattery(info.Name)
+	if err != nil {
+		return result, err
+	}
+	result.FullAfterWh = after.FullWh
+	result.Completed = true
+	return result, nil
+}
+
+// calibration journalise les étapes d'une calibration en cours
+type calibration struct {
+	opts   CalibrationOptions
+	result *CalibrationResult
+	start  time.Time
+}
+
+// waitFor relit la batterie à chaque intervalle jusqu'à done.
+func (c *calibration) waitFor(ctx context.Context, phase CalibrationPhase, done func(probe.BatteryInfo) bool) error {
+	phaseStart := time.Now()
+	ticker := time.NewTicker(c.opts.Interval)
+	defer ticker.Stop()
+
+	for {
+		info, err := probe.ReadBattery(c.result.Battery)
+		if err != nil {
+			return fmt.Errorf("%s: %w", phase, err)
+		}
+		sample := CalibrationSample{
+			Phase:    phase,
+			Elapsed:  time.Since(c.start),
+			Capacity: info.Capacity,
+			Status:   info.Status,
+			EnergyWh: info.NowWh,
+			PowerW:   info.PowerNow / 1_000_000,
+		}
+		c.result.Samples = append(c.result.Samples, sample)
+		c.opts.OnSample(sample)
+
+		if done(info) {
+			c.result.Phases[phase] = time.Since(phaseStart)
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func isFull(b probe.BatteryInfo) bool {
+	return b.Status == StatusFull || b.Capacity >= 100
+}
+
+// restoreChargeControl remet les seuils et le comportement d'origine.
+func restoreChargeControl(name string, control probe.ChargeControl) error {
+	var errs []error
+	if control.EndThreshold != nil {
+		low := 0
+		if control.StartThreshold != nil {
+			low = *control.StartThreshold
+		}
+		errs = append(errs, probe.SetChargeThresholds(name, low, *control.EndThreshold))
+	}
+	if control.Behaviour != "" {
+		errs = append(errs, probe.SetChargeBehaviour(name, control.Behaviour))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("restauration des réglages de charge: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package battery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gobox/internal/diagnostic/cpu"
+	"gobox/internal/probe"
+)
+
+// DefaultDischargeOptions : un quart d'heure, un point toutes les 5 s.
+func DefaultDischargeOptions() DischargeOptions {
+	return DischargeOptions{
+		Duration:    15 * time.Minute,
+		Interval:    5 * time.Second,
+		MinCapacity: 10,
+	}
+}
+
+// RunDischargeTest mesure la décharge réelle d'une batterie, secteur
+// débranché : la puissance est échantillonnée et intégrée pour obtenir
+// l'énergie consommée, rapportée aux points de charge perdus pour
+// extrapoler la capacité réelle et l'autonomie. La jauge (energy_full)
+// n'est pas crue sur parole.
+//
+// En cas d'annulation, le résultat partiel est retourné avec ctx.Err().
+func RunDischargeTest(ctx context.Context, opts DischargeOptions) (DischargeResult, error) {
+	if !probe.FS().IsHost() {
+		return DischargeResult{}, errors.New("test de décharge impossible en mode rejeu")
+	}
+	def := DefaultDischargeOptions()
+	if opts.Duration <= 0 {
+		opts.Duration = def.Duration
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = def.Interval
+	}
+	if opts.OnSample == nil {
+		opts.OnSample = func(DischargeSample) {}
+	}
+
+	status, err := probe.GetPowerStatus()
+	if err != nil {
+		return DischargeResult{}, fmt.Errorf("récupération batterie: %w", err)
+	}
+	info, err := selectBattery(status.Batteries, opts.Battery)
+	if err != nil {
+		return DischargeResult{}, err
+	}
+	if status.OnAC || info.Status != StatusDischarging {
+		return DischargeResult{}, fmt.Errorf("%s en statut %q : débrancher le secteur avant le test", info.Name, info.Status)
+	}
+	if info.Capacity <= opts.MinCapacity {
+		return DischargeResult{}, fmt.Errorf("%s chargée à %d %% : charge insuffisante (minimum %d %%)",
+			info.Name, info.Capacity, opts.MinCapacity)
+	}
+
+	criteria := DefaultDischargeGradingCriteria()
+	result := DischargeResult{
+		Battery:            info.Name,
+		CPULoad:            opts.CPULoad,
+		ReportedCapacityWh: info.FullWh,
+		DesignCapacityWh:   info.DesignWh,
+		Timestamp:          time.Now(),
+	}
+
+	// Charge contrôlée : le workload du stress CPU tourne pendant toute la
+	// mesure, on laisse la tension se stabiliser avant le premier point.
+	if opts.CPULoad {
+		loadCtx, stopLoad := context.WithCancel(ctx)
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cpu.RunCPUStressTest(loadCtx, cpu.CPUStressOptions{Duration: opts.Duration + opts.Interval}, nil)
+		}()
+		defer wg.Wait()
+		defer stopLoad()
+
+		select {
+		case <-ctx.Done():
+			return result, ctx.Err()
+		case <-time.After(opts.Interval):
+		}
+	}
+
+	ticker := time.NewTicker(opts.Interval)
+	defer ticker.Stop()
+	start := time.Now()
+
+	for {
+		sample, stop := takeSample(&result, criteria, start)
+		if sample != nil {
+			result.Samples = append(result.Samples, *sample)
+			opts.OnSample(*sample)
+		}
+		switch {
+		case stop != "":
+		case sample.Capacity <= opts.MinCapacity:
+			stop = "charge minimale atteinte"
+		case sample.Elapsed >= opts.Duration:
+			stop = "durée atteinte"
+		}
+		if stop != "" {
+			result.Stopped = stop
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			result.Stopped = "interrompu"
+			result.summarize(criteria)
+			return result, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+
+	result.summarize(criteria)
+	return result, nil
+}
+
+func selectBattery(batteries []probe.BatteryInfo, name string) (probe.BatteryInfo, error) {
+	for _, b := range batteries {
+		if name == "" || b.Name == name {
+			return b, nil
+		}
+	}
+	if name == "" {
+		return probe.BatteryInfo{}, errors.New("aucune batterie détectée")
+	}
+	return probe.BatteryInfo{}, fmt.Errorf("batterie %s introuvable", name)
+}
+
+// takeSample relit la batterie et détecte les anomalies par rapport au
+// point précédent. Retourne une raison d'arrêt non vide pour finir la mesure.
+func takeSample(result *DischargeResult, criteria DischargeGradingCriteria, start time.Time) (*DischargeSample, string) {
+	info, err := probe.ReadBattery(result.Battery)
+	if err != nil {
+		result.CutOff = true
+		return nil, "batterie disparue"
+	}
+	if info.Status == StatusCharging || info.Status == StatusFull {
+		return nil, "secteur rebranché"
+	}
+
+	sample := DischargeSample{
+		Elapsed:  time.Since(start),
+		Capacity: info.Capacity,
+		EnergyWh: info.NowWh,
+		PowerW:   info.PowerNow / 1_000_000,
+		VoltageV: info.VoltageNow / 1_000_000,
+	}
+	if len(result.Samples) == 0 {
+		return &sample, ""
+	}
+
+	prev := result.Samples[len(result.Samples)-1]
+	// Sans power_now ni current_now, la puissance se déduit de la jauge
+	if sample.PowerW == 0 && prev.EnergyWh > sample.EnergyWh {
+		sample.PowerW = (prev.EnergyWh - sample.EnergyWh) / (sample.Elapsed - prev.Elapsed).Hours()
+	}
+	if prev.VoltageV > 0 && (prev.VoltageV-sample.VoltageV)/prev.VoltageV > criteria.MaxVoltageSagRatio {
+		result.VoltageSags++
+	}
+	if prev.Capacity-sample.Capacity >= criteria.MaxCapacityJump || (sample.Capacity == 0 && prev.Capacity > 0) {
+		result.CutOff, result.cutOffSample = true, true
+		return &sample, "chute brutale de la charge"
+	}
+	return &sample, ""
+}
+
+// summarize intègre la courbe et extrapole capacité et autonomie. Le point
+// d'une chute brutale est exclu : il fausserait l'extrapolation.
+func (r *DischargeResult) summarize(criteria DischargeGradingCriteria) {
+	samples := r.Samples
+	if r.cutOffSample && len(samples) > 1 {
+		samples = samples[:len(samples)-1]
+	}
+	if len(samples) > 0 {
+		r.Duration = samples[len(samples)-1].Elapsed
+	}
+
+	if len(samples) >= 2 {
+		first, last := samples[0], samples[len(samples)-1]
+		for i := 1; i < len(samples); i++ {
+			dt := (samples[i].Elapsed - samples[i-1].Elapsed).Hours()
+			r.EnergyDrawnWh += (samples[i].PowerW + samples[i-1].PowerW) / 2 * dt
+		}
+		r.CounterDrawnWh = first.EnergyWh - last.EnergyWh
+		if r.EnergyDrawnWh == 0 {
+			r.EnergyDrawnWh = r.CounterDrawnWh
+		}
+		r.PercentDrop = first.Capacity - last.Capacity
+		if hours := (last.Elapsed - first.Elapsed).Hours(); hours > 0 {
+			r.AvgPowerW = r.EnergyDrawnWh / hours
+		}
+	}
+
+	if r.PercentDrop >= criteria.MinPercentDrop && r.EnergyDrawnWh > 0 {
+		r.MeasuredCapacityWh = r.EnergyDrawnWh / (float64(r.PercentDrop) / 100)
+		r.MeasuredHealth = CalculateHealthPercent(r.DesignCapacityWh, r.MeasuredCapacityWh)
+	}
+	capacity := r.MeasuredCapacityWh
+	if capacity == 0 {
+		capacity = r.ReportedCapacityWh
+	}
+	if r.AvgPowerW > 0 {
+		r.Runtime = time.Duration(capacity / r.AvgPowerW * float64(time.Hour))
+	}
+
+	r.Grade = ComputeDischargeGrade(criteria, DefaultBatteryGradingCriteria(), *r)
+	r.Issues = DetectDischargeIssues(*r, criteria)
+}
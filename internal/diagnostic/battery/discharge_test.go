@@ -0,0 +1,35 @@
+package battery
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSummarizeCutOff(t *testing.T) {
+	samples := []DischargeSample{
+		{Elapsed: 0, Capacity: 80, EnergyWh: 40, PowerW: 10},
+		{Elapsed: 30 * time.Minute, Capacity: 70, EnergyWh: 35, PowerW: 10},
+		{Elapsed: time.Hour, Capacity: 60, EnergyWh: 30, PowerW: 10},
+	}
+	tests := []struct {
+		name         string
+		samples      []DischargeSample
+		cutOffSample bool
+		wantDuration time.Duration
+		wantDrop     int
+	}{
+		// Batterie disparue : aucun échantillon ajouté, le dernier reste valide
+		{"battery gone", samples, false, time.Hour, 20},
+		// Chute brutale : l'échantillon de la chute est exclu
+		{"capacity jump", append(samples, DischargeSample{Elapsed: 90 * time.Minute, Capacity: 0, PowerW: 10}),
+			true, time.Hour, 20},
+	}
+	for _, tt := range tests {
+		r := DischargeResult{Samples: tt.samples, CutOff: true, cutOffSample: tt.cutOffSample, DesignCapacityWh: 50}
+		r.summarize(DefaultDischargeGradingCriteria())
+		if r.Duration != tt.wantDuration || r.PercentDrop != tt.wantDrop || r.EnergyDrawnWh != 10 {
+			t.Errorf("%s: duration %s, drop %d, drawn %.1f Wh; want %s, %d, 10 Wh",
+				tt.name, r.Duration, r.PercentDrop, r.EnergyDrawnWh, tt.wantDuration, tt.wantDrop)
+		}
+	}
+}
@@ -2,8 +2,10 @@ package battery
 
 import (
 	"fmt"
+	"time"
 
 	"gobox/internal/diagnostic/common"
+	"gobox/internal/probe"
 )
 
 const (
@@ -21,6 +23,9 @@ func DefaultBatteryGradingCriteria() BatteryGradingCriteria {
 		MaxCyclesForA: 300,  // <= 300 cycles = A
 		MaxCyclesForB: 500,  // <= 500 cycles = B
 		MaxCyclesForC: 800,  // <= 800 cycles = C
+
+		MaxTemperatureC: 50, // une batterie au repos dépasse rarement 40 °C
+		MaxAgeYears:     5,  // le lithium vieillit même sans cycles
 	}
 }
 
@@ -42,6 +47,20 @@ func ComputeGrade(
 	return common.WorseGrade(healthGrade, cycleGrade)
 }
 
+// CombinedGrade retient la pire note : une seule batterie usée suffit à
+// raccourcir l'autonomie de la machine.
+func CombinedGrade(tests []BatteryHealthTest) common.Grade {
+	var grade common.Grade
+	for i, t := range tests {
+		if i == 0 {
+			grade = t.Grade
+		} else {
+			grade = common.WorseGrade(grade, t.Grade)
+		}
+	}
+	return grade
+}
+
 func gradeFromHealth(criteria BatteryGradingCriteria, healthPercent float64) common.Grade {
 	switch {
 	case healthPercent >= criteria.MinHealthForA:
@@ -69,12 +88,13 @@ func gradeFromCycle(criteria BatteryGradingCriteria, cycleCount int) common.Grad
 }
 
 func DetectIssues(
-	status string,
+	info probe.BatteryInfo,
 	healthPercentage float64,
-	cycleCount int,
+	ageYears float64,
 	criteria BatteryGradingCriteria,
 ) []string {
 	issues := []string{}
+	status, cycleCount := info.Status, info.Cycle
 
 	validStatuses := map[string]bool{
 		StatusCharging:    true,
@@ -105,5 +125,107 @@ func DetectIssues(
 		)
 	}
 
+	if t := info.TemperatureC; t != nil && *t > criteria.MaxTemperatureC {
+		issues = append(issues, fmt.Sprintf("Batterie en surchauffe : %.1f °C (>%.0f °C)", *t, criteria.MaxTemperatureC))
+	}
+	if h := info.Health; h != nil && !firmwareHealthOK[*h] {
+		issues = append(issues, fmt.Sprintf("Le firmware signale un état anormal : %s", *h))
+	}
+	if ageYears > criteria.MaxAgeYears {
+		issues = append(issues, fmt.Sprintf("Cellules âgées de %.1f ans (>%.0f ans), considérer un remplacement.",
+			ageYears, criteria.MaxAgeYears))
+	}
+
 	return issues
 }
+
+// firmwareHealthOK sont les valeurs de health qui ne signalent rien
+var firmwareHealthOK = map[string]bool{
+	"Good":    true,
+	"Unknown": true,
+}
+
+// AgeYears calcule l'âge des cellules depuis la date de fabrication.
+func AgeYears(info probe.BatteryInfo, now time.Time) float64 {
+	if info.ManufactureDate == nil || now.Before(*info.ManufactureDate) {
+		return 0
+	}
+	return now.Sub(*info.ManufactureDate).Hours() / (24 * 365.25)
+}
+
+func DefaultDischargeGradingCriteria() DischargeGradingCriteria {
+	return DischargeGradingCriteria{
+		MinPercentDrop:     5,   // 1 % de résolution : ±20 % d'erreur au pire
+		MinReportedRatio:   0.8, // la jauge annonce 25 % de plus que la réalité
+		ReportedMaxGrade:   common.GradeC,
+		MaxVoltageSagRatio: 0.08, // une charge CPU fait baisser la tension de quelques %
+		VoltageSagMaxGrade: common.GradeC,
+		MaxCapacityJump:    10,
+		CutOffMaxGrade:     common.GradeF,
+	}
+}
+
+// ComputeDischargeGrade note la capacité réellement mesurée avec les
+// seuils de santé habituels, puis les anomalies de la courbe. Une mesure
+// trop courte pour extrapoler et sans anomalie n'est pas notée.
+func ComputeDischargeGrade(criteria DischargeGradingCriteria, health BatteryGradingCriteria, r DischargeResult) common.Grade {
+	var grade common.Grade
+	worse := func(g common.Grade) {
+		if grade == "" {
+			grade = g
+		} else {
+			grade = common.WorseGrade(grade, g)
+		}
+	}
+
+	if r.MeasuredCapacityWh > 0 {
+		if r.DesignCapacityWh > 0 {
+			worse(gradeFromHealth(health, r.MeasuredHealth))
+		} else {
+			worse(common.GradeA)
+		}
+		if reportedRatio(r) < criteria.MinReportedRatio {
+			worse(criteria.ReportedMaxGrade)
+		}
+	}
+	if r.VoltageSags > 0 {
+		worse(criteria.VoltageSagMaxGrade)
+	}
+	if r.CutOff {
+		worse(criteria.CutOffMaxGrade)
+	}
+	return grade
+}
+
+func DetectDischargeIssues(r DischargeResult, criteria DischargeGradingCriteria) []string {
+	issues := []string{}
+
+	if r.CutOff {
+		issues = append(issues, "Chute brutale de la charge pendant la décharge : la machine risque de s'éteindre sans prévenir")
+	}
+	if r.VoltageSags > 0 {
+		issues = append(issues, fmt.Sprintf("%d chute(s) de tension brutale(s) (>%.0f %%) : cellule faible ?",
+			r.VoltageSags, criteria.MaxVoltageSagRatio*100))
+	}
+	if r.MeasuredCapacityWh == 0 {
+		if !r.CutOff {
+			issues = append(issues, fmt.Sprintf("Baisse de %d %% seulement : mesure trop courte pour extrapoler la capacité (%d %% minimum)",
+				r.PercentDrop, criteria.MinPercentDrop))
+		}
+		return issues
+	}
+	if ratio := reportedRatio(r); ratio < criteria.MinReportedRatio {
+		issues = append(issues, fmt.Sprintf("Capacité mesurée %.1f Wh pour %.1f Wh annoncés (%.0f %%) : jauge trop optimiste",
+			r.MeasuredCapacityWh, r.ReportedCapacityWh, ratio*100))
+	}
+	return issues
+}
+
+// reportedRatio compare la capacité mesurée à celle annoncée ; 1 si
+// l'une des deux est inconnue.
+func reportedRatio(r DischargeResult) float64 {
+	if r.MeasuredCapacityWh <= 0 || r.ReportedCapacityWh <= 0 {
+		return 1
+	}
+	return r.MeasuredCapacityWh / r.ReportedCapacityWh
+}
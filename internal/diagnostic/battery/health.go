@@ -7,39 +7,59 @@ import (
 	"gobox/internal/probe"
 )
 
-// RunBatteryTest exécute le test batterie et retourne le résultat structuré
-// Retourne (résultat, erreur)
-func RunBatteryTest() (BatteryHealthTest, error) {
-	// 1. Récupérer les données brutes
-	info, err := probe.GetBatteryInfo()
-	if err != nil {
-		return BatteryHealthTest{}, fmt.Errorf("récupération batterie: %w", err)
-	}
-
-	// 2. Vérifier que la batterie existe
-	if info.Capacity < 0 {
-		return BatteryHealthTest{}, fmt.Errorf("batterie non détectée")
-	}
-
-	// 3. Charger les critères
+// RunBatteryTest note une batterie à partir des données de la sonde
+func RunBatteryTest(info probe.BatteryInfo) BatteryHealthTest {
+	// 1. Charger les critères
 	criteria := DefaultBatteryGradingCriteria()
 
-	// 4. Calculer la santé
+	// 2. Calculer la santé
 	healthPercent := CalculateHealthPercent(info.DesignCapacity, info.CurrentCapacity)
 
-	// 5. Obtenir le grade
+	// 3. Obtenir le grade
 	grade := ComputeGrade(criteria, healthPercent, info.Cycle)
 
-	// 6. Détecter les problèmes
-	issues := DetectIssues(info.Status, healthPercent, info.Cycle, criteria)
+	// 4. Détecter les problèmes
+	now := time.Now()
+	age := AgeYears(info, now)
+	issues := DetectIssues(info, healthPercent, age, criteria)
 
-	// 7. Construire et retourner le résultat
+	// 5. Construire et retourner le résultat
 	return BatteryHealthTest{
+		Name:             info.Name,
 		Status:           info.Status,
 		Grade:            grade,
 		HealthPercentage: healthPercent,
 		CycleCount:       info.Cycle,
+		AgeYears:         age,
+		Info:             info,
 		Issues:           issues,
+		Timestamp:        now,
+	}
+}
+
+// RunAllBatteryTests note chaque batterie séparément et calcule la note
+// de l'ensemble. Un rapport sans batterie (poste fixe) n'est pas une erreur.
+func RunAllBatteryTests() (BatteryReport, error) {
+	status, err := probe.GetPowerStatus()
+	if err != nil {
+		return BatteryReport{}, fmt.Errorf("récupération batterie: %w", err)
+	}
+
+	report := BatteryReport{
+		Supplies:         status.Supplies,
+		OnAC:             status.OnAC,
+		Capacity:         status.Capacity,
+		HealthPercentage: CalculateHealthPercent(status.DesignWh, status.FullWh),
 		Timestamp:        time.Now(),
-	}, nil
+	}
+	for _, info := range status.Batteries {
+		test := RunBatteryTest(info)
+		report.Batteries = append(report.Batteries, test)
+		for _, issue := range test.Issues {
+			report.Issues = append(report.Issues, test.Name+": "+issue)
+		}
+	}
+	report.Grade = CombinedGrade(report.Batteries)
+
+	return report, nil
 }
@@ -4,17 +4,33 @@ import (
 	"time"
 
 	"gobox/internal/diagnostic/common"
+	"gobox/internal/probe"
 )
 
 type BatteryHealthTest struct {
+	Name             string // ex: "BAT0"
 	Status           string
 	Grade            common.Grade
 	HealthPercentage float64
 	CycleCount       int
+	AgeYears         float64           // depuis la date de fabrication, 0 si inconnue
+	Info             probe.BatteryInfo // données brutes de la sonde
 	Issues           []string
 	Timestamp        time.Time
 }
 
+// BatteryReport regroupe les batteries et les alimentations de la machine
+type BatteryReport struct {
+	Batteries        []BatteryHealthTest
+	Supplies         []probe.PowerSupply
+	OnAC             bool
+	Capacity         int          // charge globale en %
+	HealthPercentage float64      // santé de l'ensemble : capacités actuelles / neuves
+	Grade            common.Grade // pire note des batteries, "" si aucune
+	Issues           []string     // préfixés du nom de la batterie
+	Timestamp        time.Time
+}
+
 type BatteryGradingCriteria struct {
 	MinHealthForA float64
 	MinHealthForB float64
@@ -22,4 +38,111 @@ type BatteryGradingCriteria struct {
 	MaxCyclesForA int
 	MaxCyclesForB int
 	MaxCyclesForC int
+
+	MaxTemperatureC float64 // au-delà : surchauffe signalée
+	MaxAgeYears     float64 // au-delà : cellules vieillies signalées
+}
+
+// DischargeOptions paramètre le test de décharge
+type DischargeOptions struct {
+	Battery     string        // batterie à mesurer ; vide = la première
+	Duration    time.Duration // durée maximale de la mesure, ex: 15 min
+	Interval    time.Duration // période d'échantillonnage, ex: 5 s
+	MinCapacity int           // arrêt sous ce niveau de charge (%), protège la batterie
+	CPULoad     bool          // charge contrôlée : workload du test de stress CPU
+	OnSample    func(DischargeSample)
+}
+
+// DischargeSample est un point de la courbe de décharge
+type DischargeSample struct {
+	Elapsed  time.Duration `json:"elapsed_ns"`
+	Capacity int           `json:"capacity_percent"`
+	EnergyWh float64       `json:"energy_wh"` // charge restante selon la jauge
+	PowerW   float64       `json:"power_w"`
+	VoltageV float64       `json:"voltage_v"`
+}
+
+// DischargeResult résultat du test de décharge
+type DischargeResult struct {
+	Battery  string
+	CPULoad  bool
+	Samples  []DischargeSample // courbe complète, pour graphique et export
+	Duration time.Duration
+	Stopped  string // raison de l'arrêt, ex: "durée atteinte"
+
+	EnergyDrawnWh      float64       // intégrale de la puissance mesurée
+	CounterDrawnWh     float64       // baisse du compteur de la jauge sur la même période
+	PercentDrop        int           // points de charge perdus
+	AvgPowerW          float64       // puissance moyenne pendant la mesure
+	MeasuredCapacityWh float64       // capacité extrapolée, 0 si la baisse est trop faible
+	ReportedCapacityWh float64       // energy_full annoncée par la jauge
+	DesignCapacityWh   float64       // capacité neuve
+	MeasuredHealth     float64       // capacité mesurée / neuve (%), 0 si non mesurée
+	Runtime            time.Duration // autonomie d'une charge complète au régime du test
+
+	VoltageSags int  // chutes de tension brutales entre deux échantillons
+	CutOff      bool // chute brutale de la charge ou batterie disparue
+
+	// cutOffSample : le dernier échantillon est la chute brutale elle-même,
+	// exclu de l'extrapolation (faux si la batterie a disparu sans mesure).
+	cutOffSample bool
+
+	Grade     common.Grade // "" si la mesure est insuffisante
+	Issues    []string
+	Timestamp time.Time
+}
+
+// DischargeGradingCriteria critères de notation du test de décharge
+type DischargeGradingCriteria struct {
+	MinPercentDrop     int          // baisse minimale pour extrapoler, ex: 5 points
+	MinReportedRatio   float64      // mesurée / annoncée, en dessous la jauge ment, ex: 0.8
+	ReportedMaxGrade   common.Grade // jauge trop optimiste
+	MaxVoltageSagRatio float64      // chute relative d'un échantillon au suivant, ex: 0.08
+	VoltageSagMaxGrade common.Grade
+	MaxCapacityJump    int          // points perdus d'un échantillon au suivant, ex: 10
+	CutOffMaxGrade     common.Grade // chute à 0 % : la machine s'éteindrait sans prévenir
+}
+
+// CalibrationOptions paramètre la calibration guidée
+type CalibrationOptions struct {
+	Battery  string        // vide = la première
+	CutOff   int           // fin de la décharge (%), ex: 5
+	Interval time.Duration // période de journalisation, ex: 30 s
+	// Prompt demande une action à l'opérateur (brancher, débrancher le
+	// secteur) et rend la main quand elle est faite.
+	Prompt   func(message string) error
+	OnSample func(CalibrationSample)
+}
+
+// CalibrationPhase est une étape de la calibration
+type CalibrationPhase string
+
+const (
+	PhaseCharge    CalibrationPhase = "charge"    // charge complète
+	PhaseDischarge CalibrationPhase = "discharge" // décharge jusqu'au seuil de coupure
+	PhaseRecharge  CalibrationPhase = "recharge"  // recharge complète
+)
+
+// CalibrationSample est un point du journal de calibration
+type CalibrationSample struct {
+	Phase    CalibrationPhase `json:"phase"`
+	Elapsed  time.Duration    `json:"elapsed_ns"`
+	Capacity int              `json:"capacity_percent"`
+	Status   string           `json:"status"`
+	EnergyWh float64          `json:"energy_wh"`
+	PowerW   float64          `json:"power_w"`
+}
+
+// CalibrationResult résultat de la calibration
+type CalibrationResult struct {
+	Battery         string
+	Samples         []CalibrationSample
+	Phases          map[CalibrationPhase]time.Duration // durée de chaque étape terminée
+	FullBeforeWh    float64                            // energy_full avant calibration
+	FullAfterWh     float64                            // energy_full après recharge
+	DesignWh        float64
+	ForcedDischarge bool // décharge pilotée par charge_behaviour, secteur branché
+	Completed       bool
+	Duration        time.Duration
+	Timestamp       time.Time
 }
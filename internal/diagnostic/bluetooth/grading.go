@@ -0,0 +1,55 @@
+package bluetooth
+
+import (
+	"fmt"
+
+	"gobox/internal/diagnostic/common"
+)
+
+func DefaultBluetoothGradingCriteria() BluetoothGradingCriteria {
+	return BluetoothGradingCriteria{
+		NoDeviceMaxGrade: common.GradeC, // un banc sans appareil Bluetooth à portée reste possible
+		MinRSSIForA:      -80,
+		WeakSignalGrade:  common.GradeB,
+	}
+}
+
+// ComputeGrade note un adaptateur scanné. Un adaptateur bloqué ou éteint
+// n'est pas noté : on ne distingue pas un réglage d'une radio morte.
+func ComputeGrade(criteria BluetoothGradingCriteria, check AdapterCheck) common.Grade {
+	if !check.Scanned {
+		return ""
+	}
+	if len(check.Devices) == 0 {
+		return criteria.NoDeviceMaxGrade
+	}
+	if check.BestRSSI < criteria.MinRSSIForA {
+		return criteria.WeakSignalGrade
+	}
+	return common.GradeA
+}
+
+func DetectIssues(check AdapterCheck, criteria BluetoothGradingCriteria) []string {
+	issues := []string{}
+	a := check.Adapter
+
+	if a.HardBlocked {
+		issues = append(issues, "Radio bloquée par un interrupteur matériel ou le BIOS (rfkill)")
+	}
+	if a.SoftBlocked {
+		issues = append(issues, "Radio bloquée par rfkill (rfkill unblock bluetooth)")
+	}
+	if !check.Scanned {
+		if !a.IsUp && !a.HardBlocked && !a.SoftBlocked {
+			issues = append(issues, "Adaptateur éteint : découverte non testée")
+		}
+		return issues
+	}
+
+	if len(check.Devices) == 0 {
+		issues = append(issues, "Aucun appareil Bluetooth visible : radio ou antenne défectueuse ?")
+	} else if check.BestRSSI < criteria.MinRSSIForA {
+		issues = append(issues, fmt.Sprintf("Signal faible : meilleur appareil à %d dBm (antenne débranchée ?)", check.BestRSSI))
+	}
+	return issues
+}
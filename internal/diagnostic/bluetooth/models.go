@@ -0,0 +1,41 @@
+package bluetooth
+
+import (
+	"time"
+
+	"gobox/internal/diagnostic/common"
+	"gobox/internal/probe"
+)
+
+// ScanOptions paramètre la découverte Bluetooth
+type ScanOptions struct {
+	Adapters []string      // adaptateurs à tester ; vide = tous
+	Duration time.Duration // durée de la découverte par adaptateur
+}
+
+// AdapterCheck est le bilan d'un adaptateur
+type AdapterCheck struct {
+	Adapter probe.BluetoothAdapter
+	Scanned bool // découverte menée à terme
+
+	Devices  []probe.BluetoothDevice // appareils vus, dans l'ordre de découverte
+	BestRSSI int                     // meilleur signal en dBm, 0 si aucun
+
+	Grade  common.Grade // "" si l'adaptateur n'a pas pu scanner
+	Issues []string
+}
+
+// ScanResult résultat du test Bluetooth
+type ScanResult struct {
+	Adapters  []AdapterCheck
+	Grade     common.Grade // pire note des adaptateurs scannés, "" si aucun
+	Issues    []string
+	Timestamp time.Time
+}
+
+// BluetoothGradingCriteria critères de notation Bluetooth
+type BluetoothGradingCriteria struct {
+	NoDeviceMaxGrade common.Grade // découverte sans aucun appareil
+	MinRSSIForA      int          // meilleur signal attendu d'un appareil du banc, ex: -80 dBm
+	WeakSignalGrade  common.Grade // meilleur signal sous MinRSSIForA : antenne ?
+}
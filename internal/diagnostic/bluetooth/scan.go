@@ -0,0 +1,85 @@
+package bluetooth
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"gobox/internal/diagnostic/common"
+	"gobox/internal/probe"
+)
+
+func DefaultScanOptions() ScanOptions {
+	return ScanOptions{Duration: 10 * time.Second}
+}
+
+// RunBluetoothScan lance une découverte sur chaque adaptateur actif et non
+// bloqué : voir au moins un appareil prouve que la radio reçoit.
+func RunBluetoothScan(ctx context.Context, opts ScanOptions, onProgress func(fraction float64, message string)) (ScanResult, error) {
+	if !probe.FS().IsHost() {
+		return ScanResult{}, errors.New("découverte Bluetooth impossible en mode rejeu")
+	}
+	if onProgress == nil {
+		onProgress = func(float64, string) {}
+	}
+	if opts.Duration <= 0 {
+		opts.Duration = DefaultScanOptions().Duration
+	}
+
+	adapters, err := probe.ListBluetoothAdapters()
+	if err != nil {
+		return ScanResult{}, err
+	}
+	if len(opts.Adapters) > 0 {
+		adapters = slices.DeleteFunc(adapters, func(a probe.BluetoothAdapter) bool {
+			return !slices.Contains(opts.Adapters, a.Name)
+		})
+	}
+
+	criteria := DefaultBluetoothGradingCriteria()
+	result := ScanResult{Timestamp: time.Now()}
+
+	for i, adapter := range adapters {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		onProgress(float64(i)/float64(len(adapters)), adapter.Name+" découverte")
+
+		check := AdapterCheck{Adapter: adapter}
+		if adapter.IsUp && !adapter.SoftBlocked && !adapter.HardBlocked {
+			devices, err := probe.DiscoverBluetooth(ctx, adapter.Name, opts.Duration)
+			if err != nil {
+				if ctx.Err() != nil {
+					return result, ctx.Err()
+				}
+				check.Issues = append(check.Issues, "Découverte impossible : "+err.Error())
+			} else {
+				check.Scanned = true
+				check.Devices = devices
+				for j, d := range devices {
+					if j == 0 || d.RSSI > check.BestRSSI {
+						check.BestRSSI = d.RSSI
+					}
+				}
+			}
+		}
+		check.Grade = ComputeGrade(criteria, check)
+		check.Issues = append(check.Issues, DetectIssues(check, criteria)...)
+		result.Adapters = append(result.Adapters, check)
+
+		if check.Grade != "" {
+			if result.Grade == "" {
+				result.Grade = check.Grade
+			} else {
+				result.Grade = common.WorseGrade(result.Grade, check.Grade)
+			}
+		}
+		for _, issue := range check.Issues {
+			result.Issues = append(result.Issues, adapter.Name+": "+issue)
+		}
+	}
+	onProgress(1, "terminé")
+
+	return result, ctx.Err()
+}
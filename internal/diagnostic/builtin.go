@@ -0,0 +1,467 @@
+package diagnostic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gobox/internal/diagnostic/battery"
+	"gobox/internal/diagnostic/bluetooth"
+	"gobox/internal/diagnostic/common"
+	"gobox/internal/diagnostic/cpu"
+	"gobox/internal/diagnostic/disk"
+	"gobox/internal/diagnostic/network"
+	"gobox/internal/diagnostic/ram"
+	"gobox/internal/probe"
+)
+
+// Diagnostics intégrés, enregistrés dans l'ordre d'affichage.
+func init() {
+	Register(batteryDiagnostic{})
+	Register(batteryRuntimeDiagnostic{})
+	Register(diskDiagnostic{})
+	Register(diskBenchDiagnostic{})
+	Register(diskSurfaceDiagnostic{})
+	Register(cpuDiagnostic{})
+	Register(ramDiagnostic{})
+	Register(networkDiagnostic{})
+	Register(bluetoothDiagnostic{})
+}
+
+// ═══════════════════════════════════════════════════════════════════
+// BATTERIE
+// ═══════════════════════════════════════════════════════════════════
+
+type batteryDiagnostic struct{}
+
+func (batteryDiagnostic) ID() string                       { return "battery" }
+func (batteryDiagnostic) Description() string              { return "Santé et cycles de la batterie" }
+func (batteryDiagnostic) Privileges() []Privilege          { return nil }
+func (batteryDiagnostic) EstimatedDuration() time.Duration { return time.Second }
+func (batteryDiagnostic) Offline() bool                    { return true }
+
+func (batteryDiagnostic) Run(ctx context.Context, progress ProgressFunc) (Result, error) {
+	test, err := battery.RunAllBatteryTests()
+	if err != nil {
+		return Result{}, err
+	}
+	if len(test.Batteries) == 0 {
+		return Result{Summary: "aucune batterie", Details: test}, nil
+	}
+
+	var parts []string
+	for _, b := range test.Batteries {
+		parts = append(parts, fmt.Sprintf("%s santé %.1f %%, %d cycles", b.Name, b.HealthPercentage, b.CycleCount))
+	}
+	return Result{
+		Grade:   test.Grade,
+		Summary: strings.Join(parts, ", "),
+		Issues:  test.Issues,
+		Details: test,
+	}, nil
+}
+
+type batteryRuntimeDiagnostic struct{}
+
+func (batteryRuntimeDiagnostic) ID() string { return "battery-runtime" }
+func (batteryRuntimeDiagnostic) Description() string {
+	return "Décharge mesurée, capacité réelle et autonomie (secteur débranché)"
+}
+func (batteryRuntimeDiagnostic) Privileges() []Privilege { return nil }
+func (batteryRuntimeDiagnostic) OptIn() bool             { return true } // secteur débranché, 15 min
+func (batteryRuntimeDiagnostic) EstimatedDuration() time.Duration {
+	return battery.DefaultDischargeOptions().Duration
+}
+
+func (batteryRuntimeDiagnostic) Run(ctx context.Context, progress ProgressFunc) (Result, error) {
+	batteries, err := probe.ListBatteries()
+	if err != nil {
+		return Result{}, err
+	}
+	if len(batteries) == 0 {
+		return Result{Summary: "aucune batterie"}, nil
+	}
+
+	opts := battery.DefaultDischargeOptions()
+	opts.OnSample = func(s battery.DischargeSample) {
+		progress(Progress{
+			Fraction: min(s.Elapsed.Seconds()/opts.Duration.Seconds(), 1),
+			Message:  fmt.Sprintf("%d %%, %.1f W", s.Capacity, s.PowerW),
+		})
+	}
+	test, err := battery.RunDischargeTest(ctx, opts)
+	if err != nil {
+		return Result{}, err
+	}
+
+	summary := fmt.Sprintf("%.2f Wh en %s, %d %% perdus", test.EnergyDrawnWh, test.Duration.Round(time.Second), test.PercentDrop)
+	if test.MeasuredCapacityWh > 0 {
+		summary = fmt.Sprintf("%.1f Wh mesurés (%.0f %% du neuf), autonomie ~%s",
+			test.MeasuredCapacityWh, test.MeasuredHealth, test.Runtime.Round(time.Minute))
+	}
+	return Result{
+		Grade:   test.Grade,
+		Summary: summary,
+		Issues:  test.Issues,
+		Details: test,
+	}, nil
+}
+
+// ═══════════════════════════════════════════════════════════════════
+// DISQUES
+// ═══════════════════════════════════════════════════════════════════
+
+type diskDiagnostic struct{}
+
+func (diskDiagnostic) ID() string                       { return "disk" }
+func (diskDiagnostic) Description() string              { return "Taille, partitions et santé SMART des disques" }
+func (diskDiagnostic) Privileges() []Privilege          { return nil } // SMART facultatif sans root
+func (diskDiagnostic) EstimatedDuration() time.Duration { return 5 * time.Second }
+func (diskDiagnostic) Offline() bool                    { return true } // SMART ignoré en rejeu
+
+func (diskDiagnostic) Run(ctx context.Context, progress ProgressFunc) (Result, error) {
+	tests, err := disk.RunAllDiskTests()
+	if err != nil {
+		return Result{}, err
+	}
+	if len(tests) == 0 {
+		return Result{}, fmt.Errorf("aucun disque détecté")
+	}
+
+	result := Result{Details: tests}
+	for i, t := range tests {
+		if i == 0 {
+			result.Grade = t.Grade
+		} else {
+			result.Grade = common.WorseGrade(result.Grade, t.Grade)
+		}
+		for _, issue := range t.Issues {
+			result.Issues = append(result.Issues, t.DiskName+": "+issue)
+		}
+		progress(Progress{Fraction: float64(i+1) / float64(len(tests)), Message: t.DiskName})
+	}
+	result.Summary = fmt.Sprintf("%d disque(s)", len(tests))
+	return result, nil
+}
+
+type diskBenchDiagnostic struct{}
+
+func (diskBenchDiagnostic) ID() string { return "disk-bench" }
+func (diskBenchDiagnostic) Description() string {
+	return "Débits séquentiels et IOPS 4K des disques (écriture si $" + disk.EnvBenchWrite + ")"
+}
+func (diskBenchDiagnostic) Privileges() []Privilege          { return []Privilege{PrivilegeRoot} } // /dev en O_DIRECT
+func (diskBenchDiagnostic) EstimatedDuration() time.Duration { return time.Minute }
+
+// Run mesure chaque disque et reporte le benchmark sur sa note. Un disque
+// illisible (lecteur de cartes vide) est signalé sans arrêter les autres.
+func (diskBenchDiagnostic) Run(ctx context.Context, progress ProgressFunc) (Result, error) {
+	disks, err := probe.ListDisks()
+	if err != nil {
+		return Result{}, err
+	}
+	if len(disks) == 0 {
+		return Result{}, fmt.Errorf("aucun disque détecté")
+	}
+
+	var tests []disk.DiskHealthTest
+	var parts []string
+	result := Result{}
+	for i, name := range disks {
+		test, err := disk.RunDiskTest(name)
+		if err != nil {
+			result.Issues = append(result.Issues, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+
+		opts := disk.BenchmarkOptionsFromEnv(name)
+		opts.OnProgress = func(phase string, fraction float64) {
+			progress(Progress{
+				Fraction: (float64(i) + fraction) / float64(len(disks)),
+				Message:  name + " " + phase,
+			})
+		}
+		bench, err := disk.RunBenchmark(ctx, name, opts)
+		if ctx.Err() != nil {
+			return Result{}, ctx.Err()
+		}
+		if err != nil {
+			result.Issues = append(result.Issues, fmt.Sprintf("%s: benchmark impossible (%v)", name, err))
+			parts = append(parts, name+" non mesuré")
+			continue
+		}
+		disk.ApplyBenchmark(&test, bench)
+		tests = append(tests, test)
+
+		if len(tests) == 1 {
+			result.Grade = test.Grade
+		} else {
+			result.Grade = common.WorseGrade(result.Grade, test.Grade)
+		}
+		for _, issue := range test.Issues {
+			result.Issues = append(result.Issues, name+": "+issue)
+		}
+		parts = append(parts, fmt.Sprintf("%s %.0f MB/s %.0f IOPS", name, bench.SeqReadMBps, bench.RandReadIOPS))
+	}
+	if len(tests) == 0 {
+		return Result{}, fmt.Errorf("aucun disque mesuré : %s", strings.Join(result.Issues, " ; "))
+	}
+	result.Summary = strings.Join(parts, ", ")
+	result.Details = tests
+	return result, nil
+}
+
+type diskSurfaceDiagnostic struct{}
+
+func (diskSurfaceDiagnostic) ID() string { return "disk-surface" }
+func (diskSurfaceDiagnostic) Description() string {
+	return "Scan de surface complet des disques (lecture seule)"
+}
+func (diskSurfaceDiagnostic) Privileges() []Privilege { return []Privilege{PrivilegeRoot} } // /dev en O_DIRECT
+func (diskSurfaceDiagnostic) OptIn() bool             { return true }                       // plusieurs heures sur un HDD
+
+// EstimatedDuration suit la taille cumulée des disques : une heure fixe
+// couperait un HDD de 2 To bien avant la fin.
+func (diskSurfaceDiagnostic) EstimatedDuration() time.Duration {
+	disks, _ := probe.ListDisks()
+	var total int64
+	for _, name := range disks {
+		if info, err := probe.GetDiskInfo(name); err == nil {
+			total += info.SizeBytes
+		}
+	}
+	return max(disk.EstimateSurfaceDuration(total), time.Minute)
+}
+
+// Run lit la surface de chaque disque et reporte le scan sur sa note. Un
+// disque illisible est signalé sans arrêter les autres. Interrompu ou hors
+// délai, le scan en cours est enregistré dans disk.SurfaceStatePath et
+// repris au lancement suivant (ou par "gobox surface").
+func (diskSurfaceDiagnostic) Run(ctx context.Context, progress ProgressFunc) (Result, error) {
+	disks, err := probe.ListDisks()
+	if err != nil {
+		return Result{}, err
+	}
+	if len(disks) == 0 {
+		return Result{}, fmt.Errorf("aucun disque détecté")
+	}
+
+	var tests []disk.DiskHealthTest
+	var parts []string
+	result := Result{}
+	for i, name := range disks {
+		test, err := disk.RunDiskTest(name)
+		if err != nil {
+			result.Issues = append(result.Issues, fmt.Sprintf("%s: %v", name, err))
+			continue
+		}
+
+		statePath := disk.SurfaceStatePath(name)
+		resume, err := disk.LoadSurfaceState(statePath)
+		if err != nil {
+			result.Issues = append(result.Issues, fmt.Sprintf("%s: %v", name, err))
+		}
+
+		scan, err := disk.RunSurfaceScan(ctx, name, disk.SurfaceScanOptions{
+			Resume: resume,
+			OnProgress: func(p disk.SurfaceScanProgress) {
+				fraction := 0.0
+				if p.BytesTotal > 0 {
+					fraction = float64(p.BytesDone) / float64(p.BytesTotal)
+				}
+				progress(Progress{
+					Fraction: (float64(i) + fraction) / float64(len(disks)),
+					Message:  fmt.Sprintf("%s %.0f MB/s", name, p.BytesPerSec/1e6),
+				})
+			},
+		})
+		if ctx.Err() != nil {
+			if scan.SizeBytes > 0 {
+				if saveErr := disk.SaveSurfaceState(statePath, scan); saveErr != nil {
+					return Result{}, errors.Join(ctx.Err(), saveErr)
+				}
+			}
+			return Result{}, ctx.Err()
+		}
+		if err != nil {
+			result.Issues = append(result.Issues, fmt.Sprintf("%s: scan impossible (%v)", name, err))
+			parts = append(parts, name+" non scanné")
+			continue
+		}
+		os.Remove(statePath)
+		disk.ApplySurfaceScan(&test, scan)
+		tests = append(tests, test)
+
+		if len(tests) == 1 {
+			result.Grade = test.Grade
+		} else {
+			result.Grade = common.WorseGrade(result.Grade, test.Grade)
+		}
+		for _, issue := range test.Issues {
+			result.Issues = append(result.Issues, name+": "+issue)
+		}
+		parts = append(parts, fmt.Sprintf("%s %d lente(s), %d illisible(s)", name, scan.Slow+scan.VerySlow, scan.Unreadable))
+	}
+	if len(tests) == 0 {
+		return Result{}, fmt.Errorf("aucun disque scanné : %s", strings.Join(result.Issues, " ; "))
+	}
+	result.Summary = strings.Join(parts, ", ")
+	result.Details = tests
+	return result, nil
+}
+
+// ═══════════════════════════════════════════════════════════════════
+// CPU
+// ═══════════════════════════════════════════════════════════════════
+
+type cpuDiagnostic struct{}
+
+func (cpuDiagnostic) ID() string { return "cpu" }
+func (cpuDiagnostic) Description() string {
+	return "Charge CPU vérifiée, fréquences et températures"
+}
+func (cpuDiagnostic) Privileges() []Privilege { return nil }
+func (cpuDiagnostic) EstimatedDuration() time.Duration {
+	return cpu.DefaultCPUStressOptions().Duration
+}
+
+func (cpuDiagnostic) Run(ctx context.Context, progress ProgressFunc) (Result, error) {
+	opts := cpu.DefaultCPUStressOptions()
+	test, err := cpu.RunCPUStressTest(ctx, opts, func(elapsed time.Duration, s cpu.CPUSample) {
+		progress(Progress{
+			Fraction: min(elapsed.Seconds()/opts.Duration.Seconds(), 1),
+			Message:  fmt.Sprintf("%.0f MHz, %.0f °C", s.AvgFreqMHz, s.MaxTempC),
+		})
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{
+		Grade: test.Grade,
+		Summary: fmt.Sprintf("%d workers, %d itérations, %d erreur(s), %.0f °C max",
+			test.Workers, test.Iterations, test.ComputeErrors, test.MaxTempC),
+		Issues:  test.Issues,
+		Details: test,
+	}, nil
+}
+
+// ═══════════════════════════════════════════════════════════════════
+// MÉMOIRE
+// ═══════════════════════════════════════════════════════════════════
+
+type ramDiagnostic struct{}
+
+func (ramDiagnostic) ID() string                       { return "ram" }
+func (ramDiagnostic) Description() string              { return "Test de motifs sur la mémoire libre (memtester)" }
+func (ramDiagnostic) Privileges() []Privilege          { return []Privilege{PrivilegeRoot} } // mlock
+func (ramDiagnostic) EstimatedDuration() time.Duration { return 15 * time.Minute }
+func (ramDiagnostic) OptIn() bool                      { return true }
+
+func (ramDiagnostic) Run(ctx context.Context, progress ProgressFunc) (Result, error) {
+	test, err := ram.RunRAMTest(ctx, ram.DefaultRAMTestOptions(), func(fraction float64, pattern string) {
+		progress(Progress{Fraction: fraction, Message: pattern})
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{
+		Grade:   test.Grade,
+		Summary: fmt.Sprintf("%d MB testés, %d erreur(s)", test.TestedMB, test.ErrorCount),
+		Issues:  test.Issues,
+		Details: test,
+	}, nil
+}
+
+// ═══════════════════════════════════════════════════════════════════
+// RÉSEAU
+// ═══════════════════════════════════════════════════════════════════
+
+type networkDiagnostic struct{}
+
+func (networkDiagnostic) ID() string { return "network" }
+func (networkDiagnostic) Description() string {
+	return "Lien, passerelle, DNS et débit vers gobox netserver ($" + network.EnvServer + ")"
+}
+func (networkDiagnostic) Privileges() []Privilege { return nil } // scan Wi-Fi en cache sans root
+
+// EstimatedDuration dépend des interfaces présentes et de la configuration
+// du banc : chaque interface ajoute son scan Wi-Fi, son test de câble et
+// son test de débit.
+func (networkDiagnostic) EstimatedDuration() time.Duration {
+	ifaces, _ := probe.ListNetworkInterfaces()
+	return network.EstimateDuration(network.ConnectivityOptionsFromEnv(), ifaces)
+}
+
+func (networkDiagnostic) Run(ctx context.Context, progress ProgressFunc) (Result, error) {
+	test, err := network.RunConnectivityTest(ctx, network.ConnectivityOptionsFromEnv(), func(fraction float64, message string) {
+		progress(Progress{Fraction: fraction, Message: message})
+	})
+	if err != nil {
+		return Result{}, err
+	}
+
+	var parts []string
+	for _, c := range test.Interfaces {
+		switch {
+		case !c.Tested && c.WifiScanned:
+			parts = append(parts, fmt.Sprintf("%s %d réseau(x) visible(s)", c.Name, c.WifiNetworks))
+		case !c.Tested:
+			parts = append(parts, c.Name+" sans lien")
+		case c.ThroughputTested:
+			parts = append(parts, fmt.Sprintf("%s %d Mb/s (↓%.0f ↑%.0f)", c.Name, c.SpeedMbps, c.DownloadMbps, c.UploadMbps))
+		default:
+			parts = append(parts, fmt.Sprintf("%s %d Mb/s", c.Name, c.SpeedMbps))
+		}
+	}
+	return Result{
+		Grade:   test.Grade,
+		Summary: strings.Join(parts, ", "),
+		Issues:  test.Issues,
+		Details: test,
+	}, nil
+}
+
+// ═══════════════════════════════════════════════════════════════════
+// BLUETOOTH
+// ═══════════════════════════════════════════════════════════════════
+
+type bluetoothDiagnostic struct{}
+
+func (bluetoothDiagnostic) ID() string { return "bluetooth" }
+func (bluetoothDiagnostic) Description() string {
+	return "Découverte Bluetooth : la radio reçoit-elle ?"
+}
+func (bluetoothDiagnostic) Privileges() []Privilege { return []Privilege{PrivilegeRoot} } // API mgmt : CAP_NET_ADMIN
+func (bluetoothDiagnostic) EstimatedDuration() time.Duration {
+	return bluetooth.DefaultScanOptions().Duration + time.Second
+}
+
+func (bluetoothDiagnostic) Run(ctx context.Context, progress ProgressFunc) (Result, error) {
+	test, err := bluetooth.RunBluetoothScan(ctx, bluetooth.DefaultScanOptions(), func(fraction float64, message string) {
+		progress(Progress{Fraction: fraction, Message: message})
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	if len(test.Adapters) == 0 {
+		return Result{Summary: "aucun adaptateur", Details: test}, nil
+	}
+
+	var parts []string
+	for _, c := range test.Adapters {
+		if c.Scanned {
+			parts = append(parts, fmt.Sprintf("%s %d appareil(s)", c.Adapter.Name, len(c.Devices)))
+		} else {
+			parts = append(parts, c.Adapter.Name+" non testé")
+		}
+	}
+	return Result{
+		Grade:   test.Grade,
+		Summary: strings.Join(parts, ", "),
+		Issues:  test.Issues,
+		Details: test,
+	}, nil
+}
@@ -31,3 +31,10 @@ func WorseGrade(g1, g2 Grade) Grade {
 	}
 	return g2
 }
+
+func BetterGrade(g1, g2 Grade) Grade {
+	if g1.ToScore() > g2.ToScore() {
+		return g1
+	}
+	return g2
+}
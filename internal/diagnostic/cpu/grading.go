@@ -0,0 +1,97 @@
+package cpu
+
+import (
+	"fmt"
+
+	"gobox/internal/diagnostic/common"
+)
+
+func DefaultCPUStressGradingCriteria() CPUStressGradingCriteria {
+	return CPUStressGradingCriteria{
+		MaxTempForA:      85.0,  // <= 85 °C = A
+		MaxTempForB:      95.0,  // <= 95 °C = B
+		MaxTempForC:      100.0, // <= 100 °C = C
+		MinFreqRatioForA: 0.85,  // >= 85 % de la fréquence de référence = A
+		MinFreqRatioForB: 0.65,  // >= 65 % = B
+		MinFreqRatioForC: 0.45,  // >= 45 % = C
+		ThrottleMaxGrade: common.GradeB,
+		CollapseMaxGrade: common.GradeC,
+		CollapseRatio:    0.6, // dernier tiers < 60 % du premier = effondrement
+	}
+}
+
+// ComputeGrade note le test de charge. Une seule erreur de calcul vaut F :
+// un CPU qui calcule faux n'est pas revendable, quelle que soit sa vitesse.
+func ComputeGrade(criteria CPUStressGradingCriteria, result CPUStressResult) common.Grade {
+	if result.ComputeErrors > 0 {
+		return common.GradeF
+	}
+
+	grade := common.GradeA
+	if result.MaxTempC > 0 {
+		grade = common.WorseGrade(grade, gradeFromTemp(criteria, result.MaxTempC))
+	}
+	if result.FreqRatio > 0 {
+		grade = common.WorseGrade(grade, gradeFromFreqRatio(criteria, result.FreqRatio))
+	}
+	if result.ThrottleEvents > 0 {
+		grade = common.WorseGrade(grade, criteria.ThrottleMaxGrade)
+	}
+	if result.FrequencyCollapse {
+		grade = common.WorseGrade(grade, criteria.CollapseMaxGrade)
+	}
+	return grade
+}
+
+func gradeFromTemp(criteria CPUStressGradingCriteria, tempC float64) common.Grade {
+	switch {
+	case tempC <= criteria.MaxTempForA:
+		return common.GradeA
+	case tempC <= criteria.MaxTempForB:
+		return common.GradeB
+	case tempC <= criteria.MaxTempForC:
+		return common.GradeC
+	default:
+		return common.GradeF
+	}
+}
+
+func gradeFromFreqRatio(criteria CPUStressGradingCriteria, ratio float64) common.Grade {
+	switch {
+	case ratio >= criteria.MinFreqRatioForA:
+		return common.GradeA
+	case ratio >= criteria.MinFreqRatioForB:
+		return common.GradeB
+	case ratio >= criteria.MinFreqRatioForC:
+		return common.GradeC
+	default:
+		return common.GradeF
+	}
+}
+
+func DetectIssues(result CPUStressResult, criteria CPUStressGradingCriteria) []string {
+	issues := []string{}
+
+	if result.ComputeErrors > 0 {
+		issues = append(issues, fmt.Sprintf("%d erreur(s) de calcul sous charge", result.ComputeErrors))
+		issues = append(issues, result.ErrorDetails...)
+	}
+	if result.MaxTempC > criteria.MaxTempForA {
+		issues = append(issues, fmt.Sprintf("Température élevée sous charge : %.0f °C", result.MaxTempC))
+	}
+	if result.ThrottleEvents > 0 {
+		issues = append(issues, fmt.Sprintf("Bridage thermique détecté (%d évènements)", result.ThrottleEvents))
+	}
+	if result.FrequencyCollapse {
+		issues = append(issues, "Effondrement de fréquence en fin de test (refroidissement insuffisant ?)")
+	}
+	if result.FreqRatio > 0 && result.FreqRatio < criteria.MinFreqRatioForA {
+		issues = append(issues, fmt.Sprintf("Fréquence moyenne sous charge %.0f MHz (%.0f %% de %.0f MHz)",
+			result.AvgFreqMHz, result.FreqRatio*100, result.RefFreqMHz))
+	}
+	if result.MaxTempC == 0 {
+		issues = append(issues, "Aucune sonde thermique lisible")
+	}
+
+	return issues
+}
@@ -0,0 +1,57 @@
+package cpu
+
+import (
+	"time"
+
+	"gobox/internal/diagnostic/common"
+)
+
+// CPUStressOptions paramètre le test de charge
+type CPUStressOptions struct {
+	Duration       time.Duration // durée de la charge, ex: 60 s
+	Workers        int           // 0 = un worker par CPU logique
+	SampleInterval time.Duration // période d'échantillonnage fréquence/température
+}
+
+// CPUSample est un point de la série temporelle relevée pendant la charge
+type CPUSample struct {
+	Elapsed    time.Duration
+	AvgFreqMHz float64 // moyenne des scaling_cur_freq, 0 si cpufreq absent
+	MinFreqMHz float64 // cœur le plus lent
+	MaxTempC   float64 // zone thermique la plus chaude, 0 si inconnue
+}
+
+// CPUStressResult résultat du test de charge CPU
+type CPUStressResult struct {
+	Workers           int
+	Duration          time.Duration
+	Iterations        int64    // itérations de calcul vérifiées
+	ComputeErrors     int64    // résultats différents de la référence
+	ErrorDetails      []string // premiers écarts (worker, charge)
+	RefFreqMHz        float64  // fréquence de base, 0 si inconnue
+	AvgFreqMHz        float64  // moyenne sous charge, hors chauffe
+	MinFreqMHz        float64  // plus basse fréquence observée sous charge
+	FreqRatio         float64  // AvgFreqMHz / RefFreqMHz, 0 si inconnu
+	FrequencyCollapse bool     // chute de fréquence en fin de test
+	MaxTempC          float64
+	ThrottleEvents    int64 // core/package_throttle_count (Intel), delta sur le test
+	Samples           []CPUSample
+	Grade             common.Grade
+	Issues            []string
+	Timestamp         time.Time
+}
+
+// CPUStressGradingCriteria critères de notation du test de charge
+type CPUStressGradingCriteria struct {
+	MaxTempForA float64 // ex: 85 °C → Grade A
+	MaxTempForB float64 // ex: 95 °C → Grade B
+	MaxTempForC float64 // ex: 100 °C → Grade C (au-delà = F)
+
+	MinFreqRatioForA float64 // ex: 0.85 de la fréquence de référence → Grade A
+	MinFreqRatioForB float64 // ex: 0.65 → Grade B
+	MinFreqRatioForC float64 // ex: 0.45 → Grade C (en dessous = F)
+
+	ThrottleMaxGrade common.Grade // note plafond si le CPU a été bridé
+	CollapseMaxGrade common.Grade // note plafond sur effondrement de fréquence
+	CollapseRatio    float64      // fin de test < ratio × début = effondrement
+}
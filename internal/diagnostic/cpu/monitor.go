@@ -0,0 +1,140 @@
+package cpu
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gobox/internal/probe"
+	"gobox/internal/sysfs"
+)
+
+const (
+	pathCPURoot     = "/sys/devices/system/cpu"
+	pathThermalRoot = "/sys/class/thermal"
+)
+
+// thermalIgnored sont les zones sans rapport avec le CPU (Wi-Fi, batterie, SSD...).
+var thermalIgnored = []string{"iwlwifi", "bat", "nvme", "pch", "wifi", "charger"}
+
+// monitor relit les fréquences, les zones thermiques et les compteurs
+// de bridage pendant la charge.
+type monitor struct {
+	fs        sysfs.FS
+	freqFiles []string
+	tempFiles []string
+	throttles []string
+}
+
+func newMonitor() *monitor {
+	fs := probe.FS()
+	m := &monitor{fs: fs}
+
+	m.freqFiles, _ = fs.Glob(filepath.Join(pathCPURoot, "cpu[0-9]*", "cpufreq", "scaling_cur_freq"))
+
+	zones, _ := fs.Glob(filepath.Join(pathThermalRoot, "thermal_zone*"))
+	for _, zone := range zones {
+		kind, err := fs.ReadFile(filepath.Join(zone, "type"))
+		if err != nil || isIgnoredZone(kind) {
+			continue
+		}
+		m.tempFiles = append(m.tempFiles, filepath.Join(zone, "temp"))
+	}
+
+	m.throttles, _ = fs.Glob(filepath.Join(pathCPURoot, "cpu[0-9]*", "thermal_throttle", "core_throttle_count"))
+	pkg := filepath.Join(pathCPURoot, "cpu0", "thermal_throttle", "package_throttle_count")
+	if _, err := fs.Stat(pkg); err == nil {
+		m.throttles = append(m.throttles, pkg)
+	}
+	return m
+}
+
+func isIgnoredZone(kind string) bool {
+	kind = strings.ToLower(kind)
+	for _, ignored := range thermalIgnored {
+		if strings.Contains(kind, ignored) {
+			return true
+		}
+	}
+	return false
+}
+
+// refFreqMHz est la fréquence de base : base_frequency (intel_pstate),
+// nominal_freq ACPI CPPC (amd-pstate, acpi-cpufreq) puis le « @ 1.70GHz »
+// du nom de modèle. cpuinfo_max_freq est la fréquence turbo : la charge
+// tous cœurs passerait pour du bridage. 0 si inconnue, la fréquence n'est
+// alors pas notée.
+func (m *monitor) refFreqMHz() float64 {
+	cpu0 := filepath.Join(pathCPURoot, "cpu0")
+	if khz, err := m.fs.ReadFloat(filepath.Join(cpu0, "cpufreq", "base_frequency")); err == nil && khz > 0 {
+		return khz / 1000
+	}
+	if mhz, err := m.fs.ReadFloat(filepath.Join(cpu0, "acpi_cppc", "nominal_freq")); err == nil && mhz > 0 {
+		return mhz
+	}
+	if info, err := probe.GetCPUInfo(); err == nil {
+		return modelNameFreqMHz(info.ModelName)
+	}
+	return 0
+}
+
+// modelNameFreqMHz extrait la fréquence nominale d'un nom de modèle Intel,
+// ex: "Intel(R) Core(TM) i5-8350U CPU @ 1.70GHz" → 1700. 0 si absente.
+func modelNameFreqMHz(model string) float64 {
+	_, freq, found := strings.Cut(model, "@")
+	if !found {
+		return 0
+	}
+	freq = strings.TrimSpace(freq)
+	value, found := strings.CutSuffix(freq, "GHz")
+	if !found {
+		return 0
+	}
+	ghz, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || ghz <= 0 {
+		return 0
+	}
+	return ghz * 1000
+}
+
+// sample relève un point : fréquence moyenne et minimale, zone la plus chaude.
+func (m *monitor) sample() CPUSample {
+	var s CPUSample
+	var sum float64
+	var n int
+	for _, path := range m.freqFiles {
+		khz, err := m.fs.ReadFloat(path)
+		if err != nil || khz <= 0 {
+			continue
+		}
+		mhz := khz / 1000
+		sum += mhz
+		n++
+		if s.MinFreqMHz == 0 || mhz < s.MinFreqMHz {
+			s.MinFreqMHz = mhz
+		}
+	}
+	if n > 0 {
+		s.AvgFreqMHz = sum / float64(n)
+	}
+
+	for _, path := range m.tempFiles {
+		milli, err := m.fs.ReadFloat(path)
+		if err != nil || milli <= 0 {
+			continue
+		}
+		s.MaxTempC = max(s.MaxTempC, milli/1000)
+	}
+	return s
+}
+
+// throttleCount additionne les compteurs de bridage thermique.
+func (m *monitor) throttleCount() int64 {
+	var total int64
+	for _, path := range m.throttles {
+		if v, err := m.fs.ReadInt(path); err == nil {
+			total += int64(v)
+		}
+	}
+	return total
+}
@@ -0,0 +1,279 @@
+package cpu
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"gobox/internal/probe"
+)
+
+const (
+	matrixSize     = 64
+	hashBlockSize  = 64 << 10
+	hashRounds     = 8
+	fpTerms        = 20_000
+	maxErrorDetail = 10
+)
+
+// DefaultCPUStressOptions : une minute sur tous les CPU logiques.
+func DefaultCPUStressOptions() CPUStressOptions {
+	return CPUStressOptions{
+		Duration:       60 * time.Second,
+		SampleInterval: time.Second,
+	}
+}
+
+// workload est un calcul déterministe dont le résultat est comparé à une
+// référence calculée avant la charge : un écart trahit une erreur matérielle
+// (cœur instable, tension insuffisante, surchauffe).
+type workload struct {
+	name string
+	run  func() uint64
+}
+
+var workloads = []workload{
+	{"matrix", matrixWorkload},
+	{"sha256", hashWorkload},
+	{"float", floatWorkload},
+}
+
+// matrixWorkload multiplie deux matrices 64×64 de flottants et condense le produit.
+func matrixWorkload() uint64 {
+	var a, b, c [matrixSize][matrixSize]float64
+	for i := range matrixSize {
+		for j := range matrixSize {
+			a[i][j] = float64((i*31+j*17)%97) / 7
+			b[i][j] = float64((i*13+j*29)%89) / 11
+		}
+	}
+	for i := range matrixSize {
+		for k := range matrixSize {
+			aik := a[i][k]
+			for j := range matrixSize {
+				c[i][j] += aik * b[k][j]
+			}
+		}
+	}
+
+	var sum uint64
+	for i := range matrixSize {
+		for j := range matrixSize {
+			sum = sum*31 + math.Float64bits(c[i][j])
+		}
+	}
+	return sum
+}
+
+// hashWorkload enchaîne des SHA-256 sur un bloc de 64 KiB.
+func hashWorkload() uint64 {
+	block := make([]byte, hashBlockSize)
+	for i := range block {
+		block[i] = byte(i * 7)
+	}
+	var digest [sha256.Size]byte
+	for range hashRounds {
+		copy(block, digest[:])
+		digest = sha256.Sum256(block)
+	}
+	return binary.LittleEndian.Uint64(digest[:8])
+}
+
+// floatWorkload sollicite l'unité flottante (racines, trigonométrie, divisions).
+func floatWorkload() uint64 {
+	acc := 0.0
+	for i := 1; i <= fpTerms; i++ {
+		x := float64(i)
+		acc += math.Sqrt(x)*math.Sin(x/3) + 1/(x*x+1)
+	}
+	return math.Float64bits(acc)
+}
+
+// RunCPUStressTest charge tous les CPU logiques pendant opts.Duration en
+// vérifiant chaque calcul, et relève fréquences et températures.
+func RunCPUStressTest(ctx context.Context, opts CPUStressOptions, onProgress func(elapsed time.Duration, sample CPUSample)) (CPUStressResult, error) {
+	if !probe.FS().IsHost() {
+		return CPUStressResult{}, errors.New("test de charge impossible en mode rejeu")
+	}
+	if opts.Duration <= 0 {
+		opts.Duration = DefaultCPUStressOptions().Duration
+	}
+	if opts.SampleInterval <= 0 {
+		opts.SampleInterval = DefaultCPUStressOptions().SampleInterval
+	}
+	cpus := allowedCPUs()
+	if opts.Workers <= 0 {
+		opts.Workers = len(cpus)
+	}
+	if onProgress == nil {
+		onProgress = func(time.Duration, CPUSample) {}
+	}
+
+	// 1. Références calculées avant la charge, CPU froid
+	refs := make([]uint64, len(workloads))
+	for i, w := range workloads {
+		refs[i] = w.run()
+	}
+
+	mon := newMonitor()
+	result := CPUStressResult{
+		Workers:    opts.Workers,
+		RefFreqMHz: mon.refFreqMHz(),
+		Timestamp:  time.Now(),
+	}
+	throttleStart := mon.throttleCount()
+
+	// 2. Charge : un worker épinglé par CPU logique
+	loadCtx, stop := context.WithTimeout(ctx, opts.Duration)
+	defer stop()
+
+	var (
+		iterations atomic.Int64
+		errorCount atomic.Int64
+		detailsMu  sync.Mutex
+		wg         sync.WaitGroup
+	)
+	for worker := range opts.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Pas d'UnlockOSThread : le thread épinglé meurt avec le worker
+			// au lieu de retourner, restreint à un CPU, dans le pool du runtime.
+			runtime.LockOSThread()
+			pinToCPU(cpus[worker%len(cpus)])
+
+			for loadCtx.Err() == nil {
+				for i, w := range workloads {
+					if got := w.run(); got != refs[i] {
+						if errorCount.Add(1) <= maxErrorDetail {
+							detailsMu.Lock()
+							result.ErrorDetails = append(result.ErrorDetails,
+								fmt.Sprintf("worker %d (%s) : %#x au lieu de %#x", worker, w.name, got, refs[i]))
+							detailsMu.Unlock()
+						}
+					}
+					iterations.Add(1)
+				}
+			}
+		}()
+	}
+
+	// 3. Échantillonnage jusqu'à la fin de la charge
+	start := time.Now()
+	ticker := time.NewTicker(opts.SampleInterval)
+	for sampling := true; sampling; {
+		select {
+		case <-loadCtx.Done():
+			sampling = false
+		case <-ticker.C:
+			s := mon.sample()
+			s.Elapsed = time.Since(start)
+			result.Samples = append(result.Samples, s)
+			onProgress(s.Elapsed, s)
+		}
+	}
+	ticker.Stop()
+	wg.Wait()
+
+	result.Duration = time.Since(start)
+	result.Iterations = iterations.Load()
+	result.ComputeErrors = errorCount.Load()
+	result.ThrottleEvents = mon.throttleCount() - throttleStart
+
+	// Interruption par l'appelant (et non fin normale de la durée)
+	if ctx.Err() != nil {
+		return result, ctx.Err()
+	}
+
+	// 4. Analyse de la série et notation
+	criteria := DefaultCPUStressGradingCriteria()
+	analyzeSamples(&result, criteria)
+	result.Grade = ComputeGrade(criteria, result)
+	result.Issues = DetectIssues(result, criteria)
+
+	return result, nil
+}
+
+// allowedCPUs liste les CPU autorisés au processus. Les numéros ne sont pas
+// forcément contigus (CPU hors ligne, cpuset, taskset).
+func allowedCPUs() []int {
+	var set unix.CPUSet
+	if err := unix.SchedGetaffinity(0, &set); err != nil {
+		cpus := make([]int, runtime.NumCPU())
+		for i := range cpus {
+			cpus[i] = i
+		}
+		return cpus
+	}
+	cpus := make([]int, 0, set.Count())
+	for cpu := 0; len(cpus) < set.Count(); cpu++ {
+		if set.IsSet(cpu) {
+			cpus = append(cpus, cpu)
+		}
+	}
+	return cpus
+}
+
+// pinToCPU restreint le thread courant à un CPU (best effort).
+func pinToCPU(cpu int) {
+	var set unix.CPUSet
+	set.Set(cpu)
+	unix.SchedSetaffinity(0, &set)
+}
+
+// analyzeSamples calcule moyenne, minimum et effondrement de fréquence en
+// ignorant la chauffe (premier dixième du test, au moins un échantillon).
+func analyzeSamples(result *CPUStressResult, criteria CPUStressGradingCriteria) {
+	for _, s := range result.Samples {
+		result.MaxTempC = max(result.MaxTempC, s.MaxTempC)
+	}
+
+	warmup := max(len(result.Samples)/10, 1)
+	if len(result.Samples) <= warmup {
+		return
+	}
+	loaded := result.Samples[warmup:]
+
+	var sum float64
+	var n int
+	for _, s := range loaded {
+		if s.AvgFreqMHz <= 0 {
+			continue
+		}
+		sum += s.AvgFreqMHz
+		n++
+		if result.MinFreqMHz == 0 || s.MinFreqMHz < result.MinFreqMHz {
+			result.MinFreqMHz = s.MinFreqMHz
+		}
+	}
+	if n == 0 {
+		return // cpufreq absent (VM)
+	}
+	result.AvgFreqMHz = sum / float64(n)
+	if result.RefFreqMHz > 0 {
+		result.FreqRatio = result.AvgFreqMHz / result.RefFreqMHz
+	}
+
+	if third := len(loaded) / 3; third > 0 {
+		first := meanFreq(loaded[:third])
+		last := meanFreq(loaded[len(loaded)-third:])
+		result.FrequencyCollapse = first > 0 && last < criteria.CollapseRatio*first
+	}
+}
+
+func meanFreq(samples []CPUSample) float64 {
+	var sum float64
+	for _, s := range samples {
+		sum += s.AvgFreqMHz
+	}
+	return sum / float64(len(samples))
+}
@@ -1 +1,300 @@
 package disk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"gobox/internal/blockdev"
+	"gobox/internal/diagnostic/common"
+	"gobox/internal/diagnostic/disk/operations"
+	"gobox/internal/probe"
+)
+
+const (
+	benchAlignment  = 4096 // alignement O_DIRECT, compatible secteurs 512 et 4K
+	randomBlockSize = 4096
+	benchTick       = 500 * time.Millisecond
+)
+
+// DefaultBenchmarkOptions : 1 GiB séquentiel (15 s max), 10 s de 4K aléatoire
+// sur 4 lectures simultanées, sans écriture.
+func DefaultBenchmarkOptions() BenchmarkOptions {
+	return BenchmarkOptions{
+		SeqBytes:       1 << 30,
+		SeqMaxDuration: 15 * time.Second,
+		BlockSize:      1 << 20,
+		RandomDuration: 10 * time.Second,
+		RandomWorkers:  4,
+	}
+}
+
+// EnvBenchWrite liste les disques sur lesquels le diagnostic « disk-bench »
+// peut écrire, ex: "sdb,sdc". Leurs données sont perdues.
+const EnvBenchWrite = "GOBOX_BENCH_WRITE"
+
+// BenchmarkOptionsFromEnv retourne les options par défaut, écriture
+// autorisée si diskName figure dans $GOBOX_BENCH_WRITE.
+func BenchmarkOptionsFromEnv(diskName string) BenchmarkOptions {
+	opts := DefaultBenchmarkOptions()
+	opts.AllowWrite = slices.Contains(strings.Split(os.Getenv(EnvBenchWrite), ","), diskName)
+	return opts
+}
+
+// RunBenchmark mesure les performances d'un disque en E/S directes (O_DIRECT,
+// cache noyau contourné) et les compare à l'attendu pour son type et son bus.
+//
+// La lecture est sans risque, même disque monté. L'écriture séquentielle
+// n'est faite qu'avec opts.AllowWrite, sur un disque démonté : elle écrase
+// le début du disque.
+//
+// Un SSD tout juste effacé par Format/Sanitize renvoie ses blocs non alloués
+// sans lire la flash : les lectures y sont alors optimistes.
+func RunBenchmark(ctx context.Context, diskName string, opts BenchmarkOptions) (BenchmarkResult, error) {
+	if !probe.FS().IsHost() {
+		return BenchmarkResult{}, errors.New("benchmark impossible en mode rejeu")
+	}
+	info, err := probe.GetDiskInfo(diskName)
+	if err != nil {
+		return BenchmarkResult{}, err
+	}
+
+	flag := os.O_RDONLY | unix.O_DIRECT
+	if opts.AllowWrite {
+		if err := operations.CheckNotInUse(diskName); err != nil {
+			return BenchmarkResult{}, err
+		}
+		flag = os.O_RDWR | os.O_EXCL | unix.O_DIRECT
+	}
+	dev, err := blockdev.Open(diskName, flag)
+	if err != nil {
+		return BenchmarkResult{}, err
+	}
+	defer dev.Close()
+
+	size, err := dev.Size()
+	if err != nil {
+		return BenchmarkResult{}, fmt.Errorf("taille %s: %w", diskName, err)
+	}
+
+	result := BenchmarkResult{
+		DiskName:  info.Name,
+		Type:      info.Type,
+		Bus:       info.Bus,
+		Expected:  ExpectedPerformance(info.Type, info.Bus),
+		Timestamp: time.Now(),
+	}
+	if err := benchmarkDevice(ctx, dev.File(), size, opts, &result); err != nil {
+		return result, err
+	}
+
+	criteria := DefaultBenchmarkGradingCriteria()
+	result.Grade = ComputeBenchmarkGrade(criteria, result)
+	result.Issues = DetectBenchmarkIssues(result, criteria)
+	return result, nil
+}
+
+// ApplyBenchmark rattache un benchmark au test disque : la note du disque
+// devient la pire des deux et les problèmes s'ajoutent.
+func ApplyBenchmark(test *DiskHealthTest, bench BenchmarkResult) {
+	test.Benchmark = &bench
+	test.Grade = common.WorseGrade(test.Grade, bench.Grade)
+	test.Issues = append(test.Issues, bench.Issues...)
+}
+
+// benchmarkDevice enchaîne les phases sur un fichier déjà ouvert en O_DIRECT.
+func benchmarkDevice(ctx context.Context, f *os.File, size int64, opts BenchmarkOptions, result *BenchmarkResult) error {
+	def := DefaultBenchmarkOptions()
+	if opts.SeqBytes <= 0 {
+		opts.SeqBytes = def.SeqBytes
+	}
+	if opts.SeqMaxDuration <= 0 {
+		opts.SeqMaxDuration = def.SeqMaxDuration
+	}
+	if opts.BlockSize <= 0 {
+		opts.BlockSize = def.BlockSize
+	}
+	if opts.RandomDuration <= 0 {
+		opts.RandomDuration = def.RandomDuration
+	}
+	if opts.RandomWorkers <= 0 {
+		opts.RandomWorkers = def.RandomWorkers
+	}
+	if opts.OnProgress == nil {
+		opts.OnProgress = func(string, float64) {}
+	}
+	opts.BlockSize = (opts.BlockSize + benchAlignment - 1) / benchAlignment * benchAlignment
+
+	// Volume séquentiel arrondi au bloc, borné par la taille du disque
+	seqBytes := min(opts.SeqBytes, size) / int64(opts.BlockSize) * int64(opts.BlockSize)
+	if seqBytes == 0 {
+		return fmt.Errorf("disque trop petit pour le benchmark (%d octets)", size)
+	}
+
+	mbps, err := sequential(ctx, f, seqBytes, false, opts)
+	if err != nil {
+		return fmt.Errorf("lecture séquentielle: %w", err)
+	}
+	result.SeqReadMBps = mbps
+
+	if opts.AllowWrite {
+		mbps, err := sequential(ctx, f, seqBytes, true, opts)
+		if err != nil {
+			return fmt.Errorf("écriture séquentielle: %w", err)
+		}
+		result.SeqWriteMBps = mbps
+		result.WriteTested = true
+	}
+
+	latencies, elapsed, err := randomRead(ctx, f, size, opts)
+	if err != nil {
+		return fmt.Errorf("lecture aléatoire: %w", err)
+	}
+	result.RandomWorkers = opts.RandomWorkers
+	result.RandReadIOPS = float64(len(latencies)) / elapsed.Seconds()
+	result.LatencyP50 = percentile(latencies, 0.50)
+	result.LatencyP95 = percentile(latencies, 0.95)
+	result.LatencyP99 = percentile(latencies, 0.99)
+	if len(latencies) > 0 {
+		result.LatencyMax = latencies[len(latencies)-1]
+	}
+	return nil
+}
+
+// sequential lit ou écrit total octets depuis l'offset 0 et retourne le
+// débit en MB/s. La phase s'arrête plus tôt si elle dépasse SeqMaxDuration
+// (disque très lent) : le débit porte alors sur ce qui a été transféré.
+func sequential(ctx context.Context, f *os.File, total int64, write bool, opts BenchmarkOptions) (float64, error) {
+	phase := "read"
+	buf := blockdev.AlignedBuffer(opts.BlockSize, benchAlignment)
+	if write {
+		// Données incompressibles : certains contrôleurs compressent les zéros
+		phase = "write"
+		rand.NewChaCha8([32]byte{}).Read(buf)
+	}
+
+	start := time.Now()
+	last := start
+	var done int64
+	for done < total {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		elapsed := time.Since(start)
+		if elapsed > opts.SeqMaxDuration {
+			break
+		}
+
+		var err error
+		if write {
+			_, err = f.WriteAt(buf, done)
+		} else {
+			_, err = f.ReadAt(buf, done)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("offset %d: %w", done, err)
+		}
+		done += int64(len(buf))
+
+		if time.Since(last) >= benchTick {
+			last = time.Now()
+			opts.OnProgress(phase, max(float64(done)/float64(total), elapsed.Seconds()/opts.SeqMaxDuration.Seconds()))
+		}
+	}
+	if write {
+		if err := f.Sync(); err != nil {
+			return 0, fmt.Errorf("fsync: %w", err)
+		}
+	}
+	opts.OnProgress(phase, 1)
+
+	return float64(done) / 1e6 / time.Since(start).Seconds(), nil
+}
+
+// randomRead lance RandomWorkers lecteurs 4K à des offsets aléatoires
+// alignés pendant RandomDuration et retourne les latences triées.
+func randomRead(ctx context.Context, f *os.File, size int64, opts BenchmarkOptions) ([]time.Duration, time.Duration, error) {
+	blocks := size / randomBlockSize
+	if blocks == 0 {
+		return nil, 0, fmt.Errorf("disque trop petit (%d octets)", size)
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, opts.RandomDuration)
+	defer cancel()
+
+	var (
+		mu        sync.Mutex
+		latencies []time.Duration
+		firstErr  error
+		wg        sync.WaitGroup
+	)
+	start := time.Now()
+	for range opts.RandomWorkers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			buf := blockdev.AlignedBuffer(randomBlockSize, benchAlignment)
+			rng := rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+			local := make([]time.Duration, 0, 4096)
+
+			var err error
+			for runCtx.Err() == nil {
+				off := rng.Int64N(blocks) * randomBlockSize
+				t := time.Now()
+				if _, err = f.ReadAt(buf, off); err != nil {
+					err = fmt.Errorf("offset %d: %w", off, err)
+					cancel() // inutile de poursuivre sur un disque en erreur
+					break
+				}
+				local = append(local, time.Since(t))
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			latencies = append(latencies, local...)
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(benchTick)
+	for waiting := true; waiting; {
+		select {
+		case <-runCtx.Done():
+			waiting = false
+		case <-ticker.C:
+			opts.OnProgress("random", min(time.Since(start).Seconds()/opts.RandomDuration.Seconds(), 1))
+		}
+	}
+	ticker.Stop()
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	if firstErr != nil {
+		return nil, 0, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+	opts.OnProgress("random", 1)
+
+	slices.Sort(latencies)
+	return latencies, elapsed, nil
+}
+
+// percentile lit le centile p (0..1) d'une série triée.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	return sorted[int(p*float64(len(sorted)-1))]
+}
@@ -1,44 +1,36 @@
 package disk
 
 import (
+	"fmt"
 	"time"
 
 	"gobox/internal/diagnostic/common"
+	"gobox/internal/probe"
 )
 
-// DiskHealthTest résultat du test disque
-type DiskHealthTest struct {
-	DiskName      string       // ex: "nvme0n1"
-	Vendor        string       // ex: "Samsung"
-	Model         string       // ex: "980 PRO"
-	Type          string       // "SSD" ou "HDD"
-	Grade         common.Grade // "A", "B", "C", "F"
-	SizeGB        float64      // Taille en GB
-	HasPartitions bool         // true si partitions détectées
-	PartitionList []string     // Liste des partitions
-	Issues        []string     // Problèmes détectés
-	Timestamp     time.Time
-}
-
-// DiskGradingCriteria critères de notation disque
-type DiskGradingCriteria struct {
-	// Taille minimale acceptable (en GB)
-	MinSizeForA float64 // ex: 200 GB → Grade A
-	MinSizeForB float64 // ex: 120 GB → Grade B
-	MinSizeForC float64 // ex: 60 GB → Grade C
-	// < 60 GB = automatiquement F
-
-	// Bonus SSD (si true, SSD ne peut pas être < B)
-	SSDMinGrade common.Grade // ex: GradeB (SSD jamais en C ou F sauf si autre problème)
-}
+const (
+	TypeSSD = "SSD"
+	TypeHDD = "HDD"
+)
 
 // DefaultDiskGradingCriteria retourne les critères par défaut
 func DefaultDiskGradingCriteria() DiskGradingCriteria {
-	// TODO: Retourne struct avec :
-	// MinSizeForA: 200.0
-	// MinSizeForB: 120.0
-	// MinSizeForC: 60.0
-	// SSDMinGrade: common.GradeB
+	return DiskGradingCriteria{
+		MinSizeForA: 200.0, // >= 200 GB = A
+		MinSizeForB: 120.0, // >= 120 GB = B
+		MinSizeForC: 60.0,  // >= 60 GB = C
+		SSDMinGrade: common.GradeB,
+
+		MaxPowerOnHoursForA:   10_000,
+		MaxPowerOnHoursForB:   20_000,
+		MaxPowerOnHoursForC:   35_000,
+		MaxPercentUsedForA:    10,
+		MaxPercentUsedForB:    30,
+		MaxPercentUsedForC:    80,
+		MaxReallocatedForC:    50,
+		MaxTemperatureC:       60,
+		MinAvailableSpareForC: 10,
+	}
 }
 
 // ComputeGrade calcule le grade du disque
@@ -48,21 +40,36 @@ func ComputeGrade(
 	sizeGB float64,
 	hasPartitions bool,
 ) common.Grade {
-	// TODO: Implémente la logique :
-	// 1. Si hasPartitions == true → return common.GradeF
-	// 2. Si sizeGB < criteria.MinSizeForC → return common.GradeF
-	// 3. Si diskType == "SSD" et sizeGB >= criteria.MinSizeForA → return common.GradeA
-	// 4. Sinon, utilise gradeFromSize(criteria, sizeGB)
-	// 5. Si diskType == "SSD", applique SSDMinGrade (ne jamais descendre sous B)
+	// Partitions résiduelles : disque non effacé, invendable en l'état
+	if hasPartitions {
+		return common.GradeF
+	}
+	if sizeGB < criteria.MinSizeForC {
+		return common.GradeF
+	}
+	if diskType == TypeSSD && sizeGB >= criteria.MinSizeForA {
+		return common.GradeA
+	}
+
+	grade := gradeFromSize(criteria, sizeGB)
+	if diskType == TypeSSD {
+		grade = common.BetterGrade(grade, criteria.SSDMinGrade)
+	}
+	return grade
 }
 
 // gradeFromSize calcule le grade selon la taille uniquement
 func gradeFromSize(criteria DiskGradingCriteria, sizeGB float64) common.Grade {
-	// TODO: Switch comme gradeFromHealth dans Battery
-	// >= MinSizeForA → A
-	// >= MinSizeForB → B
-	// >= MinSizeForC → C
-	// Sinon → F
+	switch {
+	case sizeGB >= criteria.MinSizeForA:
+		return common.GradeA
+	case sizeGB >= criteria.MinSizeForB:
+		return common.GradeB
+	case sizeGB >= criteria.MinSizeForC:
+		return common.GradeC
+	default:
+		return common.GradeF
+	}
 }
 
 // DetectIssues détecte les problèmes du disque
@@ -73,8 +80,294 @@ func DetectIssues(
 	partitionList []string,
 	criteria DiskGradingCriteria,
 ) []string {
-	// TODO: Retourne []string{} et ajoute des issues si :
-	// - hasPartitions == true → "Partitions résiduelles détectées: [...]"
-	// - sizeGB < criteria.MinSizeForC → "Disque trop petit (< 60 GB)"
-	// - sizeGB < criteria.MinSizeForB → "Capacité limitée (< 120 GB)"
+	issues := []string{}
+
+	if hasPartitions {
+		issues = append(issues, fmt.Sprintf("Partitions résiduelles détectées: %v", partitionList))
+	}
+
+	if sizeGB < criteria.MinSizeForC {
+		issues = append(issues, fmt.Sprintf("Disque trop petit (< %.0f GB)", criteria.MinSizeForC))
+	} else if sizeGB < criteria.MinSizeForB {
+		issues = append(issues, fmt.Sprintf("Capacité limitée (< %.0f GB)", criteria.MinSizeForB))
+	}
+
+	if diskType != TypeSSD && diskType != TypeHDD {
+		issues = append(issues, fmt.Sprintf("Type de disque inconnu: %q", diskType))
+	}
+
+	return issues
+}
+
+// ComputeHealthGrade calcule le grade à partir des données firmware.
+// Un disque sans données SMART n'est pas pénalisé (GradeA).
+func ComputeHealthGrade(criteria DiskGradingCriteria, health *probe.DiskHealth) common.Grade {
+	if health == nil {
+		return common.GradeA
+	}
+
+	// Défaillances franches : secteurs instables, alerte critique NVMe
+	if health.PendingSectors > 0 || health.CriticalWarning != 0 ||
+		health.ReallocatedSectors > criteria.MaxReallocatedForC {
+		return common.GradeF
+	}
+
+	grade := common.WorseGrade(
+		gradeFromHours(criteria, health.PowerOnHours),
+		gradeFromWear(criteria, health.PercentageUsed),
+	)
+
+	if health.ReallocatedSectors > 0 || health.MediaErrors > 0 || health.Uncorrectable > 0 {
+		grade = common.WorseGrade(grade, common.GradeC)
+	}
+	if health.Source == probe.HealthSourceNVMe && health.AvailableSpare < criteria.MinAvailableSpareForC {
+		grade = common.GradeF
+	}
+
+	return grade
+}
+
+func gradeFromHours(criteria DiskGradingCriteria, hours int64) common.Grade {
+	switch {
+	case hours <= criteria.MaxPowerOnHoursForA:
+		return common.GradeA
+	case hours <= criteria.MaxPowerOnHoursForB:
+		return common.GradeB
+	case hours <= criteria.MaxPowerOnHoursForC:
+		return common.GradeC
+	default:
+		return common.GradeF
+	}
+}
+
+func gradeFromWear(criteria DiskGradingCriteria, percentUsed int) common.Grade {
+	switch {
+	case percentUsed <= criteria.MaxPercentUsedForA:
+		return common.GradeA
+	case percentUsed <= criteria.MaxPercentUsedForB:
+		return common.GradeB
+	case percentUsed <= criteria.MaxPercentUsedForC:
+		return common.GradeC
+	default:
+		return common.GradeF
+	}
+}
+
+// DetectHealthIssues détecte les problèmes remontés par SMART / NVMe
+func DetectHealthIssues(health *probe.DiskHealth, criteria DiskGradingCriteria) []string {
+	issues := []string{}
+	if health == nil {
+		return issues
+	}
+
+	if health.PendingSectors > 0 {
+		issues = append(issues, fmt.Sprintf("Secteurs instables en attente: %d", health.PendingSectors))
+	}
+	if health.ReallocatedSectors > 0 {
+		issues = append(issues, fmt.Sprintf("Secteurs réalloués: %d", health.ReallocatedSectors))
+	}
+	if health.Uncorrectable > 0 {
+		issues = append(issues, fmt.Sprintf("Erreurs non corrigeables: %d", health.Uncorrectable))
+	}
+	if health.MediaErrors > 0 {
+		issues = append(issues, fmt.Sprintf("Erreurs média NVMe: %d", health.MediaErrors))
+	}
+	if health.CriticalWarning != 0 {
+		issues = append(issues, fmt.Sprintf("Alerte critique NVMe (0x%02x)", health.CriticalWarning))
+	}
+	if health.Source == probe.HealthSourceNVMe && health.AvailableSpare < criteria.MinAvailableSpareForC {
+		issues = append(issues, fmt.Sprintf("Réserve NVMe épuisée (%d%%)", health.AvailableSpare))
+	}
+	if health.PowerOnHours > criteria.MaxPowerOnHoursForC {
+		issues = append(issues, fmt.Sprintf("Usage très élevé (%d h de fonctionnement)", health.PowerOnHours))
+	}
+	if health.PercentageUsed > criteria.MaxPercentUsedForC {
+		issues = append(issues, fmt.Sprintf("Usure avancée (%d%% de l'endurance)", health.PercentageUsed))
+	}
+	if health.TemperatureC > criteria.MaxTemperatureC {
+		issues = append(issues, fmt.Sprintf("Température élevée (%d °C)", health.TemperatureC))
+	}
+
+	return issues
+}
+
+// ═══════════════════════════════════════════════════════════════════
+// BENCHMARK
+// ═══════════════════════════════════════════════════════════════════
+
+// DefaultBenchmarkGradingCriteria retourne les seuils par défaut
+func DefaultBenchmarkGradingCriteria() BenchmarkGradingCriteria {
+	return BenchmarkGradingCriteria{
+		MinRatioForA:    0.8,  // >= 80 % de l'attendu = A
+		MinRatioForB:    0.5,  // >= 50 % = B
+		MinRatioForC:    0.25, // >= 25 % = C (un SSD SATA à 20 MB/s est F)
+		LatencyMaxGrade: common.GradeC,
+	}
+}
+
+// ExpectedPerformance retourne le niveau attendu d'un disque sain selon son
+// type et son bus. Les valeurs sont des planchers prudents pour du matériel
+// d'occasion, pas des fiches constructeur : un disque sain les dépasse.
+func ExpectedPerformance(diskType, bus string) BenchmarkExpectation {
+	if diskType == TypeHDD {
+		if bus == probe.BusUSB {
+			return BenchmarkExpectation{Profile: "HDD USB", SeqReadMBps: 70, SeqWriteMBps: 60, RandReadIOPS: 60, MaxP99LatencyMs: 200}
+		}
+		return BenchmarkExpectation{Profile: "HDD", SeqReadMBps: 90, SeqWriteMBps: 80, RandReadIOPS: 80, MaxP99LatencyMs: 150}
+	}
+
+	switch bus {
+	case probe.BusNVMe:
+		return BenchmarkExpectation{Profile: "SSD NVMe", SeqReadMBps: 1500, SeqWriteMBps: 800, RandReadIOPS: 30_000, MaxP99LatencyMs: 5}
+	case probe.BusUSB:
+		return BenchmarkExpectation{Profile: "SSD USB", SeqReadMBps: 200, SeqWriteMBps: 150, RandReadIOPS: 2_000, MaxP99LatencyMs: 30}
+	case probe.BusMMC:
+		return BenchmarkExpectation{Profile: "eMMC", SeqReadMBps: 150, SeqWriteMBps: 60, RandReadIOPS: 1_500, MaxP99LatencyMs: 50}
+	default:
+		return BenchmarkExpectation{Profile: "SSD SATA", SeqReadMBps: 400, SeqWriteMBps: 300, RandReadIOPS: 8_000, MaxP99LatencyMs: 10}
+	}
+}
+
+// ComputeBenchmarkGrade note chaque mesure par rapport à l'attendu et
+// retient la pire. Une mesure absente (écriture non testée) est ignorée.
+func ComputeBenchmarkGrade(criteria BenchmarkGradingCriteria, result BenchmarkResult) common.Grade {
+	exp := result.Expected
+	grade := common.GradeA
+
+	grade = common.WorseGrade(grade, gradeFromRatio(criteria, result.SeqReadMBps/exp.SeqReadMBps))
+	if result.WriteTested {
+		grade = common.WorseGrade(grade, gradeFromRatio(criteria, result.SeqWriteMBps/exp.SeqWriteMBps))
+	}
+	if result.RandReadIOPS > 0 {
+		grade = common.WorseGrade(grade, gradeFromRatio(criteria, result.RandReadIOPS/exp.RandReadIOPS))
+	}
+	if latencyMs(result.LatencyP99) > exp.MaxP99LatencyMs {
+		grade = common.WorseGrade(grade, criteria.LatencyMaxGrade)
+	}
+	return grade
+}
+
+func gradeFromRatio(criteria BenchmarkGradingCriteria, ratio float64) common.Grade {
+	switch {
+	case ratio >= criteria.MinRatioForA:
+		return common.GradeA
+	case ratio >= criteria.MinRatioForB:
+		return common.GradeB
+	case ratio >= criteria.MinRatioForC:
+		return common.GradeC
+	default:
+		return common.GradeF
+	}
+}
+
+// DetectBenchmarkIssues signale les mesures sous le seuil du grade A
+func DetectBenchmarkIssues(result BenchmarkResult, criteria BenchmarkGradingCriteria) []string {
+	issues := []string{}
+	exp := result.Expected
+
+	if result.SeqReadMBps < criteria.MinRatioForA*exp.SeqReadMBps {
+		issues = append(issues, fmt.Sprintf("Lecture séquentielle lente : %.0f MB/s (attendu ≥ %.0f MB/s pour %s)",
+			result.SeqReadMBps, exp.SeqReadMBps, exp.Profile))
+	}
+	if result.WriteTested && result.SeqWriteMBps < criteria.MinRatioForA*exp.SeqWriteMBps {
+		issues = append(issues, fmt.Sprintf("Écriture séquentielle lente : %.0f MB/s (attendu ≥ %.0f MB/s pour %s)",
+			result.SeqWriteMBps, exp.SeqWriteMBps, exp.Profile))
+	}
+	if result.RandReadIOPS > 0 && result.RandReadIOPS < criteria.MinRatioForA*exp.RandReadIOPS {
+		issues = append(issues, fmt.Sprintf("Lecture 4K aléatoire lente : %.0f IOPS (attendu ≥ %.0f IOPS pour %s)",
+			result.RandReadIOPS, exp.RandReadIOPS, exp.Profile))
+	}
+	if p99 := latencyMs(result.LatencyP99); p99 > exp.MaxP99LatencyMs {
+		issues = append(issues, fmt.Sprintf("Latence 4K élevée : p99 %.1f ms (max %.0f ms), pire %.1f ms",
+			p99, exp.MaxP99LatencyMs, latencyMs(result.LatencyMax)))
+	}
+
+	return issues
+}
+
+func latencyMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// ═══════════════════════════════════════════════════════════════════
+// SCAN DE SURFACE
+// ═══════════════════════════════════════════════════════════════════
+
+// SurfaceHistogramBounds sont les tranches de latence de l'histogramme
+// (celles de Victoria pour des lectures de 256 KiB).
+var SurfaceHistogramBounds = []time.Duration{
+	5 * time.Millisecond,
+	20 * time.Millisecond,
+	50 * time.Millisecond,
+	150 * time.Millisecond,
+	500 * time.Millisecond,
+}
+
+// DefaultSurfaceGradingCriteria retourne les seuils par défaut
+func DefaultSurfaceGradingCriteria() SurfaceGradingCriteria {
+	return SurfaceGradingCriteria{
+		SlowLatency:     150 * time.Millisecond,
+		VerySlowLatency: 500 * time.Millisecond,
+		MaxSlowForA:     5,
+		MaxSlowForB:     50,
+		MaxVerySlowForC: 10,
+	}
+}
+
+// ClassifyLatency classe une lecture réussie selon sa durée
+func ClassifyLatency(criteria SurfaceGradingCriteria, latency time.Duration) SurfaceClass {
+	switch {
+	case latency >= criteria.VerySlowLatency:
+		return SurfaceVerySlow
+	case latency >= criteria.SlowLatency:
+		return SurfaceSlow
+	default:
+		return SurfaceOK
+	}
+}
+
+// ComputeSurfaceGrade note le scan. Une seule zone illisible vaut F : le
+// disque perd déjà des données.
+func ComputeSurfaceGrade(criteria SurfaceGradingCriteria, result SurfaceScanResult) common.Grade {
+	switch {
+	case result.Unreadable > 0 || result.VerySlow > criteria.MaxVerySlowForC:
+		return common.GradeF
+	case result.VerySlow > 0 || result.Slow > criteria.MaxSlowForB:
+		return common.GradeC
+	case result.Slow > criteria.MaxSlowForA:
+		return common.GradeB
+	default:
+		return common.GradeA
+	}
+}
+
+// DetectSurfaceIssues résume les zones défectueuses
+func DetectSurfaceIssues(result SurfaceScanResult, criteria SurfaceGradingCriteria) []string {
+	issues := []string{}
+
+	first := func(class SurfaceClass) int64 {
+		for _, r := range result.Regions {
+			if r.Class == class {
+				return r.Offset
+			}
+		}
+		return -1
+	}
+	if result.Unreadable > 0 {
+		issues = append(issues, fmt.Sprintf("Surface : %d zone(s) illisible(s), première à l'offset %d",
+			result.Unreadable, first(SurfaceUnreadable)))
+	}
+	if result.VerySlow > 0 {
+		issues = append(issues, fmt.Sprintf("Surface : %d zone(s) très lente(s) (≥ %s), première à l'offset %d",
+			result.VerySlow, criteria.VerySlowLatency, first(SurfaceVerySlow)))
+	}
+	if result.Slow > criteria.MaxSlowForA {
+		issues = append(issues, fmt.Sprintf("Surface : %d zone(s) lente(s) (≥ %s)", result.Slow, criteria.SlowLatency))
+	}
+	if !result.Complete {
+		issues = append(issues, fmt.Sprintf("Scan de surface incomplet : %.1f %% lus",
+			float64(result.NextOffset)*100/float64(max(result.SizeBytes, 1))))
+	}
+
+	return issues
 }
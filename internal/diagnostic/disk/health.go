@@ -0,0 +1,84 @@
+package disk
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"gobox/internal/diagnostic/common"
+	"gobox/internal/probe"
+)
+
+// bytesPerGB : gigaoctet décimal, celui des étiquettes constructeur
+// (un SSD « 128 GB » doit atteindre le seuil de 120 GB).
+const bytesPerGB = 1_000_000_000
+
+// RunDiskTest exécute le test d'un disque et retourne le résultat structuré
+// Retourne (résultat, erreur)
+func RunDiskTest(diskName string) (DiskHealthTest, error) {
+	// 1. Récupérer les données brutes
+	info, err := probe.GetDiskInfo(diskName)
+	if err != nil {
+		return DiskHealthTest{}, fmt.Errorf("récupération disque %s: %w", diskName, err)
+	}
+
+	// 2. Charger les critères
+	criteria := DefaultDiskGradingCriteria()
+
+	// 3. Calculer la taille et l'état des partitions
+	sizeGB := float64(info.SizeBytes) / bytesPerGB
+	hasPartitions := len(info.Partitions) > 0
+
+	// 4. Lire la santé firmware (optionnelle : USB, droits, rejeu)
+	health, healthErr := probe.GetDiskHealth(diskName)
+
+	// 5. Obtenir le grade
+	grade := common.WorseGrade(
+		ComputeGrade(criteria, info.Type, sizeGB, hasPartitions),
+		ComputeHealthGrade(criteria, health),
+	)
+
+	// 6. Détecter les problèmes
+	issues := DetectIssues(info.Type, sizeGB, hasPartitions, info.Partitions, criteria)
+	issues = append(issues, DetectHealthIssues(health, criteria)...)
+	if healthErr != nil {
+		issues = append(issues, fmt.Sprintf("Données SMART indisponibles: %v", healthErr))
+	}
+
+	// 7. Construire et retourner le résultat
+	return DiskHealthTest{
+		DiskName:      info.Name,
+		Vendor:        info.Vendor,
+		Model:         info.Model,
+		Serial:        info.Serial,
+		Type:          info.Type,
+		Grade:         grade,
+		SizeGB:        sizeGB,
+		HasPartitions: hasPartitions,
+		PartitionList: info.Partitions,
+		Health:        health,
+		Issues:        issues,
+		Timestamp:     time.Now(),
+	}, nil
+}
+
+// RunAllDiskTests teste tous les disques physiques détectés.
+// Un disque illisible est ignoré (avertissement) sans bloquer les autres.
+func RunAllDiskTests() ([]DiskHealthTest, error) {
+	disks, err := probe.ListDisks()
+	if err != nil {
+		return nil, fmt.Errorf("listage disques: %w", err)
+	}
+
+	results := make([]DiskHealthTest, 0, len(disks))
+	for _, name := range disks {
+		result, err := RunDiskTest(name)
+		if err != nil {
+			log.Printf("Warning: test disque %s ignoré : %v", name, err)
+			continue
+		}
+		results = append(results, result)
+	}
+
+	return results, nil
+}
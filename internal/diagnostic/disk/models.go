@@ -1 +1,167 @@
 package disk
+
+import (
+	"time"
+
+	"gobox/internal/diagnostic/common"
+	"gobox/internal/probe"
+)
+
+// DiskHealthTest résultat du test disque
+type DiskHealthTest struct {
+	DiskName      string             // ex: "nvme0n1"
+	Vendor        string             // ex: "Samsung"
+	Model         string             // ex: "980 PRO"
+	Serial        string             // ex: "S5GXNF0R123456"
+	Type          string             // "SSD" ou "HDD"
+	Grade         common.Grade       // "A", "B", "C", "F"
+	SizeGB        float64            // Taille en GB
+	HasPartitions bool               // true si partitions détectées
+	PartitionList []string           // Liste des partitions
+	Health        *probe.DiskHealth  // Données SMART/NVMe (nil si illisibles)
+	Benchmark     *BenchmarkResult   // Mesures de performance (nil si non mesurées)
+	Surface       *SurfaceScanResult // Scan de surface (nil si non effectué)
+	Issues        []string           // Problèmes détectés
+	Timestamp     time.Time
+}
+
+// DiskGradingCriteria critères de notation disque
+type DiskGradingCriteria struct {
+	// Taille minimale acceptable (en GB)
+	MinSizeForA float64 // ex: 200 GB → Grade A
+	MinSizeForB float64 // ex: 120 GB → Grade B
+	MinSizeForC float64 // ex: 60 GB → Grade C
+	// < 60 GB = automatiquement F
+
+	// Bonus SSD (si true, SSD ne peut pas être < B)
+	SSDMinGrade common.Grade // ex: GradeB (SSD jamais en C ou F sauf si autre problème)
+
+	// Santé firmware (SMART / NVMe)
+	MaxPowerOnHoursForA   int64 // ex: 10 000 h → Grade A
+	MaxPowerOnHoursForB   int64 // ex: 20 000 h → Grade B
+	MaxPowerOnHoursForC   int64 // ex: 35 000 h → Grade C
+	MaxPercentUsedForA    int   // ex: 10 % d'usure → Grade A
+	MaxPercentUsedForB    int   // ex: 30 % → Grade B
+	MaxPercentUsedForC    int   // ex: 80 % → Grade C
+	MaxReallocatedForC    int64 // secteurs réalloués tolérés en C (au-delà = F, > 0 = C max)
+	MaxTemperatureC       int   // au-delà : problème signalé
+	MinAvailableSpareForC int   // NVMe : réserve minimale (%)
+}
+
+// BenchmarkOptions paramètre le benchmark disque
+type BenchmarkOptions struct {
+	SeqBytes       int64         // volume séquentiel maximal, ex: 1 GiB
+	SeqMaxDuration time.Duration // arrêt anticipé d'une phase séquentielle trop lente
+	BlockSize      int           // taille des E/S séquentielles, ex: 1 MiB
+	RandomDuration time.Duration // durée de la phase 4K aléatoire
+	RandomWorkers  int           // lectures aléatoires simultanées (profondeur de file)
+	// AllowWrite autorise l'écriture séquentielle, destructive : elle écrase
+	// le début du disque, qui doit être démonté et libre (cf. CheckNotInUse).
+	AllowWrite bool
+	OnProgress func(phase string, fraction float64) // peut être nil
+}
+
+// BenchmarkExpectation est le niveau attendu pour une classe de disque
+type BenchmarkExpectation struct {
+	Profile         string  // ex: "SSD NVMe", "HDD USB"
+	SeqReadMBps     float64 // débit séquentiel en lecture
+	SeqWriteMBps    float64 // débit séquentiel en écriture
+	RandReadIOPS    float64 // lectures 4K aléatoires par seconde
+	MaxP99LatencyMs float64 // latence 4K au 99e centile
+}
+
+// BenchmarkResult résultat du benchmark disque
+type BenchmarkResult struct {
+	DiskName      string
+	Type          string // "SSD" ou "HDD"
+	Bus           string // "NVMe", "SATA", "USB", "MMC" ou ""
+	SeqReadMBps   float64
+	SeqWriteMBps  float64 // 0 si l'écriture n'a pas été testée
+	WriteTested   bool
+	RandReadIOPS  float64
+	LatencyP50    time.Duration
+	LatencyP95    time.Duration
+	LatencyP99    time.Duration
+	LatencyMax    time.Duration
+	RandomWorkers int
+	Expected      BenchmarkExpectation
+	Grade         common.Grade
+	Issues        []string
+	Timestamp     time.Time
+}
+
+// BenchmarkGradingCriteria seuils en fraction du niveau attendu
+type BenchmarkGradingCriteria struct {
+	MinRatioForA float64 // ex: 0.8 de l'attendu → Grade A
+	MinRatioForB float64 // ex: 0.5 → Grade B
+	MinRatioForC float64 // ex: 0.25 → Grade C (en dessous = F)
+	// LatencyMaxGrade plafonne la note si la latence p99 dépasse l'attendu
+	LatencyMaxGrade common.Grade
+}
+
+// SurfaceClass est l'état d'une zone après le scan de surface
+type SurfaceClass uint8
+
+const (
+	SurfacePending    SurfaceClass = iota // pas encore lue
+	SurfaceOK                             // lecture sous le seuil « lent »
+	SurfaceSlow                           // lecture lente (secteur faible, relecture interne)
+	SurfaceVerySlow                       // lecture très lente, secteur en fin de vie
+	SurfaceUnreadable                     // erreur de lecture
+)
+
+// SurfaceScanOptions paramètre le scan de surface
+type SurfaceScanOptions struct {
+	ChunkSize  int                       // taille d'une lecture, ex: 256 KiB
+	Resume     *SurfaceScanResult        // scan interrompu à reprendre, peut être nil
+	OnProgress func(SurfaceScanProgress) // appelé au plus toutes les 500 ms, peut être nil
+}
+
+// SurfaceScanProgress décrit l'avancement live du scan
+type SurfaceScanProgress struct {
+	BytesDone   int64
+	BytesTotal  int64
+	BytesPerSec float64
+	ETA         time.Duration
+	Slow        int64
+	VerySlow    int64
+	Unreadable  int64
+}
+
+// SurfaceRegion est une zone défectueuse (zones contiguës de même classe fusionnées)
+type SurfaceRegion struct {
+	Offset int64
+	Length int64
+	Class  SurfaceClass
+}
+
+// SurfaceScanResult résultat (éventuellement partiel) du scan de surface
+type SurfaceScanResult struct {
+	DiskName   string
+	SizeBytes  int64
+	ChunkSize  int
+	NextOffset int64          // reprise : premier octet non lu
+	Complete   bool           // toute la surface a été lue
+	Map        []SurfaceClass // une entrée par zone de ChunkSize octets
+	// Histogram compte les zones par tranche de latence : Histogram[i] pour
+	// une latence < SurfaceHistogramBounds[i], la dernière au-delà. Les
+	// zones illisibles n'y figurent pas.
+	Histogram  []int64
+	Slow       int64
+	VerySlow   int64
+	Unreadable int64
+	Regions    []SurfaceRegion // zones lentes, très lentes ou illisibles
+	Duration   time.Duration   // cumulée sur les reprises
+	Grade      common.Grade
+	Issues     []string
+	Timestamp  time.Time
+}
+
+// SurfaceGradingCriteria seuils de classification et de notation du scan
+type SurfaceGradingCriteria struct {
+	SlowLatency     time.Duration // ex: 150 ms → zone lente
+	VerySlowLatency time.Duration // ex: 500 ms → zone très lente
+	MaxSlowForA     int64         // zones lentes tolérées en A (recalibrages ponctuels)
+	MaxSlowForB     int64         // au-delà : C
+	MaxVerySlowForC int64         // au-delà : F (toute zone très lente = C max)
+}
@@ -0,0 +1,552 @@
+package operations
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gobox/internal/blockdev"
+	"gobox/internal/probe"
+)
+
+// FirmwareMethod est un effacement exécuté par le contrôleur du disque
+type FirmwareMethod string
+
+const (
+	FirmwareATAErase           FirmwareMethod = "ata-erase"            // SECURITY ERASE UNIT normal
+	FirmwareATAEnhanced        FirmwareMethod = "ata-enhanced"         // SECURITY ERASE UNIT enhanced
+	FirmwareNVMeFormat         FirmwareMethod = "nvme-format"          // Format NVM, SES=1 (user data erase)
+	FirmwareNVMeFormatCrypto   FirmwareMethod = "nvme-format-crypto"   // Format NVM, SES=2 (cryptographic erase)
+	FirmwareNVMeSanitize       FirmwareMethod = "nvme-sanitize"        // Sanitize, block erase
+	FirmwareNVMeSanitizeCrypto FirmwareMethod = "nvme-sanitize-crypto" // Sanitize, crypto erase
+)
+
+// FrozenHint explique comment sortir un disque ATA de l'état « frozen ».
+const FrozenHint = "le BIOS a gelé la sécurité ATA au démarrage : mettre la machine " +
+	"en veille (rtcwake -m mem -s 10, ou echo mem > /sys/power/state) puis relancer " +
+	"sans redémarrer, ou rebrancher le disque à chaud"
+
+var (
+	// ErrFrozen signale un disque ATA en état « security frozen »
+	ErrFrozen = errors.New("sécurité ATA gelée (frozen)")
+	// ErrLocked signale un disque ATA verrouillé par un mot de passe inconnu
+	ErrLocked = errors.New("disque ATA verrouillé par mot de passe")
+	// ErrUnsupported signale une méthode non proposée par le disque
+	ErrUnsupported = errors.New("méthode non supportée par le disque")
+)
+
+const (
+	ataCmdSecuritySetPassword  = 0xF1
+	ataCmdSecurityErasePrepare = 0xF3
+	ataCmdSecurityEraseUnit    = 0xF4
+	ataCmdSecurityDisable      = 0xF6
+
+	// ataErasePassword est le mot de passe utilisateur temporaire posé
+	// avant l'effacement ; l'effacement réussi le supprime.
+	ataErasePassword = "gobox"
+
+	ataDefaultEraseTimeout = 12 * time.Hour
+	nvmeFormatTimeout      = 2 * time.Hour
+	sanitizePollInterval   = 2 * time.Second
+)
+
+// Valeurs de SANACT (Sanitize, CDW10 bits 2:0).
+const (
+	sanitizeActionBlockErase  = 0x2
+	sanitizeActionCryptoErase = 0x4
+)
+
+// Valeurs de SES (Format NVM, CDW10 bits 11:9).
+const (
+	formatSESUserData = 0x1
+	formatSESCrypto   = 0x2
+)
+
+// Valeurs de SSTAT (journal Sanitize Status, bits 2:0).
+const (
+	SanitizeNeverRun      = 0
+	SanitizeSucceeded     = 1
+	SanitizeInProgress    = 2
+	SanitizeFailed        = 3
+	SanitizeSucceededNDAS = 4 // succès, sans désallocation
+)
+
+// ATASecurity reflète le mot 128 d'IDENTIFY DEVICE (Security status)
+type ATASecurity struct {
+	Supported        bool
+	Enabled          bool // mot de passe utilisateur posé
+	Locked           bool
+	Frozen           bool
+	CountExpired     bool
+	EnhancedSupport  bool
+	EraseTime        time.Duration // estimation constructeur, 0 si inconnue
+	EnhancedTime     time.Duration
+	EraseTimeAtLeast bool // estimation saturée (« plus de 508 min »)
+}
+
+// NVMeEraseSupport résume les capacités d'effacement d'un contrôleur NVMe
+type NVMeEraseSupport struct {
+	Format            bool // OACS bit 1
+	FormatCrypto      bool // FNA bit 2
+	FormatAllNS       bool // FNA bit 0 : le format s'applique à tous les namespaces
+	SanitizeCrypto    bool // SANICAP bit 0
+	SanitizeBlock     bool // SANICAP bit 1
+	SanitizeOverwrite bool // SANICAP bit 2
+}
+
+// FirmwareCapabilities décrit les effacements matériels possibles
+type FirmwareCapabilities struct {
+	Disk      string
+	Transport string // "ata" ou "nvme"
+	Methods   []FirmwareMethod
+	ATA       *ATASecurity
+	NVMe      *NVMeEraseSupport
+	Sanitize  *SanitizeStatus // dernier état connu (NVMe uniquement)
+}
+
+// SanitizeStatus décode le journal Sanitize Status (log 0x81)
+type SanitizeStatus struct {
+	Progress       float64 // 0..1, significatif pendant une opération
+	Status         int     // Sanitize*
+	GlobalErased   bool    // aucune donnée utilisateur écrite depuis le dernier sanitize
+	EstimateBlock  time.Duration
+	EstimateCrypto time.Duration
+}
+
+// FirmwareProgress est remonté pendant un effacement matériel
+type FirmwareProgress struct {
+	Method   FirmwareMethod
+	Progress float64 // 0..1, -1 si le disque ne le remonte pas (ATA, Format)
+	Elapsed  time.Duration
+	Estimate time.Duration // estimation constructeur, 0 si inconnue
+}
+
+// FirmwareResult trace structurée d'un effacement matériel
+type FirmwareResult struct {
+	Disk      string
+	Method    FirmwareMethod
+	StartedAt time.Time
+	EndedAt   time.Time
+	Completed bool
+}
+
+// ═══════════════════════════════════════════════════════════════════
+// DÉTECTION
+// ═══════════════════════════════════════════════════════════════════
+
+// DetectFirmwareErase interroge le disque (IDENTIFY) et liste les
+// effacements matériels disponibles. Nécessite les droits root.
+func DetectFirmwareErase(diskName string) (FirmwareCapabilities, error) {
+	if !probe.FS().IsHost() {
+		return FirmwareCapabilities{}, errors.New("détection impossible en mode rejeu")
+	}
+	dev, err := blockdev.Open(diskName, os.O_RDONLY)
+	if err != nil {
+		return FirmwareCapabilities{}, err
+	}
+	defer dev.Close()
+	return detect(dev)
+}
+
+func detect(dev *blockdev.Device) (FirmwareCapabilities, error) {
+	caps := FirmwareCapabilities{Disk: dev.Name}
+
+	if strings.HasPrefix(dev.Name, "nvme") {
+		caps.Transport = "nvme"
+		ctrl, err := dev.NVMeIdentifyController()
+		if err != nil {
+			return caps, fmt.Errorf("identify NVMe %s: %w", dev.Name, err)
+		}
+		support := ParseNVMeEraseSupport(ctrl)
+		caps.NVMe = &support
+
+		if support.Format {
+			caps.Methods = append(caps.Methods, FirmwareNVMeFormat)
+		}
+		if support.FormatCrypto {
+			caps.Methods = append(caps.Methods, FirmwareNVMeFormatCrypto)
+		}
+		if support.SanitizeBlock {
+			caps.Methods = append(caps.Methods, FirmwareNVMeSanitize)
+		}
+		if support.SanitizeCrypto {
+			caps.Methods = append(caps.Methods, FirmwareNVMeSanitizeCrypto)
+		}
+		if support.SanitizeBlock || support.SanitizeCrypto {
+			if status, err := readSanitizeStatus(dev); err == nil {
+				caps.Sanitize = &status
+			}
+		}
+		return caps, nil
+	}
+
+	caps.Transport = "ata"
+	identify, err := dev.ATAIdentify()
+	if err != nil {
+		return caps, fmt.Errorf("identify ATA %s: %w", dev.Name, err)
+	}
+	security := ParseATASecurity(identify)
+	caps.ATA = &security
+
+	if security.Supported {
+		caps.Methods = append(caps.Methods, FirmwareATAErase)
+		if security.EnhancedSupport {
+			caps.Methods = append(caps.Methods, FirmwareATAEnhanced)
+		}
+	}
+	return caps, nil
+}
+
+// ParseATASecurity décode les mots 82, 89, 90 et 128 d'IDENTIFY DEVICE.
+func ParseATASecurity(identify []byte) ATASecurity {
+	word := func(n int) uint16 {
+		if 2*n+2 > len(identify) {
+			return 0
+		}
+		return binary.LittleEndian.Uint16(identify[2*n:])
+	}
+
+	status := word(128)
+	security := ATASecurity{
+		Supported:       word(82)&(1<<1) != 0 && status&(1<<0) != 0,
+		Enabled:         status&(1<<1) != 0,
+		Locked:          status&(1<<2) != 0,
+		Frozen:          status&(1<<3) != 0,
+		CountExpired:    status&(1<<4) != 0,
+		EnhancedSupport: status&(1<<5) != 0,
+	}
+	security.EraseTime, security.EraseTimeAtLeast = ataEraseTime(word(89))
+	security.EnhancedTime, _ = ataEraseTime(word(90))
+	return security
+}
+
+// ataEraseTime décode une durée d'effacement en unités de 2 minutes
+// (format étendu si bit 15, valeur maximale = « au moins »).
+func ataEraseTime(w uint16) (time.Duration, bool) {
+	var units, maxUnits uint16
+	if w&0x8000 != 0 {
+		units, maxUnits = w&0x7FFF, 0x7FFF
+	} else {
+		units, maxUnits = w&0xFF, 0xFF
+	}
+	return time.Duration(units) * 2 * time.Minute, units == maxUnits
+}
+
+// ParseNVMeEraseSupport décode OACS, SANICAP et FNA d'Identify Controller.
+func ParseNVMeEraseSupport(ctrl []byte) NVMeEraseSupport {
+	if len(ctrl) < 528 {
+		return NVMeEraseSupport{}
+	}
+	oacs := binary.LittleEndian.Uint16(ctrl[256:])
+	sanicap := binary.LittleEndian.Uint32(ctrl[328:])
+	fna := ctrl[524]
+
+	return NVMeEraseSupport{
+		Format:            oacs&(1<<1) != 0,
+		FormatCrypto:      oacs&(1<<1) != 0 && fna&(1<<2) != 0,
+		FormatAllNS:       fna&(1<<0) != 0,
+		SanitizeCrypto:    sanicap&(1<<0) != 0,
+		SanitizeBlock:     sanicap&(1<<1) != 0,
+		SanitizeOverwrite: sanicap&(1<<2) != 0,
+	}
+}
+
+// ParseSanitizeStatus décode le journal Sanitize Status (log 0x81).
+func ParseSanitizeStatus(page []byte) (SanitizeStatus, error) {
+	if len(page) < 20 {
+		return SanitizeStatus{}, fmt.Errorf("journal sanitize tronqué (%d octets)", len(page))
+	}
+	sstat := binary.LittleEndian.Uint16(page[2:])
+	estimate := func(off int) time.Duration {
+		secs := binary.LittleEndian.Uint32(page[off:])
+		if secs == 0xFFFFFFFF {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+
+	return SanitizeStatus{
+		Progress:       float64(binary.LittleEndian.Uint16(page[0:])) / 65536,
+		Status:         int(sstat & 0x7),
+		GlobalErased:   sstat&(1<<8) != 0,
+		EstimateBlock:  estimate(12),
+		EstimateCrypto: estimate(16),
+	}, nil
+}
+
+func readSanitizeStatus(dev *blockdev.Device) (SanitizeStatus, error) {
+	page := make([]byte, 512)
+	if err := dev.NVMeGetLogPage(blockdev.NVMeLogSanitize, 0, page); err != nil {
+		return SanitizeStatus{}, fmt.Errorf("journal sanitize %s: %w", dev.Name, err)
+	}
+	return ParseSanitizeStatus(page)
+}
+
+// ═══════════════════════════════════════════════════════════════════
+// EFFACEMENT
+// ═══════════════════════════════════════════════════════════════════
+
+// FirmwareErase lance un effacement matériel et attend sa fin.
+//
+// Les mêmes refus que Wipe s'appliquent (disque monté, swap, holders).
+// Un disque ATA « frozen » renvoie ErrFrozen (voir FrozenHint).
+// onProgress peut être nil.
+func FirmwareErase(ctx context.Context, diskName string, method FirmwareMethod, onProgress func(FirmwareProgress)) (FirmwareResult, error) {
+	if !probe.FS().IsHost() {
+		return FirmwareResult{}, errors.New("refus : effacement impossible en mode rejeu")
+	}
+	if err := CheckNotInUse(diskName); err != nil {
+		return FirmwareResult{}, err
+	}
+
+	dev, err := blockdev.Open(diskName, os.O_RDWR|os.O_EXCL)
+	if err != nil {
+		return FirmwareResult{}, err
+	}
+	defer dev.Close()
+
+	caps, err := detect(dev)
+	if err != nil {
+		return FirmwareResult{}, err
+	}
+	if !hasMethod(caps.Methods, method) {
+		return FirmwareResult{}, fmt.Errorf("%w : %s sur %s", ErrUnsupported, method, diskName)
+	}
+	if controllerWide(caps, method) {
+		if err := CheckControllerNotInUse(diskName); err != nil {
+			return FirmwareResult{}, err
+		}
+	}
+	if onProgress == nil {
+		onProgress = func(FirmwareProgress) {}
+	}
+
+	result := FirmwareResult{Disk: diskName, Method: method, StartedAt: time.Now()}
+
+	switch method {
+	case FirmwareATAErase, FirmwareATAEnhanced:
+		err = ataSecurityErase(ctx, dev, *caps.ATA, method == FirmwareATAEnhanced, onProgress)
+	case FirmwareNVMeFormat:
+		err = nvmeFormat(ctx, dev, formatSESUserData, method, onProgress)
+	case FirmwareNVMeFormatCrypto:
+		err = nvmeFormat(ctx, dev, formatSESCrypto, method, onProgress)
+	case FirmwareNVMeSanitize:
+		err = nvmeSanitize(ctx, dev, sanitizeActionBlockErase, method, caps.Sanitize, onProgress)
+	case FirmwareNVMeSanitizeCrypto:
+		err = nvmeSanitize(ctx, dev, sanitizeActionCryptoErase, method, caps.Sanitize, onProgress)
+	}
+
+	result.EndedAt = time.Now()
+	result.Completed = err == nil
+	return result, err
+}
+
+// controllerWide indique un effacement qui touche tous les namespaces du
+// contrôleur et pas seulement diskName.
+func controllerWide(caps FirmwareCapabilities, method FirmwareMethod) bool {
+	switch method {
+	case FirmwareNVMeSanitize, FirmwareNVMeSanitizeCrypto:
+		return true
+	case FirmwareNVMeFormat, FirmwareNVMeFormatCrypto:
+		return caps.NVMe != nil && caps.NVMe.FormatAllNS
+	}
+	return false
+}
+
+func hasMethod(methods []FirmwareMethod, method FirmwareMethod) bool {
+	for _, m := range methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// ataSecurityBlock construit le bloc de 512 octets des commandes
+// SECURITY SET PASSWORD / ERASE UNIT / DISABLE PASSWORD :
+// mot 0 = contrôle (bit 0 identifiant master, bit 1 enhanced),
+// mots 1-16 = mot de passe (32 octets, complété par des zéros).
+func ataSecurityBlock(enhanced bool, password string) []byte {
+	block := make([]byte, 512)
+	if enhanced {
+		block[0] |= 1 << 1
+	}
+	copy(block[2:34], password)
+	return block
+}
+
+// ataSecurityCommand encode une commande de sécurité ATA (PIO out, 1 bloc).
+func ataSecurityCommand(command uint8) blockdev.ATACommand {
+	return blockdev.ATACommand{
+		Command:  command,
+		Count:    1,
+		Protocol: blockdev.ATAProtoPIOOut,
+	}
+}
+
+func ataSecurityErase(ctx context.Context, dev *blockdev.Device, security ATASecurity, enhanced bool, onProgress func(FirmwareProgress)) error {
+	switch {
+	case security.Frozen:
+		return fmt.Errorf("%s: %w (%s)", dev.Name, ErrFrozen, FrozenHint)
+	case security.Locked:
+		return fmt.Errorf("%s: %w", dev.Name, ErrLocked)
+	case security.CountExpired:
+		return fmt.Errorf("%s: compteur d'essais de mot de passe épuisé, redémarrer le disque", dev.Name)
+	case security.Enabled:
+		return fmt.Errorf("%s: un mot de passe utilisateur est déjà posé", dev.Name)
+	}
+
+	method, estimate := FirmwareATAErase, security.EraseTime
+	if enhanced {
+		method, estimate = FirmwareATAEnhanced, security.EnhancedTime
+	}
+	// Pas de sortie possible avant la fin : délai large, jamais sous l'estimation
+	timeout := max(ataDefaultEraseTimeout, 2*estimate+10*time.Minute)
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if _, err := dev.ATAPassThrough(ataSecurityCommand(ataCmdSecuritySetPassword),
+		ataSecurityBlock(false, ataErasePassword), 30*time.Second); err != nil {
+		return fmt.Errorf("SECURITY SET PASSWORD %s: %w", dev.Name, err)
+	}
+
+	prepare := blockdev.ATACommand{Command: ataCmdSecurityErasePrepare, Protocol: blockdev.ATAProtoNonData}
+	if _, err := dev.ATAPassThrough(prepare, nil, 30*time.Second); err != nil {
+		ataDisablePassword(dev)
+		return fmt.Errorf("SECURITY ERASE PREPARE %s: %w", dev.Name, err)
+	}
+
+	// ERASE UNIT est bloquant et non interruptible : un ticker remonte
+	// le temps écoulé pendant l'attente de l'ioctl.
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		_, err := dev.ATAPassThrough(ataSecurityCommand(ataCmdSecurityEraseUnit),
+			ataSecurityBlock(enhanced, ataErasePassword), timeout)
+		done <- err
+	}()
+
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				ataDisablePassword(dev)
+				return fmt.Errorf("SECURITY ERASE UNIT %s: %w", dev.Name, err)
+			}
+			onProgress(FirmwareProgress{Method: method, Progress: 1, Elapsed: time.Since(start), Estimate: estimate})
+			return nil
+		case <-ticker.C:
+			onProgress(FirmwareProgress{Method: method, Progress: -1, Elapsed: time.Since(start), Estimate: estimate})
+		}
+	}
+}
+
+// ataDisablePassword retire le mot de passe temporaire après un échec,
+// pour ne pas laisser un disque verrouillé au prochain démarrage.
+func ataDisablePassword(dev *blockdev.Device) {
+	dev.ATAPassThrough(ataSecurityCommand(ataCmdSecurityDisable),
+		ataSecurityBlock(false, ataErasePassword), 30*time.Second)
+}
+
+// nvmeFormatCDW10 encode le CDW10 de Format NVM en conservant le format
+// LBA courant (FLBAS) et la protection (DPS) du namespace.
+func nvmeFormatCDW10(flbas, dps, ses uint8) uint32 {
+	lbaf := uint32(flbas&0x0F) | uint32(flbas>>5&0x3)<<12
+	mset := uint32(flbas>>4&0x1) << 4
+	pi := uint32(dps&0x7) << 5
+	pil := uint32(dps>>3&0x1) << 8
+	return lbaf | mset | pi | pil | uint32(ses&0x7)<<9
+}
+
+// nvmeSanitizeCDW10 encode le CDW10 de Sanitize (AUSE=1 : sortie d'échec
+// sans commande explicite, NDAS=0 : désallocation après effacement).
+func nvmeSanitizeCDW10(action uint8) uint32 {
+	return uint32(action&0x7) | 1<<3
+}
+
+func nvmeFormat(ctx context.Context, dev *blockdev.Device, ses uint8, method FirmwareMethod, onProgress func(FirmwareProgress)) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	nsid, err := dev.NVMeNamespaceID()
+	if err != nil {
+		return err
+	}
+	ns, err := dev.NVMeIdentifyNamespace(nsid)
+	if err != nil {
+		return fmt.Errorf("identify namespace %d: %w", nsid, err)
+	}
+
+	cmd := blockdev.NVMeAdminCommand{
+		Opcode:    blockdev.NVMeOpFormatNVM,
+		NSID:      nsid,
+		CDW10:     nvmeFormatCDW10(ns[26], ns[29], ses),
+		TimeoutMs: uint32(nvmeFormatTimeout / time.Millisecond),
+	}
+
+	start := time.Now()
+	onProgress(FirmwareProgress{Method: method, Progress: -1})
+	if _, err := dev.NVMeAdmin(&cmd, nil); err != nil {
+		return fmt.Errorf("format NVM %s: %w", dev.Name, err)
+	}
+	onProgress(FirmwareProgress{Method: method, Progress: 1, Elapsed: time.Since(start)})
+	return nil
+}
+
+func nvmeSanitize(ctx context.Context, dev *blockdev.Device, action uint8, method FirmwareMethod, previous *SanitizeStatus, onProgress func(FirmwareProgress)) error {
+	if previous != nil && previous.Status == SanitizeInProgress {
+		return fmt.Errorf("%s: un sanitize est déjà en cours", dev.Name)
+	}
+
+	var estimate time.Duration
+	if previous != nil {
+		estimate = previous.EstimateBlock
+		if action == sanitizeActionCryptoErase {
+			estimate = previous.EstimateCrypto
+		}
+	}
+
+	cmd := blockdev.NVMeAdminCommand{
+		Opcode: blockdev.NVMeOpSanitize,
+		CDW10:  nvmeSanitizeCDW10(action),
+	}
+	if _, err := dev.NVMeAdmin(&cmd, nil); err != nil {
+		return fmt.Errorf("sanitize %s: %w", dev.Name, err)
+	}
+
+	// Le contrôleur exécute le sanitize en tâche de fond : on suit le
+	// journal 0x81 jusqu'à un état terminal.
+	start := time.Now()
+	ticker := time.NewTicker(sanitizePollInterval)
+	defer ticker.Stop()
+	for {
+		status, err := readSanitizeStatus(dev)
+		if err != nil {
+			return err
+		}
+		switch status.Status {
+		case SanitizeSucceeded, SanitizeSucceededNDAS:
+			onProgress(FirmwareProgress{Method: method, Progress: 1, Elapsed: time.Since(start), Estimate: estimate})
+			return nil
+		case SanitizeFailed:
+			return fmt.Errorf("sanitize %s: échec signalé par le contrôleur", dev.Name)
+		}
+		onProgress(FirmwareProgress{Method: method, Progress: status.Progress, Elapsed: time.Since(start), Estimate: estimate})
+
+		select {
+		case <-ctx.Done():
+			// Le sanitize continue côté contrôleur, même après un redémarrage
+			return fmt.Errorf("suivi interrompu, sanitize toujours en cours sur %s: %w", dev.Name, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,405 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+	"unsafe"
+
+	"gobox/internal/blockdev"
+)
+
+// Requêtes ioctl (voir blockdev/nvme.go et blockdev/sgio.go)
+const (
+	fakeIoctlNVMeID    = 0x4E40
+	fakeIoctlNVMeAdmin = 0xC0484E41
+	fakeIoctlSGIO      = 0x2285
+)
+
+// fakeNVMe remplace blockdev.Ioctl par un contrôleur simulé qui enregistre
+// les commandes admin reçues.
+type fakeNVMe struct {
+	nsid     uint32
+	flbas    uint8 // Identify Namespace octet 26
+	dps      uint8 // Identify Namespace octet 29
+	commands []blockdev.NVMeAdminCommand
+}
+
+func (f *fakeNVMe) install(t *testing.T) *blockdev.Device {
+	return installIoctl(t, "nvme0n1", f.ioctl)
+}
+
+// installIoctl remplace blockdev.Ioctl le temps du test et renvoie un
+// périphérique adossé à un fichier temporaire.
+func installIoctl(t *testing.T, name string, ioctl func(fd, req uintptr, arg unsafe.Pointer) (uintptr, error)) *blockdev.Device {
+	t.Helper()
+	previous := blockdev.Ioctl
+	blockdev.Ioctl = ioctl
+	t.Cleanup(func() { blockdev.Ioctl = previous })
+
+	file, err := os.Create(filepath.Join(t.TempDir(), name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { file.Close() })
+	return blockdev.NewDevice(name, file)
+}
+
+func (f *fakeNVMe) ioctl(fd, req uintptr, arg unsafe.Pointer) (uintptr, error) {
+	switch req {
+	case fakeIoctlNVMeID:
+		return uintptr(f.nsid), nil
+	case fakeIoctlNVMeAdmin:
+		cmd := (*blockdev.NVMeAdminCommand)(arg)
+		f.commands = append(f.commands, *cmd)
+		var data []byte
+		if cmd.DataLen > 0 {
+			data = unsafe.Slice(*(**byte)(unsafe.Pointer(&cmd.Addr)), cmd.DataLen)
+		}
+		switch {
+		case cmd.Opcode == blockdev.NVMeOpIdentify && cmd.CDW10 == 0:
+			data[26], data[29] = f.flbas, f.dps
+		case cmd.Opcode == blockdev.NVMeOpGetLogPage && cmd.CDW10&0xFF == blockdev.NVMeLogSanitize:
+			data[2] = SanitizeSucceeded
+		}
+		return 0, nil
+	}
+	return 0, os.ErrInvalid
+}
+
+func TestNVMeFormatCDW10(t *testing.T) {
+	tests := []struct {
+		name       string
+		flbas, dps uint8
+		ses        uint8
+		want       uint32
+	}{
+		{"lbaf0 user data", 0x00, 0x00, formatSESUserData, 0x1 << 9},
+		{"lbaf1 crypto", 0x01, 0x00, formatSESCrypto, 0x1 | 0x2<<9},
+		// FLBAS bits 6:5 = bits hauts du format, bit 4 = métadonnées étendues
+		{"lbaf17 extended metadata", 0x31, 0x00, formatSESUserData, 0x1 | 1<<12 | 1<<4 | 0x1<<9},
+		// DPS bits 2:0 = type PI, bit 3 = PI en tête
+		{"pi type 1 first", 0x00, 0x09, 0, 1<<5 | 1<<8},
+	}
+	for _, tt := range tests {
+		if got := nvmeFormatCDW10(tt.flbas, tt.dps, tt.ses); got != tt.want {
+			t.Errorf("%s: nvmeFormatCDW10(%#x, %#x, %d) = %#x, want %#x",
+				tt.name, tt.flbas, tt.dps, tt.ses, got, tt.want)
+		}
+	}
+}
+
+func TestNVMeSanitizeCDW10(t *testing.T) {
+	tests := []struct {
+		action uint8
+		want   uint32
+	}{
+		{sanitizeActionBlockErase, 0x2 | 1<<3},
+		{sanitizeActionCryptoErase, 0x4 | 1<<3},
+	}
+	for _, tt := range tests {
+		if got := nvmeSanitizeCDW10(tt.action); got != tt.want {
+			t.Errorf("nvmeSanitizeCDW10(%d) = %#x, want %#x", tt.action, got, tt.want)
+		}
+	}
+}
+
+func TestNVMeFormatCommand(t *testing.T) {
+	fake := &fakeNVMe{nsid: 3, flbas: 0x01, dps: 0x01}
+	dev := fake.install(t)
+
+	if err := nvmeFormat(context.Background(), dev, formatSESCrypto, FirmwareNVMeFormatCrypto, func(FirmwareProgress) {}); err != nil {
+		t.Fatalf("nvmeFormat: %v", err)
+	}
+	if len(fake.commands) != 2 {
+		t.Fatalf("got %d admin commands, want identify + format", len(fake.commands))
+	}
+	if id := fake.commands[0]; id.Opcode != blockdev.NVMeOpIdentify || id.NSID != 3 || id.DataLen != 4096 {
+		t.Errorf("identify = %+v", id)
+	}
+	format := fake.commands[1]
+	if format.Opcode != blockdev.NVMeOpFormatNVM || format.NSID != 3 {
+		t.Errorf("format opcode %#x nsid %d, want %#x nsid 3", format.Opcode, format.NSID, blockdev.NVMeOpFormatNVM)
+	}
+	if want := nvmeFormatCDW10(0x01, 0x01, formatSESCrypto); format.CDW10 != want {
+		t.Errorf("format CDW10 = %#x, want %#x", format.CDW10, want)
+	}
+	if format.TimeoutMs == 0 || format.DataLen != 0 {
+		t.Errorf("format timeout %d ms, %d data bytes", format.TimeoutMs, format.DataLen)
+	}
+}
+
+func TestNVMeSanitizeCommand(t *testing.T) {
+	fake := &fakeNVMe{nsid: 1}
+	dev := fake.install(t)
+
+	if err := nvmeSanitize(context.Background(), dev, sanitizeActionCryptoErase, FirmwareNVMeSanitizeCrypto, nil, func(FirmwareProgress) {}); err != nil {
+		t.Fatalf("nvmeSanitize: %v", err)
+	}
+	if len(fake.commands) != 2 {
+		t.Fatalf("got %d admin commands, want sanitize + log 0x81", len(fake.commands))
+	}
+	sanitize := fake.commands[0]
+	if sanitize.Opcode != blockdev.NVMeOpSanitize || sanitize.CDW10 != nvmeSanitizeCDW10(sanitizeActionCryptoErase) {
+		t.Errorf("sanitize = opcode %#x CDW10 %#x", sanitize.Opcode, sanitize.CDW10)
+	}
+	if log := fake.commands[1]; log.Opcode != blockdev.NVMeOpGetLogPage || log.CDW10&0xFF != blockdev.NVMeLogSanitize {
+		t.Errorf("status poll = opcode %#x CDW10 %#x", log.Opcode, log.CDW10)
+	}
+}
+
+func TestNVMeSanitizeRefusesWhileInProgress(t *testing.T) {
+	fake := &fakeNVMe{nsid: 1}
+	dev := fake.install(t)
+
+	err := nvmeSanitize(context.Background(), dev, sanitizeActionBlockErase, FirmwareNVMeSanitize,
+		&SanitizeStatus{Status: SanitizeInProgress}, func(FirmwareProgress) {})
+	if err == nil || len(fake.commands) != 0 {
+		t.Errorf("err = %v, %d commands sent, want refusal before any command", err, len(fake.commands))
+	}
+}
+
+func TestControllerWide(t *testing.T) {
+	perNS := FirmwareCapabilities{NVMe: &NVMeEraseSupport{}}
+	allNS := FirmwareCapabilities{NVMe: &NVMeEraseSupport{FormatAllNS: true}}
+	tests := []struct {
+		caps   FirmwareCapabilities
+		method FirmwareMethod
+		want   bool
+	}{
+		{perNS, FirmwareNVMeFormat, false},
+		{allNS, FirmwareNVMeFormat, true},
+		{allNS, FirmwareNVMeFormatCrypto, true},
+		{perNS, FirmwareNVMeSanitize, true},
+		{perNS, FirmwareNVMeSanitizeCrypto, true},
+		{FirmwareCapabilities{}, FirmwareATAErase, false},
+	}
+	for _, tt := range tests {
+		if got := controllerWide(tt.caps, tt.method); got != tt.want {
+			t.Errorf("controllerWide(FNA0=%t, %s) = %t, want %t",
+				tt.caps.NVMe != nil && tt.caps.NVMe.FormatAllNS, tt.method, got, tt.want)
+		}
+	}
+}
+
+func TestATASecurityEncoding(t *testing.T) {
+	block := ataSecurityBlock(true, ataErasePassword)
+	if len(block) != 512 || block[0] != 1<<1 || string(block[2:7]) != ataErasePassword || block[7] != 0 {
+		t.Errorf("enhanced block = % x", block[:8])
+	}
+	if block := ataSecurityBlock(false, ataErasePassword); block[0] != 0 {
+		t.Errorf("normal erase control word = %#x, want 0", block[0])
+	}
+
+	cmd := ataSecurityCommand(ataCmdSecurityEraseUnit)
+	if cmd.Command != 0xF4 || cmd.Count != 1 || cmd.Protocol != blockdev.ATAProtoPIOOut {
+		t.Errorf("erase unit command = %+v", cmd)
+	}
+}
+
+// fakeSGIOHdr reprend la disposition de struct sg_io_hdr (blockdev.sgIOHdr).
+type fakeSGIOHdr struct {
+	InterfaceID    int32
+	DxferDirection int32
+	CmdLen         uint8
+	MxSbLen        uint8
+	IovecCount     uint16
+	DxferLen       uint32
+	Dxferp         unsafe.Pointer
+	Cmdp           unsafe.Pointer
+	Sbp            unsafe.Pointer
+	Timeout        uint32
+	Flags          uint32
+	PackID         int32
+	UsrPtr         unsafe.Pointer
+	Status         uint8
+	MaskedStatus   uint8
+	MsgStatus      uint8
+	SbLenWr        uint8
+	HostStatus     uint16
+	DriverStatus   uint16
+	Resid          int32
+	Duration       uint32
+	Info           uint32
+}
+
+// sataCommand est une requête SG_IO reçue par fakeSATA.
+type sataCommand struct {
+	hdr  fakeSGIOHdr
+	cdb  []byte
+	data []byte // copie du bloc transmis
+}
+
+// fakeSATA remplace blockdev.Ioctl par un disque SATA derrière SG_IO. Les
+// commandes non-data répondent comme un vrai SAT (CK_COND : CHECK
+// CONDITION et registres ATA en sense) ; la commande failOn échoue.
+type fakeSATA struct {
+	failOn   uint8
+	mu       sync.Mutex // ERASE UNIT part d'une goroutine
+	commands []sataCommand
+}
+
+func (f *fakeSATA) install(t *testing.T) *blockdev.Device {
+	return installIoctl(t, "sda", f.ioctl)
+}
+
+func (f *fakeSATA) ioctl(fd, req uintptr, arg unsafe.Pointer) (uintptr, error) {
+	if req != fakeIoctlSGIO {
+		return 0, os.ErrInvalid
+	}
+	hdr := (*fakeSGIOHdr)(arg)
+	cmd := sataCommand{
+		hdr: *hdr,
+		cdb: append([]byte(nil), unsafe.Slice((*byte)(hdr.Cmdp), hdr.CmdLen)...),
+	}
+	if hdr.DxferLen > 0 {
+		cmd.data = append([]byte(nil), unsafe.Slice((*byte)(hdr.Dxferp), hdr.DxferLen)...)
+	}
+	f.mu.Lock()
+	f.commands = append(f.commands, cmd)
+	f.mu.Unlock()
+
+	ataStatus := uint8(0x50) // DRDY | DSC
+	switch {
+	case cmd.cdb[14] == f.failOn:
+		ataStatus = 0x51 // ERR
+	case cmd.cdb[1]>>1 != blockdev.ATAProtoNonData:
+		return 0, nil
+	}
+	// Sense au format descripteur, descripteur ATA Status Return (0x09)
+	sense := unsafe.Slice((*byte)(hdr.Sbp), hdr.MxSbLen)
+	sense[0], sense[7] = 0x72, 14
+	sense[8], sense[9], sense[8+13] = 0x09, 12, ataStatus
+	hdr.Status, hdr.SbLenWr = 0x02, 22 // CHECK CONDITION
+	return 0, nil
+}
+
+func (f *fakeSATA) opcodes() []uint8 {
+	var ops []uint8
+	for _, c := range f.commands {
+		ops = append(ops, c.cdb[14])
+	}
+	return ops
+}
+
+func TestATACommandCDB(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  blockdev.ATACommand
+		want [16]byte
+	}{
+		{
+			name: "set password (PIO out)",
+			cmd:  ataSecurityCommand(ataCmdSecuritySetPassword),
+			want: [16]byte{0x85, 5 << 1, 0x06, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 0xF1, 0},
+		},
+		{
+			name: "erase prepare (non-data, CK_COND)",
+			cmd:  blockdev.ATACommand{Command: ataCmdSecurityErasePrepare, Protocol: blockdev.ATAProtoNonData},
+			want: [16]byte{0x85, 3 << 1, 0x20, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0xF3, 0},
+		},
+		{
+			name: "smart read data (PIO in)",
+			cmd:  blockdev.ATACommand{Command: 0xB0, Features: 0xD0, Count: 1, LBA: 0xC24F00, Protocol: blockdev.ATAProtoPIOIn},
+			want: [16]byte{0x85, 4 << 1, 0x0E, 0, 0xD0, 0, 1, 0, 0x00, 0, 0x4F, 0, 0xC2, 0, 0xB0, 0},
+		},
+		{
+			// bits 24-27 de la LBA dans le registre device
+			name: "28-bit lba",
+			cmd:  blockdev.ATACommand{Command: 0x20, Count: 1, LBA: 0x0ABCDEF1, Device: 0x40, Protocol: blockdev.ATAProtoPIOIn},
+			want: [16]byte{0x85, 4 << 1, 0x0E, 0, 0, 0, 1, 0, 0xF1, 0, 0xDE, 0, 0xBC, 0x4A, 0x20, 0},
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.cmd.CDB(); string(got) != string(tt.want[:]) {
+			t.Errorf("%s: CDB = % x\n want % x", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestATASecurityEraseSequence(t *testing.T) {
+	for _, enhanced := range []bool{false, true} {
+		fake := &fakeSATA{}
+		dev := fake.install(t)
+
+		var last FirmwareProgress
+		security := ATASecurity{Supported: true, EnhancedSupport: true, EraseTime: 2 * time.Hour, EnhancedTime: time.Hour}
+		if err := ataSecurityErase(context.Background(), dev, security, enhanced, func(p FirmwareProgress) { last = p }); err != nil {
+			t.Fatalf("enhanced=%t: ataSecurityErase: %v", enhanced, err)
+		}
+		if got := fake.opcodes(); string(got) != "\xF1\xF3\xF4" {
+			t.Fatalf("enhanced=%t: opcodes % x, want SET PASSWORD, ERASE PREPARE, ERASE UNIT", enhanced, got)
+		}
+		if last.Progress != 1 {
+			t.Errorf("enhanced=%t: last progress %+v, want 1", enhanced, last)
+		}
+
+		for _, c := range fake.commands {
+			if c.hdr.InterfaceID != 'S' || c.hdr.CmdLen != 16 || c.hdr.MxSbLen != 32 || c.hdr.Sbp == nil {
+				t.Errorf("opcode %#x: header %+v", c.cdb[14], c.hdr)
+			}
+		}
+
+		setPassword, prepare, erase := fake.commands[0], fake.commands[1], fake.commands[2]
+		if setPassword.hdr.DxferDirection != int32(blockdev.DirToDevice) || len(setPassword.data) != 512 ||
+			setPassword.data[0] != 0 || string(setPassword.data[2:7]) != ataErasePassword {
+			t.Errorf("SET PASSWORD dir %d, block % x", setPassword.hdr.DxferDirection, setPassword.data[:8])
+		}
+		if prepare.hdr.DxferDirection != int32(blockdev.DirNone) || prepare.hdr.DxferLen != 0 || prepare.hdr.Dxferp != nil {
+			t.Errorf("ERASE PREPARE transfers data: %+v", prepare.hdr)
+		}
+		wantControl := byte(0)
+		if enhanced {
+			wantControl = 1 << 1
+		}
+		if erase.hdr.DxferDirection != int32(blockdev.DirToDevice) || len(erase.data) != 512 ||
+			erase.data[0] != wantControl || string(erase.data[2:7]) != ataErasePassword {
+			t.Errorf("enhanced=%t: ERASE UNIT block % x", enhanced, erase.data[:8])
+		}
+		if time.Duration(erase.hdr.Timeout)*time.Millisecond < ataDefaultEraseTimeout {
+			t.Errorf("ERASE UNIT timeout %d ms, want at least %s", erase.hdr.Timeout, ataDefaultEraseTimeout)
+		}
+	}
+}
+
+func TestATASecurityEraseDisablesPasswordOnFailure(t *testing.T) {
+	fake := &fakeSATA{failOn: ataCmdSecurityErasePrepare}
+	dev := fake.install(t)
+
+	err := ataSecurityErase(context.Background(), dev, ATASecurity{Supported: true}, false, func(FirmwareProgress) {})
+	if err == nil {
+		t.Fatal("failed ERASE PREPARE accepted")
+	}
+	if got := fake.opcodes(); string(got) != "\xF1\xF3\xF6" {
+		t.Errorf("opcodes % x, want SET PASSWORD, ERASE PREPARE, DISABLE PASSWORD", got)
+	}
+}
+
+func TestATASecurityEraseRefusals(t *testing.T) {
+	tests := []struct {
+		name     string
+		security ATASecurity
+		want     error
+	}{
+		{"frozen", ATASecurity{Supported: true, Frozen: true}, ErrFrozen},
+		{"locked", ATASecurity{Supported: true, Locked: true}, ErrLocked},
+		{"password set", ATASecurity{Supported: true, Enabled: true}, nil},
+		{"count expired", ATASecurity{Supported: true, CountExpired: true}, nil},
+	}
+	for _, tt := range tests {
+		fake := &fakeSATA{}
+		dev := fake.install(t)
+
+		err := ataSecurityErase(context.Background(), dev, tt.security, false, func(FirmwareProgress) {})
+		if err == nil || tt.want != nil && !errors.Is(err, tt.want) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.want)
+		}
+		if len(fake.commands) != 0 {
+			t.Errorf("%s: %d commands sent, want refusal before any command", tt.name, len(fake.commands))
+		}
+	}
+}
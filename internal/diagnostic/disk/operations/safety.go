@@ -0,0 +1,165 @@
+package operations
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gobox/internal/probe"
+)
+
+const (
+	pathMounts = "/proc/self/mounts"
+	pathSwaps  = "/proc/swaps"
+	sysBlock   = "/sys/block"
+)
+
+// CheckNotInUse refuse un disque monté, utilisé en swap ou tenu par un
+// device-mapper/RAID (LVM, dm-crypt, md). Le disque système, qui porte
+// forcément « / », est couvert par le contrôle des montages.
+func CheckNotInUse(diskName string) error {
+	info, err := probe.GetDiskInfo(diskName)
+	if err != nil {
+		return err
+	}
+
+	devices := map[string]bool{"/dev/" + diskName: true}
+	for _, part := range info.Partitions {
+		devices["/dev/"+part] = true
+	}
+
+	if mount, found, err := findMount(devices); err != nil {
+		return err
+	} else if found {
+		return fmt.Errorf("refus : %s est monté (%s)", diskName, mount)
+	}
+
+	if swap, found, err := findSwap(devices); err != nil {
+		return err
+	} else if found {
+		return fmt.Errorf("refus : %s est utilisé en swap", swap)
+	}
+
+	for _, name := range append([]string{diskName}, info.Partitions...) {
+		holders, err := listHolders(diskName, name)
+		if err != nil {
+			return err
+		}
+		if len(holders) > 0 {
+			return fmt.Errorf("refus : %s est utilisé par %s (LVM/dm-crypt/RAID)",
+				name, strings.Join(holders, ", "))
+		}
+	}
+
+	return nil
+}
+
+// CheckControllerNotInUse applique CheckNotInUse à tous les namespaces du
+// contrôleur qui porte diskName : un Sanitize, ou un Format quand le
+// contrôleur l'étend à tous les namespaces (FNA bit 0), les efface tous.
+func CheckControllerNotInUse(diskName string) error {
+	namespaces, err := controllerNamespaces(diskName)
+	if err != nil {
+		return err
+	}
+	for _, ns := range namespaces {
+		if err := CheckNotInUse(ns); err != nil {
+			return fmt.Errorf("%w (namespace du même contrôleur que %s)", err, diskName)
+		}
+	}
+	return nil
+}
+
+// controllerNamespaces liste les disques de /sys/block dont le lien device
+// mène au même contrôleur que diskName, diskName compris.
+func controllerNamespaces(diskName string) ([]string, error) {
+	controller, err := filepath.EvalSymlinks(filepath.Join(sysBlock, diskName, "device"))
+	if err != nil {
+		return nil, fmt.Errorf("contrôleur de %s: %w", diskName, err)
+	}
+
+	entries, err := os.ReadDir(sysBlock)
+	if err != nil {
+		return nil, fmt.Errorf("lecture %s: %w", sysBlock, err)
+	}
+	var namespaces []string
+	for _, e := range entries {
+		device, err := filepath.EvalSymlinks(filepath.Join(sysBlock, e.Name(), "device"))
+		if err == nil && device == controller {
+			namespaces = append(namespaces, e.Name())
+		}
+	}
+	return namespaces, nil
+}
+
+// findMount cherche un montage dont la source résout vers l'un des devices.
+func findMount(devices map[string]bool) (string, bool, error) {
+	f, err := os.Open(pathMounts)
+	if err != nil {
+		return "", false, fmt.Errorf("lecture %s: %w", pathMounts, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || !strings.HasPrefix(fields[0], "/dev/") {
+			continue
+		}
+		source := fields[0]
+		if resolved, err := filepath.EvalSymlinks(source); err == nil {
+			source = resolved
+		}
+		if devices[source] {
+			return fmt.Sprintf("%s sur %s", source, fields[1]), true, nil
+		}
+	}
+	return "", false, scanner.Err()
+}
+
+func findSwap(devices map[string]bool) (string, bool, error) {
+	f, err := os.Open(pathSwaps)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("lecture %s: %w", pathSwaps, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if devices[fields[0]] {
+			return fields[0], true, nil
+		}
+	}
+	return "", false, scanner.Err()
+}
+
+// listHolders liste /sys/block/<disk>[/<part>]/holders.
+func listHolders(diskName, name string) ([]string, error) {
+	dir := filepath.Join(sysBlock, diskName, "holders")
+	if name != diskName {
+		dir = filepath.Join(sysBlock, diskName, name, "holders")
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("lecture %s: %w", dir, err)
+	}
+
+	holders := make([]string, 0, len(entries))
+	for _, e := range entries {
+		holders = append(holders, e.Name())
+	}
+	return holders, nil
+}
@@ -0,0 +1,452 @@
+package operations
+
+import (
+	"bytes"
+	"context"
+	crand "crypto/rand"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"gobox/internal/blockdev"
+	"gobox/internal/probe"
+)
+
+const (
+	DefaultBlockSize = 4 << 20 // 4 MiB par écriture
+	directAlignment  = 4096    // alignement mémoire/offset requis par O_DIRECT
+	progressInterval = 500 * time.Millisecond
+)
+
+// Pattern est le contenu écrit lors d'une passe
+type Pattern struct {
+	Name   string // ex: "zeros", "random"
+	Fill   byte   // octet de remplissage (si Random == false)
+	Random bool   // flux pseudo-aléatoire ChaCha8 (reproductible pour la vérification)
+}
+
+var (
+	PatternZeros  = Pattern{Name: "zeros", Fill: 0x00}
+	PatternOnes   = Pattern{Name: "ones", Fill: 0xFF}
+	PatternRandom = Pattern{Name: "random", Random: true}
+)
+
+// Method est une suite de passes d'écrasement
+type Method struct {
+	Name   string
+	Passes []Pattern
+}
+
+var (
+	MethodZero   = Method{Name: "zero", Passes: []Pattern{PatternZeros}}
+	MethodRandom = Method{Name: "random", Passes: []Pattern{PatternRandom}}
+	// MethodDoD3 : DoD 5220.22-M (E), zéros puis complément puis aléatoire
+	MethodDoD3 = Method{Name: "dod3", Passes: []Pattern{PatternZeros, PatternOnes, PatternRandom}}
+)
+
+// Methods liste les méthodes disponibles par nom
+var Methods = map[string]Method{
+	MethodZero.Name:   MethodZero,
+	MethodRandom.Name: MethodRandom,
+	MethodDoD3.Name:   MethodDoD3,
+}
+
+// Progress décrit l'avancement live d'un effacement
+type Progress struct {
+	Phase       string // "write" ou "verify"
+	Pass        int    // numéro de passe (1..Passes)
+	Passes      int
+	Pattern     string
+	BytesDone   int64 // octets traités dans la phase courante
+	BytesTotal  int64
+	BytesPerSec float64
+	ETA         time.Duration // restant pour la phase courante
+}
+
+// Options paramètre un effacement
+type Options struct {
+	Method     Method
+	Verify     bool           // relecture finale de la dernière passe
+	BlockSize  int            // taille d'écriture, défaut DefaultBlockSize
+	OnProgress func(Progress) // appelé au plus toutes les 500 ms, peut être nil
+}
+
+// PassResult résultat d'une passe d'écriture
+type PassResult struct {
+	Pattern     string
+	Bytes       int64
+	Duration    time.Duration
+	BytesPerSec float64
+}
+
+// WipeResult trace structurée d'un effacement
+type WipeResult struct {
+	Target         string // ex: "/dev/sda" ou chemin de fichier (loopback)
+	SizeBytes      int64
+	Method         string
+	Passes         []PassResult
+	Verified       bool  // true si la vérification a été faite et sans écart
+	VerifyFailures int64 // blocs différents du motif attendu
+	FirstMismatch  int64 // offset du premier écart, -1 si aucun
+	Direct         bool  // E/S O_DIRECT (cache noyau contourné)
+	StartedAt      time.Time
+	EndedAt        time.Time
+	Completed      bool // toutes les passes écrites
+}
+
+// ErrVerifyFailed signale une relecture non conforme
+var ErrVerifyFailed = errors.New("vérification : contenu relu différent du motif écrit")
+
+// Wipe écrase target avec les passes de la méthode puis vérifie la dernière.
+//
+// target est un nom de disque ("sda", refusé s'il est monté, en swap ou
+// tenu par LVM/dm-crypt) ou le chemin d'un fichier ordinaire, ce qui
+// permet de tester le moteur sur un fichier image ou un loop device.
+func Wipe(ctx context.Context, target string, opts Options) (WipeResult, error) {
+	if len(opts.Method.Passes) == 0 {
+		return WipeResult{}, errors.New("méthode d'effacement sans passe")
+	}
+	if opts.BlockSize <= 0 {
+		opts.BlockSize = DefaultBlockSize
+	}
+	opts.BlockSize = alignUp(opts.BlockSize, directAlignment)
+
+	dev, direct, err := openTarget(target)
+	if err != nil {
+		return WipeResult{}, err
+	}
+	defer func() { dev.Close() }() // dev peut être rouvert sans O_DIRECT
+
+	size, err := dev.Size()
+	if err != nil {
+		return WipeResult{}, fmt.Errorf("taille %s: %w", target, err)
+	}
+	if direct && size%int64(directAlign(dev)) != 0 {
+		// Queue non alignée (fichier image) : on renonce à O_DIRECT
+		buffered, err := reopenBuffered(dev)
+		if err != nil {
+			return WipeResult{}, err
+		}
+		dev, direct = buffered, false
+	}
+
+	result := WipeResult{
+		Target:        dev.File().Name(),
+		SizeBytes:     size,
+		Method:        opts.Method.Name,
+		Direct:        direct,
+		FirstMismatch: -1,
+		StartedAt:     time.Now(),
+	}
+	fail := func(err error) (WipeResult, error) {
+		result.EndedAt = time.Now()
+		return result, err
+	}
+
+	buf := blockdev.AlignedBuffer(opts.BlockSize, directAlignment)
+	var lastSeed [32]byte
+
+	for i, pattern := range opts.Method.Passes {
+		var seed [32]byte
+		if pattern.Random {
+			if _, err := crand.Read(seed[:]); err != nil {
+				return fail(fmt.Errorf("graine aléatoire: %w", err))
+			}
+		}
+		lastSeed = seed
+
+		pr, err := writePass(ctx, dev, size, buf, pattern, seed, i+1, opts)
+		result.Passes = append(result.Passes, pr)
+		if err != nil {
+			return fail(fmt.Errorf("passe %d (%s): %w", i+1, pattern.Name, err))
+		}
+	}
+	result.Completed = true
+
+	if opts.Verify {
+		last := opts.Method.Passes[len(opts.Method.Passes)-1]
+		failures, first, err := verifyPass(ctx, dev, size, buf, last, lastSeed, opts)
+		result.VerifyFailures = failures
+		result.FirstMismatch = first
+		if err != nil {
+			return fail(fmt.Errorf("vérification: %w", err))
+		}
+		if failures > 0 {
+			return fail(fmt.Errorf("%w (%d blocs, premier écart à l'offset %d)",
+				ErrVerifyFailed, failures, first))
+		}
+		result.Verified = true
+	}
+
+	result.EndedAt = time.Now()
+	return result, nil
+}
+
+// directAlign est la granularité O_DIRECT : secteur logique pour un
+// périphérique, page pour un fichier image.
+func directAlign(dev *blockdev.Device) int {
+	if info, err := dev.File().Stat(); err == nil && info.Mode().IsRegular() {
+		return directAlignment
+	}
+	return dev.LogicalBlockSize()
+}
+
+// openTarget ouvre un disque (après contrôles de sécurité) ou un fichier.
+func openTarget(target string) (*blockdev.Device, bool, error) {
+	if strings.Contains(target, "/") {
+		info, err := os.Stat(target)
+		if err != nil {
+			return nil, false, err
+		}
+		if !info.Mode().IsRegular() && info.Mode()&os.ModeDevice == 0 {
+			return nil, false, fmt.Errorf("%s n'est ni un fichier ni un périphérique bloc", target)
+		}
+		if info.Mode()&os.ModeDevice != 0 {
+			// /dev/sdX passé par chemin (ou lien /dev/disk/by-id/...) :
+			// mêmes contrôles que par nom, sur le nœud réel
+			name, err := deviceName(target)
+			if err != nil {
+				return nil, false, err
+			}
+			return openTarget(name)
+		}
+		return openFile(target)
+	}
+
+	if !probe.FS().IsHost() {
+		return nil, false, errors.New("refus : effacement impossible en mode rejeu")
+	}
+	if err := CheckNotInUse(target); err != nil {
+		return nil, false, err
+	}
+
+	dev, err := blockdev.Open(target, os.O_RDWR|os.O_EXCL|unix.O_DIRECT)
+	if err != nil {
+		return nil, false, err
+	}
+	return dev, true, nil
+}
+
+// deviceName résout les liens d'un nœud de périphérique et retourne son nom
+// noyau ("sda"). Un nœud hors de /dev n'a pas de nom fiable : refusé.
+func deviceName(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	resolved, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return "", err
+	}
+	if filepath.Dir(resolved) != "/dev" {
+		return "", fmt.Errorf("refus : %s (%s) n'est pas un périphérique de /dev", path, resolved)
+	}
+	return filepath.Base(resolved), nil
+}
+
+func openFile(path string) (*blockdev.Device, bool, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|unix.O_DIRECT, 0)
+	if errors.Is(err, syscall.EINVAL) {
+		// tmpfs et certains FS refusent O_DIRECT
+		f, err = os.OpenFile(path, os.O_RDWR, 0)
+		if err != nil {
+			return nil, false, err
+		}
+		return blockdev.NewDevice(path, f), false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return blockdev.NewDevice(path, f), true, nil
+}
+
+func reopenBuffered(dev *blockdev.Device) (*blockdev.Device, error) {
+	name := dev.File().Name()
+	flag := os.O_RDWR
+	if info, err := dev.File().Stat(); err == nil && info.Mode()&os.ModeDevice != 0 {
+		flag |= os.O_EXCL
+	}
+	dev.Close()
+	f, err := os.OpenFile(name, flag, 0)
+	if err != nil {
+		return nil, err
+	}
+	return blockdev.NewDevice(dev.Name, f), nil
+}
+
+// writePass écrit un motif sur toute la surface puis force l'écriture (fsync).
+func writePass(
+	ctx context.Context,
+	dev *blockdev.Device,
+	size int64,
+	buf []byte,
+	pattern Pattern,
+	seed [32]byte,
+	pass int,
+	opts Options,
+) (PassResult, error) {
+	fill := newFiller(pattern, seed)
+	meter := newMeter("write", pass, len(opts.Method.Passes), pattern.Name, size, opts.OnProgress)
+	f := dev.File()
+
+	var off int64
+	for off < size {
+		if err := ctx.Err(); err != nil {
+			return meter.result(off), err
+		}
+		chunk := buf[:min(int64(len(buf)), size-off)]
+		fill(chunk)
+		n, err := f.WriteAt(chunk, off)
+		off += int64(n)
+		if err != nil {
+			return meter.result(off), fmt.Errorf("écriture offset %d: %w", off, err)
+		}
+		meter.update(off)
+	}
+
+	if err := f.Sync(); err != nil {
+		return meter.result(off), fmt.Errorf("fsync: %w", err)
+	}
+	meter.finish(off)
+	return meter.result(off), nil
+}
+
+// verifyPass relit toute la surface et compare au motif régénéré.
+func verifyPass(
+	ctx context.Context,
+	dev *blockdev.Device,
+	size int64,
+	buf []byte,
+	pattern Pattern,
+	seed [32]byte,
+	opts Options,
+) (int64, int64, error) {
+	fill := newFiller(pattern, seed)
+	expected := make([]byte, len(buf))
+	meter := newMeter("verify", len(opts.Method.Passes), len(opts.Method.Passes), pattern.Name, size, opts.OnProgress)
+	f := dev.File()
+
+	var failures int64
+	first := int64(-1)
+	var off int64
+	for off < size {
+		if err := ctx.Err(); err != nil {
+			return failures, first, err
+		}
+		n := min(int64(len(buf)), size-off)
+		chunk := buf[:n]
+		if _, err := f.ReadAt(chunk, off); err != nil {
+			return failures, first, fmt.Errorf("lecture offset %d: %w", off, err)
+		}
+		want := expected[:n]
+		fill(want)
+		if !bytes.Equal(chunk, want) {
+			failures++
+			if first < 0 {
+				first = off + int64(mismatchIndex(chunk, want))
+			}
+		}
+		off += n
+		meter.update(off)
+	}
+	meter.finish(off)
+	return failures, first, nil
+}
+
+// newFiller retourne un générateur de blocs ; pour l'aléatoire, la même
+// graine régénère exactement le même flux lors de la vérification.
+func newFiller(pattern Pattern, seed [32]byte) func([]byte) {
+	if !pattern.Random {
+		return func(b []byte) {
+			for i := range b {
+				b[i] = pattern.Fill
+			}
+		}
+	}
+	stream := rand.NewChaCha8(seed)
+	return func(b []byte) {
+		stream.Read(b)
+	}
+}
+
+func mismatchIndex(a, b []byte) int {
+	for i := range a {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return 0
+}
+
+func alignUp(n, align int) int {
+	return (n + align - 1) / align * align
+}
+
+// ═══════════════════════════════════════════════════════════════════
+// MESURE DE DÉBIT
+// ═══════════════════════════════════════════════════════════════════
+
+type meter struct {
+	progress Progress
+	start    time.Time
+	last     time.Time
+	notify   func(Progress)
+}
+
+func newMeter(phase string, pass, passes int, pattern string, total int64, notify func(Progress)) *meter {
+	now := time.Now()
+	return &meter{
+		progress: Progress{
+			Phase:      phase,
+			Pass:       pass,
+			Passes:     passes,
+			Pattern:    pattern,
+			BytesTotal: total,
+		},
+		start:  now,
+		last:   now,
+		notify: notify,
+	}
+}
+
+func (m *meter) update(done int64) {
+	if m.notify == nil || time.Since(m.last) < progressInterval {
+		return
+	}
+	m.emit(done)
+}
+
+func (m *meter) finish(done int64) {
+	if m.notify != nil {
+		m.emit(done)
+	}
+}
+
+func (m *meter) emit(done int64) {
+	m.last = time.Now()
+	p := m.progress
+	p.BytesDone = done
+	if elapsed := time.Since(m.start).Seconds(); elapsed > 0 {
+		p.BytesPerSec = float64(done) / elapsed
+	}
+	if p.BytesPerSec > 0 {
+		p.ETA = time.Duration(float64(p.BytesTotal-done) / p.BytesPerSec * float64(time.Second))
+	}
+	m.notify(p)
+}
+
+func (m *meter) result(done int64) PassResult {
+	d := time.Since(m.start)
+	r := PassResult{Pattern: m.progress.Pattern, Bytes: done, Duration: d}
+	if d > 0 {
+		r.BytesPerSec = float64(done) / d.Seconds()
+	}
+	return r
+}
@@ -0,0 +1,126 @@
+package operations
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+const imageSize = 3<<20 + 4096 // queue non multiple du bloc d'écriture
+
+// newImage crée un fichier image rempli d'un motif non nul.
+func newImage(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "disk.img")
+	if err := os.WriteFile(path, bytes.Repeat([]byte{0xA5}, imageSize), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestWipeImageFile(t *testing.T) {
+	tests := []struct {
+		method Method
+		want   byte // contenu attendu après la dernière passe, sauf aléatoire
+	}{
+		{MethodZero, 0x00},
+		{MethodRandom, 0},
+		{MethodDoD3, 0},
+		{Method{Name: "ones", Passes: []Pattern{PatternOnes}}, 0xFF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method.Name, func(t *testing.T) {
+			path := newImage(t)
+			result, err := Wipe(context.Background(), path, Options{
+				Method:    tt.method,
+				Verify:    true,
+				BlockSize: 1 << 20,
+			})
+			if err != nil {
+				t.Fatalf("Wipe: %v", err)
+			}
+			if !result.Completed || !result.Verified || result.VerifyFailures != 0 || result.FirstMismatch != -1 {
+				t.Errorf("result = %+v, want completed and verified", result)
+			}
+			if result.SizeBytes != imageSize || len(result.Passes) != len(tt.method.Passes) {
+				t.Errorf("size %d, %d passes", result.SizeBytes, len(result.Passes))
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			last := tt.method.Passes[len(tt.method.Passes)-1]
+			switch {
+			case last.Random:
+				if bytes.Count(data, []byte{0xA5}) > imageSize/64 {
+					t.Error("random pass left the original pattern in place")
+				}
+			case !bytes.Equal(data, bytes.Repeat([]byte{tt.want}, imageSize)):
+				t.Errorf("image not filled with %#x", tt.want)
+			}
+		})
+	}
+}
+
+func TestWipeCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, err := Wipe(ctx, newImage(t), Options{Method: MethodZero, Verify: true})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if result.Completed || result.Verified {
+		t.Errorf("cancelled wipe reported as completed: %+v", result)
+	}
+}
+
+// TestWipeLoopDevice passe par le chemin des vrais disques : nœud /dev,
+// contrôles de sécurité, O_EXCL et O_DIRECT. Nécessite root et losetup.
+func TestWipeLoopDevice(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("root requis pour attacher un loop device")
+	}
+	path := newImage(t)
+	out, err := exec.Command("losetup", "--find", "--show", path).Output()
+	if err != nil {
+		t.Skipf("losetup indisponible: %v", err)
+	}
+	loop := strings.TrimSpace(string(out))
+	t.Cleanup(func() { exec.Command("losetup", "--detach", loop).Run() })
+
+	result, err := Wipe(context.Background(), loop, Options{Method: MethodDoD3, Verify: true})
+	if err != nil {
+		t.Fatalf("Wipe(%s): %v", loop, err)
+	}
+	if !result.Verified || !result.Direct || result.SizeBytes != imageSize {
+		t.Errorf("result = %+v, want verified O_DIRECT wipe of %d bytes", result, imageSize)
+	}
+}
+
+func TestOpenTargetRejectsDeviceOutsideDev(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("root requis pour mknod")
+	}
+	node := filepath.Join(t.TempDir(), "sda")
+	if err := unix.Mknod(node, unix.S_IFBLK|0o600, int(unix.Mkdev(7, 255))); err != nil {
+		t.Skipf("mknod: %v", err)
+	}
+	link := filepath.Join(t.TempDir(), "disk")
+	if err := os.Symlink(node, link); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, target := range []string{node, link} {
+		if _, _, err := openTarget(target); err == nil || !strings.Contains(err.Error(), "/dev") {
+			t.Errorf("openTarget(%s) = %v, want refusal outside /dev", target, err)
+		}
+	}
+}
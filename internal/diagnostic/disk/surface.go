@@ -0,0 +1,273 @@
+package disk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"gobox/internal/blockdev"
+	"gobox/internal/diagnostic/common"
+	"gobox/internal/probe"
+)
+
+const (
+	DefaultSurfaceChunkSize = 256 << 10 // 256 KiB, comme Victoria
+	surfaceMinSpeed         = 50e6      // octets/s, HDD lent ou clé USB : base de l'estimation de durée
+)
+
+// RunSurfaceScan lit toute la surface du disque par zones de ChunkSize
+// octets, en O_DIRECT, et classe chaque zone selon sa latence. Le scan est
+// en lecture seule, donc sans risque même disque monté.
+//
+// Sur annulation, le résultat partiel est renvoyé avec ctx.Err() : il peut
+// être repassé dans opts.Resume pour reprendre à NextOffset.
+func RunSurfaceScan(ctx context.Context, diskName string, opts SurfaceScanOptions) (SurfaceScanResult, error) {
+	if !probe.FS().IsHost() {
+		return SurfaceScanResult{}, errors.New("scan de surface impossible en mode rejeu")
+	}
+	info, err := probe.GetDiskInfo(diskName)
+	if err != nil {
+		return SurfaceScanResult{}, err
+	}
+
+	result, err := newSurfaceResult(info.Name, info.SizeBytes, opts)
+	if err != nil {
+		return SurfaceScanResult{}, err
+	}
+
+	dev, err := blockdev.Open(diskName, os.O_RDONLY|unix.O_DIRECT)
+	if err != nil {
+		return SurfaceScanResult{}, err
+	}
+	defer dev.Close()
+
+	criteria := DefaultSurfaceGradingCriteria()
+	scanErr := scanSurface(ctx, dev.File(), &result, opts, criteria)
+
+	result.Grade = ComputeSurfaceGrade(criteria, result)
+	result.Issues = DetectSurfaceIssues(result, criteria)
+	return result, scanErr
+}
+
+// ApplySurfaceScan rattache le scan au test disque : la note du disque
+// devient la pire des deux et les problèmes s'ajoutent.
+func ApplySurfaceScan(test *DiskHealthTest, scan SurfaceScanResult) {
+	test.Surface = &scan
+	test.Grade = common.WorseGrade(test.Grade, scan.Grade)
+	test.Issues = append(test.Issues, scan.Issues...)
+}
+
+// EstimateSurfaceDuration borne la durée d'un scan de size octets sur la
+// base d'un débit lent (50 MB/s), pour ne pas couper un HDD en cours.
+func EstimateSurfaceDuration(size int64) time.Duration {
+	return time.Duration(float64(size) / surfaceMinSpeed * float64(time.Second))
+}
+
+// SurfaceStatePath est le fichier d'état par défaut d'un scan, dans le
+// dossier courant, partagé par "gobox surface" et le diagnostic.
+func SurfaceStatePath(diskName string) string {
+	return "gobox-surface-" + diskName + ".json"
+}
+
+// LoadSurfaceState relit un état enregistré par SaveSurfaceState. Un
+// fichier absent n'est pas une erreur : le résultat est alors nil.
+func LoadSurfaceState(path string) (*SurfaceScanResult, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var prev SurfaceScanResult
+	if err := json.Unmarshal(data, &prev); err != nil {
+		return nil, fmt.Errorf("état %s illisible: %w", path, err)
+	}
+	return &prev, nil
+}
+
+// SaveSurfaceState enregistre un scan interrompu pour le reprendre.
+func SaveSurfaceState(path string, result SurfaceScanResult) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// newSurfaceResult prépare un scan neuf ou valide la reprise d'un scan
+// interrompu (même disque, même taille, même découpage).
+func newSurfaceResult(diskName string, size int64, opts SurfaceScanOptions) (SurfaceScanResult, error) {
+	if prev := opts.Resume; prev != nil {
+		if prev.DiskName != diskName || prev.SizeBytes != size {
+			return SurfaceScanResult{}, fmt.Errorf("reprise impossible : le scan portait sur %s (%d octets), pas sur %s (%d octets)",
+				prev.DiskName, prev.SizeBytes, diskName, size)
+		}
+		if err := checkSurfaceState(prev); err != nil {
+			return SurfaceScanResult{}, fmt.Errorf("reprise impossible : %w", err)
+		}
+		return *prev, nil
+	}
+
+	chunk := opts.ChunkSize
+	if chunk <= 0 {
+		chunk = DefaultSurfaceChunkSize
+	}
+	chunk = (chunk + benchAlignment - 1) / benchAlignment * benchAlignment
+	if size <= 0 {
+		return SurfaceScanResult{}, fmt.Errorf("%s : taille nulle", diskName)
+	}
+
+	return SurfaceScanResult{
+		DiskName:  diskName,
+		SizeBytes: size,
+		ChunkSize: chunk,
+		Map:       make([]SurfaceClass, chunkCount(size, chunk)),
+		Histogram: make([]int64, len(SurfaceHistogramBounds)+1),
+		Timestamp: time.Now(),
+	}, nil
+}
+
+// checkSurfaceState vérifie la cohérence d'un état relu : un fichier
+// tronqué ou modifié ferait sortir scanSurface de Map ou de Histogram.
+func checkSurfaceState(r *SurfaceScanResult) error {
+	switch {
+	case r.ChunkSize <= 0 || r.ChunkSize%benchAlignment != 0:
+		return fmt.Errorf("taille de zone %d invalide", r.ChunkSize)
+	case len(r.Map) != chunkCount(r.SizeBytes, r.ChunkSize):
+		return fmt.Errorf("carte de %d zones, %d attendues", len(r.Map), chunkCount(r.SizeBytes, r.ChunkSize))
+	case len(r.Histogram) != len(SurfaceHistogramBounds)+1:
+		return fmt.Errorf("histogramme de %d tranches, %d attendues", len(r.Histogram), len(SurfaceHistogramBounds)+1)
+	case r.NextOffset < 0 || r.NextOffset > r.SizeBytes:
+		return fmt.Errorf("position %d hors du disque", r.NextOffset)
+	case r.NextOffset%int64(r.ChunkSize) != 0 && r.NextOffset != r.SizeBytes:
+		return fmt.Errorf("position %d non alignée sur %d octets", r.NextOffset, r.ChunkSize)
+	}
+	return nil
+}
+
+func chunkCount(size int64, chunk int) int {
+	return int((size + int64(chunk) - 1) / int64(chunk))
+}
+
+// scanSurface lit f de result.NextOffset à la fin et complète result.
+func scanSurface(ctx context.Context, f *os.File, result *SurfaceScanResult, opts SurfaceScanOptions, criteria SurfaceGradingCriteria) error {
+	buf := blockdev.AlignedBuffer(result.ChunkSize, benchAlignment)
+	notify := opts.OnProgress
+	if notify == nil {
+		notify = func(SurfaceScanProgress) {}
+	}
+
+	start := time.Now()
+	resumedAt := result.NextOffset
+	last := start
+	emit := func() {
+		p := SurfaceScanProgress{
+			BytesDone:  result.NextOffset,
+			BytesTotal: result.SizeBytes,
+			Slow:       result.Slow,
+			VerySlow:   result.VerySlow,
+			Unreadable: result.Unreadable,
+		}
+		if elapsed := time.Since(start).Seconds(); elapsed > 0 {
+			p.BytesPerSec = float64(result.NextOffset-resumedAt) / elapsed
+		}
+		if p.BytesPerSec > 0 {
+			p.ETA = time.Duration(float64(result.SizeBytes-result.NextOffset) / p.BytesPerSec * float64(time.Second))
+		}
+		notify(p)
+	}
+	defer func() { result.Duration += time.Since(start) }()
+
+	for result.NextOffset < result.SizeBytes {
+		if err := ctx.Err(); err != nil {
+			emit()
+			return err
+		}
+
+		off := result.NextOffset
+		n := min(int64(len(buf)), result.SizeBytes-off)
+		t := time.Now()
+		_, err := f.ReadAt(buf[:n], off)
+		latency := time.Since(t)
+
+		class := SurfaceUnreadable
+		if err == nil {
+			class = ClassifyLatency(criteria, latency)
+			result.Histogram[histogramBucket(latency)]++
+		}
+		result.record(off, n, class)
+		result.NextOffset = off + n
+
+		if time.Since(last) >= benchTick {
+			last = time.Now()
+			emit()
+		}
+	}
+
+	result.Complete = true
+	emit()
+	return nil
+}
+
+// record inscrit la classe d'une zone dans la carte et les compteurs, et
+// fusionne les zones défectueuses contiguës de même classe.
+func (r *SurfaceScanResult) record(off, n int64, class SurfaceClass) {
+	r.Map[off/int64(r.ChunkSize)] = class
+
+	switch class {
+	case SurfaceSlow:
+		r.Slow++
+	case SurfaceVerySlow:
+		r.VerySlow++
+	case SurfaceUnreadable:
+		r.Unreadable++
+	default:
+		return
+	}
+
+	if k := len(r.Regions) - 1; k >= 0 && r.Regions[k].Class == class &&
+		r.Regions[k].Offset+r.Regions[k].Length == off {
+		r.Regions[k].Length += n
+		return
+	}
+	r.Regions = append(r.Regions, SurfaceRegion{Offset: off, Length: n, Class: class})
+}
+
+func histogramBucket(latency time.Duration) int {
+	for i, bound := range SurfaceHistogramBounds {
+		if latency < bound {
+			return i
+		}
+	}
+	return len(SurfaceHistogramBounds)
+}
+
+// Grid réduit la carte à rows lignes de cols cases pour l'affichage :
+// chaque case porte la pire classe des zones qu'elle couvre.
+func (r *SurfaceScanResult) Grid(cols, rows int) [][]SurfaceClass {
+	if cols <= 0 || rows <= 0 || len(r.Map) == 0 {
+		return nil
+	}
+	cells := cols * rows
+	per := max((len(r.Map)+cells-1)/cells, 1)
+	rows = min(rows, (len(r.Map)+per*cols-1)/(per*cols))
+
+	grid := make([][]SurfaceClass, rows)
+	for row := range grid {
+		grid[row] = make([]SurfaceClass, cols)
+		for col := range grid[row] {
+			lo := (row*cols + col) * per
+			hi := min(lo+per, len(r.Map))
+			for _, class := range r.Map[min(lo, hi):hi] {
+				grid[row][col] = max(grid[row][col], class)
+			}
+		}
+	}
+	return grid
+}
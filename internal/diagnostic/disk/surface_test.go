@@ -0,0 +1,73 @@
+package disk
+
+import "testing"
+
+func TestNewSurfaceResultResume(t *testing.T) {
+	const size = 10<<20 + 512 // dernière zone partielle
+	fresh, err := newSurfaceResult("sda", size, SurfaceScanOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// variant renvoie une copie de fresh modifiée par edit
+	variant := func(edit func(r *SurfaceScanResult)) *SurfaceScanResult {
+		r := fresh
+		r.Map = append([]SurfaceClass(nil), fresh.Map...)
+		r.Histogram = append([]int64(nil), fresh.Histogram...)
+		edit(&r)
+		return &r
+	}
+
+	tests := []struct {
+		name    string
+		resume  *SurfaceScanResult
+		wantErr bool
+	}{
+		{"mid-scan", variant(func(r *SurfaceScanResult) { r.NextOffset = 4 * int64(r.ChunkSize) }), false},
+		{"finished", variant(func(r *SurfaceScanResult) { r.NextOffset = size }), false},
+		{"other disk", variant(func(r *SurfaceScanResult) { r.DiskName = "sdb" }), true},
+		{"other size", variant(func(r *SurfaceScanResult) { r.SizeBytes = size * 2 }), true},
+		{"truncated map", variant(func(r *SurfaceScanResult) { r.Map = r.Map[:1] }), true},
+		{"missing histogram", variant(func(r *SurfaceScanResult) { r.Histogram = nil }), true},
+		{"short histogram", variant(func(r *SurfaceScanResult) { r.Histogram = r.Histogram[:2] }), true},
+		{"negative offset", variant(func(r *SurfaceScanResult) { r.NextOffset = -1 }), true},
+		{"offset past end", variant(func(r *SurfaceScanResult) { r.NextOffset = size + int64(r.ChunkSize) }), true},
+		{"unaligned offset", variant(func(r *SurfaceScanResult) { r.NextOffset = 4096 }), true},
+		{"unaligned chunk", variant(func(r *SurfaceScanResult) {
+			r.ChunkSize = 1000
+			r.Map = make([]SurfaceClass, chunkCount(size, 1000))
+		}), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newSurfaceResult("sda", size, SurfaceScanOptions{Resume: tt.resume})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %t", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSurfaceStateRoundTrip(t *testing.T) {
+	path := t.TempDir() + "/state.json"
+	if prev, err := LoadSurfaceState(path); prev != nil || err != nil {
+		t.Fatalf("missing state = %v, %v; want nil, nil", prev, err)
+	}
+
+	scan, err := newSurfaceResult("sda", 1<<20, SurfaceScanOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	scan.NextOffset = int64(scan.ChunkSize)
+	scan.Histogram[1] = 1
+	if err := SaveSurfaceState(path, scan); err != nil {
+		t.Fatal(err)
+	}
+	prev, err := LoadSurfaceState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newSurfaceResult("sda", 1<<20, SurfaceScanOptions{Resume: prev}); err != nil {
+		t.Errorf("saved state rejected: %v", err)
+	}
+}
@@ -1 +1,608 @@
 package network
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+
+	"gobox/internal/diagnostic/common"
+	"gobox/internal/probe"
+)
+
+const (
+	pathARP = "/proc/net/arp"
+
+	atfComplete         = 0x02             // ATF_COM de /proc/net/arp (net/if_arp.h)
+	udpProbePort        = 33434            // port traceroute : normalement fermé, la passerelle répond « port unreachable »
+	linkRecoveryTimeout = 15 * time.Second // renégociation après un test de câble
+)
+
+// Variables d'environnement lues par ConnectivityOptionsFromEnv
+const (
+	EnvServer   = "GOBOX_NETSERVER"    // ex: "192.168.1.10:8765"
+	EnvResolver = "GOBOX_DNS_RESOLVER" // ex: "192.168.1.1"
+	EnvDNSName  = "GOBOX_DNS_NAME"     // ex: "intranet.local"
+	EnvCable    = "GOBOX_CABLE_TEST"   // "1" : tester aussi les câbles des ports avec lien
+)
+
+func DefaultConnectivityOptions() ConnectivityOptions {
+	return ConnectivityOptions{
+		DNSName:            "example.com",
+		ThroughputDuration: 5 * time.Second,
+		Timeout:            2 * time.Second,
+		StatsWindow:        5 * time.Second,
+	}
+}
+
+// ConnectivityOptionsFromEnv complète les options par défaut avec la
+// configuration du banc (pair netserver, résolveur DNS).
+func ConnectivityOptionsFromEnv() ConnectivityOptions {
+	opts := DefaultConnectivityOptions()
+	opts.Server = os.Getenv(EnvServer)
+	opts.Resolver = os.Getenv(EnvResolver)
+	if name := os.Getenv(EnvDNSName); name != "" {
+		opts.DNSName = name
+	}
+	opts.CableTest = os.Getenv(EnvCable) == "1"
+	return opts
+}
+
+// RunConnectivityTest vérifie chaque interface physique : lien, adresses,
+// passerelle, DNS et, si opts.Server est renseigné, débit vers le pair
+// « gobox netserver ». Toutes les sondes sont liées à l'interface testée.
+func RunConnectivityTest(ctx context.Context, opts ConnectivityOptions, onProgress func(fraction float64, message string)) (ConnectivityResult, error) {
+	if !probe.FS().IsHost() {
+		return ConnectivityResult{}, errors.New("test de connectivité impossible en mode rejeu")
+	}
+	if onProgress == nil {
+		onProgress = func(float64, string) {}
+	}
+	opts = opts.withDefaults()
+
+	ifaces, err := probe.ListNetworkInterfaces()
+	if err != nil {
+		return ConnectivityResult{}, err
+	}
+	ifaces = opts.selectInterfaces(ifaces)
+	if len(ifaces) == 0 {
+		return ConnectivityResult{}, errors.New("aucune interface réseau détectée")
+	}
+
+	criteria := DefaultConnectivityGradingCriteria()
+	result := ConnectivityResult{Timestamp: time.Now()}
+
+	for i, iface := range ifaces {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		step := func(message string) {
+			onProgress(float64(i)/float64(len(ifaces)), iface.Name+" "+message)
+		}
+
+		check := checkInterface(ctx, iface, opts, step)
+		check.Grade = ComputeGrade(criteria, check)
+		check.Issues = append(check.Issues, DetectIssues(check, criteria)...)
+		result.Interfaces = append(result.Interfaces, check)
+
+		if check.Grade != "" {
+			if result.Grade == "" {
+				result.Grade = check.Grade
+			} else {
+				result.Grade = common.WorseGrade(result.Grade, check.Grade)
+			}
+		}
+		for _, issue := range check.Issues {
+			result.Issues = append(result.Issues, iface.Name+": "+issue)
+		}
+	}
+	onProgress(1, "terminé")
+
+	return result, ctx.Err()
+}
+
+// EstimateDuration majore la durée de RunConnectivityTest sur ifaces :
+// scan Wi-Fi, test de câble et renégociation, sondes et débit s'ajoutent
+// d'une interface à l'autre, qui sont testées l'une après l'autre.
+func EstimateDuration(opts ConnectivityOptions, ifaces []probe.NetworkInterface) time.Duration {
+	opts = opts.withDefaults()
+	total := 5 * time.Second // énumération et notation
+	for _, iface := range opts.selectInterfaces(ifaces) {
+		if iface.Type == "wifi" {
+			total += probe.WifiScanTimeout
+		}
+		if iface.Type == "ethernet" && (!iface.Carrier || opts.CableTest) {
+			total += probe.CableTestTimeout
+			if iface.Carrier {
+				total += linkRecoveryTimeout
+			}
+		}
+		if !iface.Carrier {
+			continue
+		}
+		probes := 2 * opts.Timeout // passerelle et DNS
+		if opts.Server != "" {
+			probes += 2*opts.ThroughputDuration + 2*opts.Timeout + 5*time.Second // borne de measureThroughput
+		}
+		total += max(probes, opts.StatsWindow)
+	}
+	return total
+}
+
+// withDefaults complète les durées non renseignées.
+func (opts ConnectivityOptions) withDefaults() ConnectivityOptions {
+	def := DefaultConnectivityOptions()
+	if opts.Timeout <= 0 {
+		opts.Timeout = def.Timeout
+	}
+	if opts.ThroughputDuration <= 0 {
+		opts.ThroughputDuration = def.ThroughputDuration
+	}
+	if opts.StatsWindow <= 0 {
+		opts.StatsWindow = def.StatsWindow
+	}
+	return opts
+}
+
+// selectInterfaces restreint ifaces à opts.Interfaces, si renseigné.
+func (opts ConnectivityOptions) selectInterfaces(ifaces []probe.NetworkInterface) []probe.NetworkInterface {
+	if len(opts.Interfaces) == 0 {
+		return ifaces
+	}
+	return slices.DeleteFunc(slices.Clone(ifaces), func(i probe.NetworkInterface) bool {
+		return !slices.Contains(opts.Interfaces, i.Name)
+	})
+}
+
+// ═══════════════════════════════════════════════════════════════════
+// INTERFACE
+// ═══════════════════════════════════════════════════════════════════
+
+func checkInterface(ctx context.Context, iface probe.NetworkInterface, opts ConnectivityOptions, step func(string)) InterfaceCheck {
+	check := InterfaceCheck{
+		Name:      iface.Name,
+		Model:     iface.Model(),
+		Type:      iface.Type,
+		MAC:       iface.MACAddress,
+		Up:        iface.IsUp,
+		Carrier:   iface.Carrier,
+		Tested:    iface.Carrier,
+		Addresses: iface.Addresses,
+	}
+	for _, gw := range iface.Gateways {
+		if ip := net.ParseIP(gw); ip != nil && ip.To4() != nil {
+			check.Gateway = gw
+			break
+		}
+	}
+
+	if link := iface.Link; link != nil {
+		check.SpeedMbps = link.SpeedMbps
+		check.MaxAdvertiseMbps = link.MaxAdvertise
+		check.MaxPartnerMbps = link.MaxPartner
+		check.Duplex = link.Duplex
+		check.Autoneg = link.Autoneg
+	}
+	if check.SpeedMbps == 0 {
+		// Wi-Fi et pilotes sans ETHTOOL_GLINKSETTINGS
+		check.SpeedMbps, _ = strconv.Atoi(strings.TrimSuffix(iface.Speed, " Mbps"))
+		check.SpeedMbps = max(check.SpeedMbps, 0) // -1 sans lien
+	}
+	check.BelowAdvertised = belowAdvertised(check)
+	if iface.WoL != nil {
+		check.WoLSupported = iface.WoL.Supported
+	}
+
+	if iface.Type == "wifi" {
+		step("scan Wi-Fi")
+		scanWifi(ctx, iface, &check)
+	}
+
+	// Sans lien, le test de câble ne coûte rien ; avec lien il le coupe
+	// quelques secondes et n'est fait qu'à la demande.
+	if iface.Type == "ethernet" && (!check.Tested || opts.CableTest) {
+		step("test du câble")
+		if pairs, err := probe.RunCableTest(ctx, iface.Name); err == nil {
+			check.CablePairs = pairs
+			if check.Tested && !waitCarrier(ctx, iface.Name, linkRecoveryTimeout) {
+				check.Issues = append(check.Issues, "Lien non rétabli après le test de câble")
+				return check
+			}
+		}
+		// Sinon : PHY sans test de câble, ou pas root
+	}
+
+	if !check.Tested {
+		return check
+	}
+	local := firstIPv4(check.Addresses)
+	if local == nil {
+		return check
+	}
+
+	// Les compteurs d'erreurs sont relevés autour des sondes, qui
+	// fournissent le trafic ; la fenêtre est au moins StatsWindow.
+	before, statsErr := probe.ReadNICStats(iface.Name)
+	start := time.Now()
+
+	probeConnectivity(ctx, iface.Name, local, opts, step, &check)
+
+	if statsErr == nil {
+		step("compteurs")
+		if wait := opts.StatsWindow - time.Since(start); wait > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(wait):
+			}
+		}
+		if after, err := probe.ReadNICStats(iface.Name); err == nil {
+			delta := after.Sub(before)
+			check.Stats = &delta
+			check.StatsWindow = time.Since(start)
+		}
+	}
+
+	return check
+}
+
+// probeConnectivity teste passerelle, DNS et débit depuis l'interface.
+func probeConnectivity(ctx context.Context, name string, local net.IP, opts ConnectivityOptions, step func(string), check *InterfaceCheck) {
+	dialer := boundDialer(name, local, opts.Timeout)
+
+	if check.Gateway != "" {
+		step("passerelle")
+		check.GatewayReachable, check.GatewayMethod, check.GatewayRTT = probeGateway(ctx, name, local, check.Gateway, opts.Timeout)
+	}
+
+	if opts.DNSName != "" {
+		step("DNS")
+		check.DNSTested = true
+		start := time.Now()
+		answers, err := resolve(ctx, dialer, opts.Resolver, opts.DNSName, opts.Timeout)
+		check.DNSDuration = time.Since(start)
+		check.DNSOK = err == nil && len(answers) > 0
+		check.DNSAnswers = answers
+	}
+
+	if opts.Server != "" {
+		step("débit")
+		down, up, err := measureThroughput(ctx, dialer, opts.Server, opts.ThroughputDuration)
+		if err == nil {
+			check.ThroughputTested = true
+			check.DownloadMbps = down
+			check.UploadMbps = up
+		} else {
+			check.Issues = append(check.Issues, fmt.Sprintf("Test de débit impossible : %v", err))
+		}
+	}
+}
+
+// waitCarrier attend le retour du lien, par exemple après un test de câble.
+func waitCarrier(ctx context.Context, name string, timeout time.Duration) bool {
+	path := "/sys/class/net/" + name + "/carrier"
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if up, err := probe.FS().ReadBool(path); err == nil && up {
+			return true
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+	return false
+}
+
+// scanWifi vérifie que la radio reçoit : une carte Wi-Fi morte est un
+// défaut courant des machines reconditionnées.
+func scanWifi(ctx context.Context, iface probe.NetworkInterface, check *InterfaceCheck) {
+	if iface.Wifi != nil {
+		check.WifiGeneration = iface.Wifi.Generation
+	}
+	scan, err := probe.ScanWifi(ctx, iface.Name)
+	if err != nil {
+		check.Issues = append(check.Issues, fmt.Sprintf("Scan Wi-Fi impossible : %v", err))
+		return
+	}
+	check.WifiScanned = scan.Triggered || len(scan.BSS) > 0
+	check.WifiNetworks = len(scan.BSS)
+	if len(scan.BSS) > 0 {
+		check.WifiBestSignalDBm = scan.BSS[0].SignalDBm
+	}
+}
+
+func firstIPv4(addrs []string) net.IP {
+	for _, a := range addrs {
+		if ip, _, err := net.ParseCIDR(a); err == nil && ip.To4() != nil {
+			return ip.To4()
+		}
+	}
+	return nil
+}
+
+func hasIPv4(addrs []string) bool {
+	return firstIPv4(addrs) != nil
+}
+
+// boundDialer ouvre des connexions IPv4 depuis l'interface donnée : par
+// SO_BINDTODEVICE si on en a le droit, sinon par l'adresse source (le
+// routage peut alors encore choisir une autre interface).
+func boundDialer(name string, local net.IP, timeout time.Duration) *net.Dialer {
+	return &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, c syscall.RawConn) error {
+			var bindErr error
+			err := c.Control(func(fd uintptr) {
+				if unix.BindToDevice(int(fd), name) == nil {
+					return
+				}
+				sa := &unix.SockaddrInet4{}
+				copy(sa.Addr[:], local)
+				bindErr = unix.Bind(int(fd), sa)
+			})
+			return errors.Join(err, bindErr)
+		},
+	}
+}
+
+// ═══════════════════════════════════════════════════════════════════
+// PASSERELLE
+// ═══════════════════════════════════════════════════════════════════
+
+// probeGateway vérifie que la passerelle répond : ping ICMP si le noyau
+// autorise les sockets ping non privilégiées, sinon sonde UDP vers un port
+// fermé, et en dernier recours entrée ARP résolue.
+func probeGateway(ctx context.Context, name string, local net.IP, gateway string, timeout time.Duration) (bool, string, time.Duration) {
+	gw := net.ParseIP(gateway).To4()
+	if gw == nil {
+		return false, "", 0
+	}
+
+	if rtt, err := pingICMP(name, local, gw, timeout); err == nil {
+		return true, "icmp", rtt
+	}
+
+	dialer := boundDialer(name, local, timeout)
+	if rtt, err := probeUDP(ctx, dialer, gw, timeout); err == nil {
+		return true, "udp", rtt
+	}
+
+	if arpComplete(name, gateway) {
+		return true, "arp", 0
+	}
+	return false, "", 0
+}
+
+// pingICMP envoie un echo request par une socket ping (SOCK_DGRAM,
+// IPPROTO_ICMP), soumise à net.ipv4.ping_group_range.
+func pingICMP(name string, local, dst net.IP, timeout time.Duration) (time.Duration, error) {
+	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, unix.IPPROTO_ICMP)
+	if err != nil {
+		return 0, err
+	}
+	defer unix.Close(fd)
+
+	if unix.BindToDevice(fd, name) != nil {
+		sa := &unix.SockaddrInet4{}
+		copy(sa.Addr[:], local)
+		if err := unix.Bind(fd, sa); err != nil {
+			return 0, err
+		}
+	}
+	tv := unix.NsecToTimeval(timeout.Nanoseconds())
+	if err := unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &tv); err != nil {
+		return 0, err
+	}
+
+	// Echo request : type 8, code 0 ; l'identifiant est imposé par le noyau
+	const seq = 1
+	msg := make([]byte, 16)
+	msg[0] = 8
+	binary.BigEndian.PutUint16(msg[6:], seq)
+	copy(msg[8:], "gobox-gw")
+	binary.BigEndian.PutUint16(msg[2:], icmpChecksum(msg))
+
+	to := &unix.SockaddrInet4{}
+	copy(to.Addr[:], dst)
+	start := time.Now()
+	if err := unix.Sendto(fd, msg, 0, to); err != nil {
+		return 0, err
+	}
+
+	reply := make([]byte, 1500)
+	for {
+		n, from, err := unix.Recvfrom(fd, reply, 0)
+		if err != nil {
+			return 0, err
+		}
+		src, ok := from.(*unix.SockaddrInet4)
+		if ok && net.IP(src.Addr[:]).Equal(dst) && n >= 8 && reply[0] == 0 &&
+			binary.BigEndian.Uint16(reply[6:]) == seq {
+			return time.Since(start), nil
+		}
+		if time.Since(start) >= timeout {
+			return 0, os.ErrDeadlineExceeded
+		}
+	}
+}
+
+func icmpChecksum(b []byte) uint16 {
+	var sum uint32
+	for i := 0; i+1 < len(b); i += 2 {
+		sum += uint32(binary.BigEndian.Uint16(b[i:]))
+	}
+	if len(b)%2 == 1 {
+		sum += uint32(b[len(b)-1]) << 8
+	}
+	for sum>>16 != 0 {
+		sum = sum&0xffff + sum>>16
+	}
+	return ^uint16(sum)
+}
+
+// probeUDP envoie un datagramme vers un port fermé : un refus (ICMP port
+// unreachable) prouve que la passerelle a répondu.
+func probeUDP(ctx context.Context, dialer *net.Dialer, dst net.IP, timeout time.Duration) (time.Duration, error) {
+	conn, err := dialer.DialContext(ctx, "udp4", net.JoinHostPort(dst.String(), strconv.Itoa(udpProbePort)))
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	start := time.Now()
+	conn.SetDeadline(start.Add(timeout))
+	if _, err := conn.Write([]byte("gobox")); err != nil {
+		return 0, err
+	}
+	_, err = conn.Read(make([]byte, 64))
+	switch {
+	case err == nil, errors.Is(err, unix.ECONNREFUSED):
+		return time.Since(start), nil
+	default:
+		return 0, err
+	}
+}
+
+// arpComplete indique si l'adresse a une entrée ARP résolue sur l'interface.
+func arpComplete(name, ip string) bool {
+	data, err := os.ReadFile(pathARP)
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(data), "\n")[1:] {
+		// IP HWtype Flags HWaddress Mask Device
+		fields := strings.Fields(line)
+		if len(fields) < 6 || fields[0] != ip || fields[5] != name {
+			continue
+		}
+		flags, err := strconv.ParseUint(strings.TrimPrefix(fields[2], "0x"), 16, 32)
+		return err == nil && flags&atfComplete != 0
+	}
+	return false
+}
+
+// ═══════════════════════════════════════════════════════════════════
+// DNS
+// ═══════════════════════════════════════════════════════════════════
+
+// resolve résout host par le résolveur donné ("" = ceux de resolv.conf),
+// en passant par l'interface testée.
+func resolve(ctx context.Context, dialer *net.Dialer, server, host string, timeout time.Duration) ([]string, error) {
+	if server != "" {
+		if _, _, err := net.SplitHostPort(server); err != nil {
+			server = net.JoinHostPort(server, "53")
+		}
+	}
+	resolver := &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, address string) (net.Conn, error) {
+			if server != "" {
+				address = server
+			}
+			return dialer.DialContext(ctx, network+"4", address)
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return resolver.LookupHost(ctx, host)
+}
+
+// ═══════════════════════════════════════════════════════════════════
+// DÉBIT
+// ═══════════════════════════════════════════════════════════════════
+
+// measureThroughput mesure le débit descendant puis montant vers un pair
+// « gobox netserver », en Mb/s.
+func measureThroughput(ctx context.Context, dialer *net.Dialer, server string, duration time.Duration) (down, up float64, err error) {
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, address string) (net.Conn, error) {
+				return dialer.DialContext(ctx, "tcp4", address)
+			},
+			DisableCompression: true,
+		},
+	}
+	defer client.CloseIdleConnections()
+	base := "http://" + server
+
+	ctx, cancel := context.WithTimeout(ctx, 2*duration+2*dialer.Timeout+5*time.Second)
+	defer cancel()
+
+	// Descendant
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		fmt.Sprintf("%s/download?seconds=%g", base, duration.Seconds()), nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	n, err := io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return 0, 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, 0, fmt.Errorf("%s : %s", server, resp.Status)
+	}
+	down = mbps(n, time.Since(start))
+
+	// Montant
+	req, err = http.NewRequestWithContext(ctx, http.MethodPost, base+"/upload",
+		&timedReader{deadline: time.Now().Add(duration)})
+	if err != nil {
+		return 0, 0, err
+	}
+	start = time.Now()
+	resp, err = client.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, 0, fmt.Errorf("%s : %s", server, resp.Status)
+	}
+	var stats uploadStats
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return 0, 0, err
+	}
+	up = mbps(stats.Bytes, time.Since(start))
+
+	return down, up, nil
+}
+
+func mbps(bytes int64, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(bytes) * 8 / elapsed.Seconds() / 1e6
+}
+
+// timedReader fournit des zéros jusqu'à deadline.
+type timedReader struct {
+	deadline time.Time
+}
+
+func (r *timedReader) Read(p []byte) (int, error) {
+	if time.Now().After(r.deadline) {
+		return 0, io.EOF
+	}
+	clear(p)
+	return len(p), nil
+}
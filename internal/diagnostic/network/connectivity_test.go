@@ -0,0 +1,48 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"gobox/internal/probe"
+)
+
+func TestEstimateDuration(t *testing.T) {
+	eth := probe.NetworkInterface{Name: "eno1", Type: "ethernet", Carrier: true}
+	ethDown := probe.NetworkInterface{Name: "eno2", Type: "ethernet"}
+	wifi := probe.NetworkInterface{Name: "wlp2s0", Type: "wifi"}
+
+	def := DefaultConnectivityOptions()
+	withServer := def
+	withServer.Server = "192.168.1.10:8765"
+	withCable := def
+	withCable.CableTest = true
+	onlyWifi := def
+	onlyWifi.Interfaces = []string{"wlp2s0"}
+
+	const base = 5 * time.Second
+	probes := max(2*def.Timeout, def.StatsWindow)
+	throughput := 2*def.Timeout + 2*def.ThroughputDuration + 2*def.Timeout + 5*time.Second
+
+	tests := []struct {
+		name   string
+		opts   ConnectivityOptions
+		ifaces []probe.NetworkInterface
+		want   time.Duration
+	}{
+		{"no interface", def, nil, base},
+		{"ethernet with link", def, []probe.NetworkInterface{eth}, base + probes},
+		{"ethernet without link", def, []probe.NetworkInterface{ethDown}, base + probe.CableTestTimeout},
+		{"wifi without link", def, []probe.NetworkInterface{wifi}, base + probe.WifiScanTimeout},
+		{"throughput", withServer, []probe.NetworkInterface{eth}, base + throughput},
+		{"cable test on link", withCable, []probe.NetworkInterface{eth}, base + probe.CableTestTimeout + linkRecoveryTimeout + probes},
+		{"interfaces add up", withServer, []probe.NetworkInterface{eth, ethDown, wifi},
+			base + throughput + probe.CableTestTimeout + probe.WifiScanTimeout},
+		{"filtered", onlyWifi, []probe.NetworkInterface{eth, wifi}, base + probe.WifiScanTimeout},
+	}
+	for _, tt := range tests {
+		if got := EstimateDuration(tt.opts, tt.ifaces); got != tt.want {
+			t.Errorf("%s: EstimateDuration = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package network
+
+import (
+	"fmt"
+	"time"
+
+	"gobox/internal/diagnostic/common"
+	"gobox/internal/probe"
+)
+
+func DefaultConnectivityGradingCriteria() ConnectivityGradingCriteria {
+	return ConnectivityGradingCriteria{
+		BelowAdvertisedMaxGrade: common.GradeC, // 1 Gb/s négocié en 100 Mb/s : câble ou port abîmé
+		NoAddressMaxGrade:       common.GradeC,
+		GatewayMaxGrade:         common.GradeC,
+		DNSMaxGrade:             common.GradeB, // souvent un problème de configuration du banc
+		NoWifiNetworkMaxGrade:   common.GradeF, // un banc a toujours des points d'accès à portée : radio morte
+		MinThroughputForA:       0.7,
+		MinThroughputForB:       0.4,
+		HalfDuplexMaxGrade:      common.GradeC, // le half-duplex trahit un échec d'autonégociation
+		ErrorCountersMaxGrade:   common.GradeC, // CRC sur un lien court : port ou câble défectueux
+		MaxDropRatio:            0.01,
+		DropsMaxGrade:           common.GradeB,
+		CableFaultMaxGrade:      common.GradeC,
+	}
+}
+
+// ComputeGrade note une interface testée. Une interface Ethernet sans lien
+// n'est pas notée : on ne distingue pas un câble absent d'une carte morte.
+// Une carte Wi-Fi non associée est notée sur son scan.
+func ComputeGrade(criteria ConnectivityGradingCriteria, check InterfaceCheck) common.Grade {
+	if !check.Tested && !check.WifiScanned {
+		return ""
+	}
+
+	grade := common.GradeA
+	if check.WifiScanned && check.WifiNetworks == 0 {
+		grade = common.WorseGrade(grade, criteria.NoWifiNetworkMaxGrade)
+	}
+	if len(shortedPairs(check.CablePairs)) > 0 {
+		grade = common.WorseGrade(grade, criteria.CableFaultMaxGrade)
+	}
+	if !check.Tested {
+		return grade
+	}
+	if check.Duplex == "half" {
+		grade = common.WorseGrade(grade, criteria.HalfDuplexMaxGrade)
+	}
+	if s := check.Stats; s != nil {
+		if errorCount(*s) > 0 {
+			grade = common.WorseGrade(grade, criteria.ErrorCountersMaxGrade)
+		}
+		if dropRatio(*s) > criteria.MaxDropRatio {
+			grade = common.WorseGrade(grade, criteria.DropsMaxGrade)
+		}
+	}
+	if check.BelowAdvertised {
+		grade = common.WorseGrade(grade, criteria.BelowAdvertisedMaxGrade)
+	}
+	if !hasIPv4(check.Addresses) {
+		return common.WorseGrade(grade, criteria.NoAddressMaxGrade)
+	}
+	if check.Gateway != "" && !check.GatewayReachable {
+		grade = common.WorseGrade(grade, criteria.GatewayMaxGrade)
+	}
+	if check.DNSTested && !check.DNSOK {
+		grade = common.WorseGrade(grade, criteria.DNSMaxGrade)
+	}
+	if check.ThroughputTested && check.SpeedMbps > 0 {
+		ratio := min(check.DownloadMbps, check.UploadMbps) / float64(check.SpeedMbps)
+		switch {
+		case ratio >= criteria.MinThroughputForA:
+		case ratio >= criteria.MinThroughputForB:
+			grade = common.WorseGrade(grade, common.GradeB)
+		default:
+			grade = common.WorseGrade(grade, common.GradeC)
+		}
+	}
+	return grade
+}
+
+// belowAdvertised indique un lien négocié sous la vitesse de la carte, sauf
+// si l'équipement en face ne propose pas mieux (switch 100 Mb/s du banc).
+func belowAdvertised(check InterfaceCheck) bool {
+	if check.SpeedMbps <= 0 || check.MaxAdvertiseMbps <= 0 {
+		return false
+	}
+	expected := check.MaxAdvertiseMbps
+	if check.MaxPartnerMbps > 0 {
+		expected = min(expected, check.MaxPartnerMbps)
+	}
+	return check.SpeedMbps < expected
+}
+
+func DetectIssues(check InterfaceCheck, criteria ConnectivityGradingCriteria) []string {
+	issues := []string{}
+
+	if check.WifiScanned && check.WifiNetworks == 0 {
+		issues = append(issues, "Aucun réseau Wi-Fi visible : radio ou antennes défectueuses ?")
+	}
+	for _, p := range shortedPairs(check.CablePairs) {
+		issues = append(issues, fmt.Sprintf("Paire %s en court-circuit (%s)%s", p.Pair, p.Status, faultDistance(p)))
+	}
+	if !check.Tested {
+		for _, p := range check.CablePairs {
+			if p.Status == "open" {
+				issues = append(issues, fmt.Sprintf("Paire %s ouverte%s", p.Pair, faultDistance(p)))
+			}
+		}
+		if check.Type == "wifi" {
+			return append(issues, "Wi-Fi non associé : connectivité non testée")
+		}
+		return append(issues, "Aucun lien (câble débranché ?) : connectivité non testée")
+	}
+
+	if check.BelowAdvertised {
+		issues = append(issues, fmt.Sprintf("Lien négocié à %d Mb/s sur une carte %d Mb/s (câble ou port défectueux ?)",
+			check.SpeedMbps, check.MaxAdvertiseMbps))
+	} else if check.MaxPartnerMbps > 0 && check.MaxPartnerMbps < check.MaxAdvertiseMbps {
+		issues = append(issues, fmt.Sprintf("Lien limité à %d Mb/s par l'équipement en face", check.MaxPartnerMbps))
+	}
+	if check.Duplex == "half" {
+		issues = append(issues, "Lien négocié en half-duplex (autonégociation en échec ?)")
+	}
+	if s := check.Stats; s != nil {
+		if n := errorCount(*s); n > 0 {
+			issues = append(issues, fmt.Sprintf("%d erreur(s) de transmission en %s (CRC %d, trames %d, porteuse %d, collisions %d) : port ou câble défectueux",
+				n, check.StatsWindow.Round(time.Second), s.RxCRCErrors, s.RxFrameErrors, s.TxCarrierErrs, s.Collisions))
+		}
+		if r := dropRatio(*s); r > criteria.MaxDropRatio {
+			issues = append(issues, fmt.Sprintf("%.1f %% de paquets perdus pendant le test", r*100))
+		}
+	}
+	if !hasIPv4(check.Addresses) {
+		issues = append(issues, "Aucune adresse IPv4 (DHCP en échec ?)")
+		return issues
+	}
+	switch {
+	case check.Gateway == "":
+		issues = append(issues, "Aucune passerelle par défaut")
+	case !check.GatewayReachable:
+		issues = append(issues, fmt.Sprintf("Passerelle %s injoignable", check.Gateway))
+	}
+	if check.DNSTested && !check.DNSOK {
+		issues = append(issues, "Résolution DNS en échec")
+	}
+	if check.ThroughputTested && check.SpeedMbps > 0 {
+		low := min(check.DownloadMbps, check.UploadMbps)
+		if low < criteria.MinThroughputForA*float64(check.SpeedMbps) {
+			issues = append(issues, fmt.Sprintf("Débit faible : %.0f Mb/s descendant, %.0f Mb/s montant sur un lien %d Mb/s",
+				check.DownloadMbps, check.UploadMbps, check.SpeedMbps))
+		}
+	}
+
+	return issues
+}
+
+// shortedPairs retourne les paires en court-circuit. Une paire ouverte est
+// le plus souvent un câble absent et n'est pas retenue contre la carte.
+func shortedPairs(pairs []probe.CablePair) []probe.CablePair {
+	var out []probe.CablePair
+	for _, p := range pairs {
+		if p.Status == "short" || p.Status == "cross-short" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func faultDistance(p probe.CablePair) string {
+	if p.FaultLengthCM <= 0 {
+		return ""
+	}
+	return fmt.Sprintf(" à %.1f m", float64(p.FaultLengthCM)/100)
+}
+
+// errorCount additionne les compteurs qui ne devraient jamais bouger sur
+// un lien sain. rx_errors et tx_errors les agrègent déjà chez la plupart des
+// pilotes : on prend le plus grand des deux décomptes.
+func errorCount(s probe.NICStats) uint64 {
+	detailed := s.RxCRCErrors + s.RxFrameErrors + s.TxCarrierErrs + s.Collisions
+	return max(detailed, s.RxErrors+s.TxErrors)
+}
+
+func dropRatio(s probe.NICStats) float64 {
+	packets := s.RxPackets + s.TxPackets
+	if packets == 0 {
+		return 0
+	}
+	return float64(s.RxDropped+s.TxDropped+s.RxMissedErrors) / float64(packets)
+}
@@ -0,0 +1,94 @@
+package network
+
+import (
+	"time"
+
+	"gobox/internal/diagnostic/common"
+	"gobox/internal/probe"
+)
+
+// ConnectivityOptions paramètre le test de connectivité
+type ConnectivityOptions struct {
+	Interfaces         []string      // interfaces à tester ; vide = toutes
+	Resolver           string        // serveur DNS "ip[:port]" ; vide = résolveur système
+	DNSName            string        // nom à résoudre, ex: "example.com"
+	Server             string        // pair « gobox netserver » "hôte:port" ; vide = pas de débit
+	ThroughputDuration time.Duration // durée de chaque sens du test de débit
+	Timeout            time.Duration // délai par sonde (passerelle, DNS)
+	StatsWindow        time.Duration // durée minimale d'observation des compteurs d'erreurs
+	CableTest          bool          // tester aussi les câbles des ports avec lien (coupe le lien)
+}
+
+// InterfaceCheck est le bilan d'une interface
+type InterfaceCheck struct {
+	Name    string
+	Model   string // ex: "Intel Ethernet Connection (7) I219-V (e1000e)"
+	Type    string // "ethernet", "wifi"
+	MAC     string
+	Up      bool
+	Carrier bool
+	Tested  bool // lien présent : les sondes ont été lancées
+
+	// Lien (ethtool)
+	SpeedMbps        int // vitesse négociée, 0 si inconnue
+	MaxAdvertiseMbps int // meilleure vitesse annoncée par la carte
+	MaxPartnerMbps   int // meilleure vitesse annoncée en face, 0 si inconnue
+	BelowAdvertised  bool
+	Duplex           string // "full", "half" ou ""
+	Autoneg          bool
+	WoLSupported     string // lettres ethtool, ex: "pumbg" ; vide = pas de Wake-on-LAN
+
+	CablePairs []probe.CablePair // vide si le PHY ne sait pas tester
+
+	Stats       *probe.NICStats // accroissement des compteurs pendant le test
+	StatsWindow time.Duration
+
+	Addresses []string // CIDR, IPv4 et IPv6
+
+	Gateway          string        // passerelle IPv4 par défaut de l'interface
+	GatewayReachable bool          // réponse ICMP, ou port UDP refusé, ou entrée ARP complète
+	GatewayMethod    string        // "icmp", "udp", "arp"
+	GatewayRTT       time.Duration // 0 si non mesuré
+
+	DNSTested   bool
+	DNSOK       bool
+	DNSAnswers  []string
+	DNSDuration time.Duration
+
+	// Wi-Fi (nl80211)
+	WifiGeneration    string  // ex: "Wi-Fi 6E"
+	WifiScanned       bool    // scan déclenché, ou résultats en cache non vides
+	WifiNetworks      int     // points d'accès visibles
+	WifiBestSignalDBm float64 // meilleur signal, 0 si aucun
+
+	ThroughputTested bool
+	DownloadMbps     float64
+	UploadMbps       float64
+
+	Grade  common.Grade // "" si l'interface n'a pas été testée
+	Issues []string
+}
+
+// ConnectivityResult résultat du test de connectivité
+type ConnectivityResult struct {
+	Interfaces []InterfaceCheck
+	Grade      common.Grade // pire note des interfaces testées, "" si aucune
+	Issues     []string
+	Timestamp  time.Time
+}
+
+// ConnectivityGradingCriteria critères de notation réseau
+type ConnectivityGradingCriteria struct {
+	BelowAdvertisedMaxGrade common.Grade // lien négocié sous la vitesse de la carte
+	NoAddressMaxGrade       common.Grade // lien présent mais aucune adresse IPv4 (DHCP)
+	GatewayMaxGrade         common.Grade // passerelle injoignable
+	DNSMaxGrade             common.Grade // résolution en échec
+	NoWifiNetworkMaxGrade   common.Grade // scan Wi-Fi sans aucun point d'accès
+	MinThroughputForA       float64      // fraction de la vitesse du lien, ex: 0.7
+	MinThroughputForB       float64      // ex: 0.4, en dessous = C
+	HalfDuplexMaxGrade      common.Grade // lien négocié en half-duplex
+	ErrorCountersMaxGrade   common.Grade // erreurs CRC, trames, porteuse pendant le test
+	MaxDropRatio            float64      // paquets perdus / reçus au-delà duquel on signale
+	DropsMaxGrade           common.Grade
+	CableFaultMaxGrade      common.Grade // paire en court-circuit
+}
@@ -0,0 +1,92 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// DefaultServerPort est le port d'écoute de « gobox netserver ».
+const DefaultServerPort = 8765
+
+// maxDownloadDuration borne la durée demandée par un client.
+const maxDownloadDuration = time.Minute
+
+// uploadStats est la réponse de /upload
+type uploadStats struct {
+	Bytes int64 `json:"bytes"`
+}
+
+// NewServerHandler est le pair des tests de débit :
+//
+//	GET  /download?seconds=N  envoie des données pendant N secondes
+//	POST /upload              lit le corps et renvoie {"bytes": n}
+//
+// Il permet de tester une carte sur un réseau de banc isolé, sans accès
+// à Internet.
+func NewServerHandler() http.Handler {
+	payload := make([]byte, 64<<10)
+	for i := range payload {
+		payload[i] = byte(rand.IntN(256)) // incompressible
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "gobox netserver\n")
+	})
+	mux.HandleFunc("GET /download", func(w http.ResponseWriter, r *http.Request) {
+		seconds, err := strconv.ParseFloat(r.URL.Query().Get("seconds"), 64)
+		if err != nil || seconds <= 0 {
+			http.Error(w, "paramètre seconds invalide", http.StatusBadRequest)
+			return
+		}
+		duration := min(time.Duration(seconds*float64(time.Second)), maxDownloadDuration)
+
+		w.Header().Set("Content-Type", "application/octet-stream")
+		deadline := time.Now().Add(duration)
+		for time.Now().Before(deadline) {
+			if _, err := w.Write(payload); err != nil {
+				return // client parti
+			}
+		}
+	})
+	mux.HandleFunc("POST /upload", func(w http.ResponseWriter, r *http.Request) {
+		n, err := io.Copy(io.Discard, r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(uploadStats{Bytes: n})
+	})
+	return mux
+}
+
+// Serve écoute sur addr jusqu'à l'annulation de ctx.
+func Serve(ctx context.Context, addr string) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	srv := &http.Server{
+		Handler:           NewServerHandler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+
+	if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package ram
+
+import (
+	"fmt"
+
+	"gobox/internal/diagnostic/common"
+)
+
+func DefaultRAMGradingCriteria() RAMGradingCriteria {
+	return RAMGradingCriteria{
+		MaxSizeDeviation:     0.15, // réservé firmware + iGPU reste en dessous de 15 %
+		SizeMismatchMaxGrade: common.GradeC,
+		MinTestedFraction:    0.25, // moins d'un quart de la RAM testée = résultat partiel
+		PartialMaxGrade:      common.GradeB,
+	}
+}
+
+// ComputeGrade note le test mémoire. Une seule cellule défaillante vaut F :
+// une barrette qui corrompt des données n'est pas revendable.
+func ComputeGrade(criteria RAMGradingCriteria, result RAMTestResult) common.Grade {
+	if result.ErrorCount > 0 {
+		return common.GradeF
+	}
+
+	grade := common.GradeA
+	if result.SizeMismatch {
+		grade = common.WorseGrade(grade, criteria.SizeMismatchMaxGrade)
+	}
+	if result.KernelTotalMB > 0 &&
+		float64(result.TestedMB) < criteria.MinTestedFraction*float64(result.KernelTotalMB) {
+		grade = common.WorseGrade(grade, criteria.PartialMaxGrade)
+	}
+	return grade
+}
+
+// sizeMismatch compare la mémoire vue par le noyau aux barrettes déclarées.
+// Le noyau voit toujours un peu moins (réservations firmware, iGPU) ; un
+// écart plus grand trahit une barrette non reconnue ou un SMBIOS faux.
+func sizeMismatch(criteria RAMGradingCriteria, kernelMB, smbiosMB int) bool {
+	if kernelMB <= 0 || smbiosMB <= 0 {
+		return false
+	}
+	if kernelMB > smbiosMB {
+		return true
+	}
+	return float64(smbiosMB-kernelMB) > criteria.MaxSizeDeviation*float64(smbiosMB)
+}
+
+func DetectIssues(result RAMTestResult, criteria RAMGradingCriteria) []string {
+	issues := []string{}
+
+	if result.ErrorCount > 0 {
+		issues = append(issues, fmt.Sprintf("%d erreur(s) mémoire détectée(s)", result.ErrorCount))
+		for _, f := range result.Failures {
+			addr := fmt.Sprintf("virt %#x", f.VirtAddr)
+			if f.PhysAddr != 0 {
+				addr = fmt.Sprintf("phys %#x", f.PhysAddr)
+			}
+			issues = append(issues, fmt.Sprintf("%s : %s, attendu %#016x, lu %#016x (bits %#x)",
+				f.Pattern, addr, f.Expected, f.Actual, f.FlippedBits))
+		}
+	}
+	if result.SizeMismatch {
+		issues = append(issues, fmt.Sprintf("Mémoire vue par le système (%d MB) incohérente avec les barrettes installées (%d MB)",
+			result.KernelTotalMB, result.SMBIOSTotalMB))
+	}
+	if result.KernelTotalMB > 0 &&
+		float64(result.TestedMB) < criteria.MinTestedFraction*float64(result.KernelTotalMB) {
+		issues = append(issues, fmt.Sprintf("Test partiel : %d MB testés sur %d MB", result.TestedMB, result.KernelTotalMB))
+	}
+	if !result.Locked {
+		issues = append(issues, "Zone de test non verrouillée (mlock refusé) : des pages ont pu être swappées")
+	}
+
+	return issues
+}
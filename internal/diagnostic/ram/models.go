@@ -0,0 +1,60 @@
+package ram
+
+import (
+	"time"
+
+	"gobox/internal/diagnostic/common"
+)
+
+// RAMTestOptions paramètre le test mémoire
+type RAMTestOptions struct {
+	Fraction float64 // part de la mémoire disponible à tester, ex: 0.5
+	SizeMB   int     // taille imposée ; 0 = Fraction × MemAvailable
+	Passes   int     // nombre de passes complètes, ex: 1
+}
+
+// RAMFailure est une cellule dont la relecture diffère de l'écriture
+type RAMFailure struct {
+	Pattern      string
+	Offset       uint64  // octet dans la zone testée
+	VirtAddr     uintptr // adresse virtuelle
+	PhysAddr     uint64  // adresse physique (pagemap, root), 0 si inconnue
+	Expected     uint64
+	Actual       uint64
+	FlippedBits  uint64 // Expected ^ Actual
+	PassNumber   int
+	DetectedTime time.Duration // depuis le début du test
+}
+
+// RAMPatternResult est le bilan d'un motif sur l'ensemble des passes
+type RAMPatternResult struct {
+	Name     string
+	Errors   int64
+	Duration time.Duration
+}
+
+// RAMTestResult résultat du test mémoire
+type RAMTestResult struct {
+	TestedMB      int
+	AvailableMB   int  // MemAvailable au lancement
+	KernelTotalMB int  // MemTotal vu par le noyau
+	SMBIOSTotalMB int  // somme des barrettes (SMBIOS), 0 si illisible
+	SizeMismatch  bool // MemTotal incohérent avec les barrettes installées
+	Locked        bool // zone verrouillée par mlock (pas de swap)
+	Passes        int
+	Patterns      []RAMPatternResult
+	ErrorCount    int64        // total des mots en erreur
+	Failures      []RAMFailure // premières erreurs seulement
+	Duration      time.Duration
+	Grade         common.Grade
+	Issues        []string
+	Timestamp     time.Time
+}
+
+// RAMGradingCriteria critères de notation du test mémoire
+type RAMGradingCriteria struct {
+	MaxSizeDeviation     float64      // écart MemTotal/SMBIOS toléré, ex: 0.15
+	SizeMismatchMaxGrade common.Grade // note plafond si une barrette semble ignorée
+	MinTestedFraction    float64      // en dessous (part de MemTotal), note plafonnée
+	PartialMaxGrade      common.Grade // note plafond d'un test trop partiel
+}
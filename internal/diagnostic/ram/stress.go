@@ -1 +1,491 @@
 package ram
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/bits"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+
+	"gobox/internal/probe"
+)
+
+const (
+	pathMeminfo = "/proc/meminfo"
+	pathPagemap = "/proc/self/pagemap"
+
+	chunkWords  = 1 << 16 // 512 KiB entre deux vérifications d'annulation
+	maxFailures = 64
+)
+
+// DefaultRAMTestOptions : la moitié de la mémoire disponible, une passe.
+func DefaultRAMTestOptions() RAMTestOptions {
+	return RAMTestOptions{
+		Fraction: 0.5,
+		Passes:   1,
+	}
+}
+
+// ═══════════════════════════════════════════════════════════════════
+// MOTIFS
+// ═══════════════════════════════════════════════════════════════════
+
+// pattern est un motif de test ; sweeps est le nombre de parcours complets
+// de la zone qu'il effectue, pour la progression.
+type pattern struct {
+	name   string
+	sweeps int
+	run    func(t *tester) error
+}
+
+var patterns = []pattern{
+	{"stuck-address", 4, stuckAddress},
+	{"random-value", 2, randomValue},
+	{"checkerboard", 4, checkerboard},
+	{"walking-ones", 128, walkingOnes},
+	{"walking-zeros", 128, walkingZeros},
+	{"bit-flip", 192, bitFlip},
+	{"moving-inversions", 8, movingInversions},
+}
+
+// generator remplit buf avec les valeurs attendues des mots lo, lo+1...
+// Les boucles restent dans le générateur : un appel par tranche, pas par mot.
+type generator func(buf []uint64, lo int)
+
+func solid(p uint64) generator {
+	return func(buf []uint64, _ int) {
+		for k := range buf {
+			buf[k] = p
+		}
+	}
+}
+
+// alternating écrit p sur les mots pairs (impairs si phase = 1) et ^p ailleurs.
+func alternating(p uint64, phase int) generator {
+	return func(buf []uint64, lo int) {
+		for k := range buf {
+			if (lo+k)&1 == phase {
+				buf[k] = p
+			} else {
+				buf[k] = ^p
+			}
+		}
+	}
+}
+
+func inverted(gen generator) generator {
+	return func(buf []uint64, lo int) {
+		gen(buf, lo)
+		for k := range buf {
+			buf[k] = ^buf[k]
+		}
+	}
+}
+
+// stuckAddress écrit dans chaque mot sa propre adresse (puis son inverse) :
+// détecte les lignes d'adresse collées ou court-circuitées.
+func stuckAddress(t *tester) error {
+	gen := generator(func(buf []uint64, lo int) {
+		addr := uint64(t.base) + uint64(lo)*8
+		for k := range buf {
+			buf[k] = addr + uint64(k)*8
+		}
+	})
+	for _, g := range []generator{gen, inverted(gen)} {
+		if err := t.write(g); err != nil {
+			return err
+		}
+		if err := t.verify(g); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// randomValue remplit la zone d'une suite pseudo-aléatoire propre à la passe.
+func randomValue(t *tester) error {
+	seed := uint64(time.Now().UnixNano())
+	gen := generator(func(buf []uint64, lo int) {
+		for k := range buf {
+			buf[k] = splitmix64(seed + uint64(lo+k))
+		}
+	})
+	if err := t.write(gen); err != nil {
+		return err
+	}
+	return t.verify(gen)
+}
+
+// checkerboard alterne 0101… et 1010… d'un mot à l'autre, puis inverse.
+func checkerboard(t *tester) error {
+	for phase := range 2 {
+		gen := alternating(0x5555555555555555, phase)
+		if err := t.write(gen); err != nil {
+			return err
+		}
+		if err := t.verify(gen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walkingOnes fait glisser un bit à 1 sur les 64 lignes de données, décalé
+// d'un rang d'un mot au suivant.
+func walkingOnes(t *tester) error {
+	return walking(t, 0)
+}
+
+// walkingZeros est le complément de walkingOnes : un seul bit à 0.
+func walkingZeros(t *tester) error {
+	return walking(t, ^uint64(0))
+}
+
+func walking(t *tester, mask uint64) error {
+	for j := range 64 {
+		gen := generator(func(buf []uint64, lo int) {
+			for k := range buf {
+				buf[k] = bits.RotateLeft64(1, j+lo+k) ^ mask
+			}
+		})
+		if err := t.write(gen); err != nil {
+			return err
+		}
+		if err := t.verify(gen); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bitFlip écrit un bit isolé en alternance avec son inverse, puis bascule
+// toute la zone en place : détecte les cellules qui ne changent pas d'état.
+func bitFlip(t *tester) error {
+	for j := range 64 {
+		gen := alternating(uint64(1)<<j, 0)
+		inverse := inverted(gen)
+		if err := t.write(gen); err != nil {
+			return err
+		}
+		if err := t.verifyAndWrite(gen, inverse, false); err != nil {
+			return err
+		}
+		if err := t.verify(inverse); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// movingInversions est un test « March » : écriture ascendante, puis
+// lecture/inversion ascendante et descendante. Détecte les couplages
+// entre cellules voisines.
+func movingInversions(t *tester) error {
+	for _, p := range []uint64{0, 0x9E3779B97F4A7C15} {
+		if err := t.write(solid(p)); err != nil {
+			return err
+		}
+		if err := t.verifyAndWrite(solid(p), solid(^p), false); err != nil {
+			return err
+		}
+		if err := t.verifyAndWrite(solid(^p), solid(p), true); err != nil {
+			return err
+		}
+		if err := t.verify(solid(p)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func splitmix64(x uint64) uint64 {
+	x += 0x9E3779B97F4A7C15
+	x = (x ^ (x >> 30)) * 0xBF58476D1CE4E5B9
+	x = (x ^ (x >> 27)) * 0x94D049BB133111EB
+	return x ^ (x >> 31)
+}
+
+// ═══════════════════════════════════════════════════════════════════
+// PARCOURS
+// ═══════════════════════════════════════════════════════════════════
+
+// tester parcourt la zone verrouillée mot par mot (64 bits).
+type tester struct {
+	ctx      context.Context
+	words    []uint64
+	base     uintptr
+	expect   []uint64 // valeurs attendues de la tranche courante
+	next     []uint64 // valeurs à écrire après relecture
+	pagemap  *os.File // nil sans root
+	start    time.Time
+	pass     int
+	pattern  string
+	errors   int64
+	result   *RAMTestResult
+	done     int
+	total    int
+	progress func(fraction float64, pattern string)
+}
+
+// chunks appelle fn sur chaque tranche [lo, hi), dans l'ordre demandé, en
+// vérifiant l'annulation entre deux tranches.
+func (t *tester) chunks(descending bool, fn func(lo, hi int)) error {
+	n := len(t.words)
+	count := (n + chunkWords - 1) / chunkWords
+	for c := range count {
+		if err := t.ctx.Err(); err != nil {
+			return err
+		}
+		if descending {
+			c = count - 1 - c
+		}
+		fn(c*chunkWords, min((c+1)*chunkWords, n))
+	}
+	t.done++
+	t.progress(float64(t.done)/float64(t.total), t.pattern)
+	return nil
+}
+
+func (t *tester) write(gen generator) error {
+	return t.chunks(false, func(lo, hi int) {
+		gen(t.words[lo:hi], lo)
+	})
+}
+
+func (t *tester) verify(gen generator) error {
+	return t.chunks(false, func(lo, hi int) {
+		expect := t.expect[:hi-lo]
+		gen(expect, lo)
+		for k, got := range t.words[lo:hi] {
+			if got != expect[k] {
+				t.fail(lo+k, expect[k], got)
+			}
+		}
+	})
+}
+
+// verifyAndWrite relit chaque mot puis y écrit immédiatement sa nouvelle
+// valeur, dans le sens demandé.
+func (t *tester) verifyAndWrite(gen, nextGen generator, descending bool) error {
+	return t.chunks(descending, func(lo, hi int) {
+		expect, next := t.expect[:hi-lo], t.next[:hi-lo]
+		gen(expect, lo)
+		nextGen(next, lo)
+		words := t.words[lo:hi]
+		if descending {
+			for k := len(words) - 1; k >= 0; k-- {
+				if words[k] != expect[k] {
+					t.fail(lo+k, expect[k], words[k])
+				}
+				words[k] = next[k]
+			}
+			return
+		}
+		for k := range words {
+			if words[k] != expect[k] {
+				t.fail(lo+k, expect[k], words[k])
+			}
+			words[k] = next[k]
+		}
+	})
+}
+
+func (t *tester) fail(i int, want, got uint64) {
+	t.errors++
+	t.result.ErrorCount++
+	if len(t.result.Failures) >= maxFailures {
+		return
+	}
+	virt := t.base + uintptr(i)*8
+	t.result.Failures = append(t.result.Failures, RAMFailure{
+		Pattern:      t.pattern,
+		Offset:       uint64(i) * 8,
+		VirtAddr:     virt,
+		PhysAddr:     physAddr(t.pagemap, virt),
+		Expected:     want,
+		Actual:       got,
+		FlippedBits:  want ^ got,
+		PassNumber:   t.pass,
+		DetectedTime: time.Since(t.start),
+	})
+}
+
+// ═══════════════════════════════════════════════════════════════════
+// EXÉCUTION
+// ═══════════════════════════════════════════════════════════════════
+
+// RunRAMTest verrouille une zone de mémoire libre et y déroule les motifs
+// de test. En cas d'annulation, le résultat partiel est renvoyé avec ctx.Err().
+func RunRAMTest(ctx context.Context, opts RAMTestOptions, onProgress func(fraction float64, pattern string)) (RAMTestResult, error) {
+	if !probe.FS().IsHost() {
+		return RAMTestResult{}, errors.New("test mémoire impossible en mode rejeu")
+	}
+	if opts.Passes <= 0 {
+		opts.Passes = 1
+	}
+	if opts.Fraction <= 0 || opts.Fraction > 1 {
+		opts.Fraction = DefaultRAMTestOptions().Fraction
+	}
+	if onProgress == nil {
+		onProgress = func(float64, string) {}
+	}
+
+	totalMB, availableMB, err := readMeminfo()
+	if err != nil {
+		return RAMTestResult{}, err
+	}
+
+	result := RAMTestResult{
+		AvailableMB:   availableMB,
+		KernelTotalMB: totalMB,
+		Passes:        opts.Passes,
+		Timestamp:     time.Now(),
+	}
+	if info, err := probe.GetMemoryInfo(); err == nil {
+		result.SMBIOSTotalMB = info.TotalMB
+	}
+
+	sizeMB := opts.SizeMB
+	if sizeMB <= 0 {
+		sizeMB = int(opts.Fraction * float64(availableMB))
+	}
+	if sizeMB <= 0 || sizeMB > availableMB {
+		return result, fmt.Errorf("taille de test invalide : %d MB (%d MB disponibles)", sizeMB, availableMB)
+	}
+
+	mem, locked, err := allocate(sizeMB << 20)
+	if err != nil {
+		return result, err
+	}
+	defer release(mem, locked)
+
+	result.TestedMB = sizeMB
+	result.Locked = locked
+
+	t := &tester{
+		ctx:      ctx,
+		words:    unsafe.Slice((*uint64)(unsafe.Pointer(&mem[0])), len(mem)/8),
+		base:     uintptr(unsafe.Pointer(&mem[0])),
+		expect:   make([]uint64, chunkWords),
+		next:     make([]uint64, chunkWords),
+		start:    time.Now(),
+		result:   &result,
+		progress: onProgress,
+	}
+	for _, p := range patterns {
+		t.total += p.sweeps * opts.Passes
+	}
+	if f, err := os.Open(pathPagemap); err == nil {
+		t.pagemap = f
+		defer f.Close()
+	}
+
+	result.Patterns = make([]RAMPatternResult, len(patterns))
+	for i, p := range patterns {
+		result.Patterns[i].Name = p.name
+	}
+
+	var runErr error
+run:
+	for pass := range opts.Passes {
+		t.pass = pass + 1
+		for i, p := range patterns {
+			t.pattern, t.errors = p.name, 0
+			start := time.Now()
+			runErr = p.run(t)
+			result.Patterns[i].Errors += t.errors
+			result.Patterns[i].Duration += time.Since(start)
+			if runErr != nil {
+				break run
+			}
+		}
+	}
+	result.Duration = time.Since(t.start)
+
+	if runErr != nil {
+		return result, runErr
+	}
+
+	criteria := DefaultRAMGradingCriteria()
+	result.SizeMismatch = sizeMismatch(criteria, result.KernelTotalMB, result.SMBIOSTotalMB)
+	result.Grade = ComputeGrade(criteria, result)
+	result.Issues = DetectIssues(result, criteria)
+
+	return result, nil
+}
+
+// allocate réserve une zone anonyme hors du tas Go, préchargée, puis tente
+// de la verrouiller en RAM (mlock, root ou RLIMIT_MEMLOCK suffisant).
+func allocate(size int) ([]byte, bool, error) {
+	mem, err := unix.Mmap(-1, 0, size, unix.PROT_READ|unix.PROT_WRITE,
+		unix.MAP_PRIVATE|unix.MAP_ANONYMOUS|unix.MAP_POPULATE)
+	if err != nil {
+		return nil, false, fmt.Errorf("allocation de %d MB: %w", size>>20, err)
+	}
+	locked := unix.Mlock(mem) == nil
+	return mem, locked, nil
+}
+
+func release(mem []byte, locked bool) {
+	if locked {
+		unix.Munlock(mem)
+	}
+	unix.Munmap(mem)
+}
+
+// readMeminfo retourne MemTotal et MemAvailable en MB.
+func readMeminfo() (totalMB, availableMB int, err error) {
+	data, err := probe.FS().ReadFile(pathMeminfo)
+	if err != nil {
+		return 0, 0, fmt.Errorf("lecture %s: %w", pathMeminfo, err)
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		kb, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(value), " kB"))
+		if err != nil {
+			continue
+		}
+		switch key {
+		case "MemTotal":
+			totalMB = kb / 1024
+		case "MemAvailable":
+			availableMB = kb / 1024
+		}
+	}
+	if totalMB == 0 || availableMB == 0 {
+		return 0, 0, fmt.Errorf("%s: MemTotal/MemAvailable absents", pathMeminfo)
+	}
+	return totalMB, availableMB, nil
+}
+
+// physAddr traduit une adresse virtuelle via /proc/self/pagemap. Le noyau
+// masque le PFN sans CAP_SYS_ADMIN : 0 dans ce cas.
+func physAddr(pagemap *os.File, virt uintptr) uint64 {
+	if pagemap == nil {
+		return 0
+	}
+	pageSize := uintptr(os.Getpagesize())
+	var entry [8]byte
+	if _, err := pagemap.ReadAt(entry[:], int64(virt/pageSize)*8); err != nil {
+		return 0
+	}
+	v := binary.LittleEndian.Uint64(entry[:])
+	pfn := v & (1<<55 - 1)
+	if v&(1<<63) == 0 || pfn == 0 {
+		return 0
+	}
+	return pfn*uint64(pageSize) + uint64(virt%pageSize)
+}
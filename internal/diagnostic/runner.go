@@ -1 +1,380 @@
 package diagnostic
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	"gobox/internal/diagnostic/common"
+)
+
+// ═══════════════════════════════════════════════════════════════════
+// CONTRAT
+// ═══════════════════════════════════════════════════════════════════
+
+// Privilege est un droit nécessaire à l'exécution d'un diagnostic
+type Privilege string
+
+const (
+	PrivilegeRoot Privilege = "root" // ioctl disque, mlock, sockets brutes...
+)
+
+// Progress est l'avancement remonté par un diagnostic en cours
+type Progress struct {
+	ID       string  // rempli par le runner
+	Fraction float64 // 0..1, -1 si inconnu
+	Message  string
+}
+
+// ProgressFunc reçoit l'avancement ; elle peut être appelée depuis
+// plusieurs goroutines en mode parallèle.
+type ProgressFunc func(Progress)
+
+// Diagnostic est un test exécutable par le runner
+type Diagnostic interface {
+	ID() string          // identifiant stable (ex: "battery", "disk")
+	Description() string // libellé court
+	Privileges() []Privilege
+	EstimatedDuration() time.Duration
+	// Run exécute le test ; il doit s'arrêter dès que ctx est annulé.
+	Run(ctx context.Context, progress ProgressFunc) (Result, error)
+}
+
+// OptIn est implémenté par les diagnostics exclus du plan par défaut
+// (très longs ou exigeant une manipulation) : ils ne tournent que
+// demandés par leur ID.
+type OptIn interface {
+	OptIn() bool
+}
+
+// IsOptIn indique si d ne tourne que sur demande explicite.
+func IsOptIn(d Diagnostic) bool {
+	o, ok := d.(OptIn)
+	return ok && o.OptIn()
+}
+
+// Offline est implémenté par les diagnostics qui ne font que lire les
+// sondes : ils restent valables contre une archive rejouée (voir capture).
+type Offline interface {
+	Offline() bool
+}
+
+// IsOffline indique si d peut tourner contre une archive rejouée.
+func IsOffline(d Diagnostic) bool {
+	o, ok := d.(Offline)
+	return ok && o.Offline()
+}
+
+// Status est l'issue d'un diagnostic
+type Status string
+
+const (
+	StatusPassed    Status = "passed"    // exécuté, note obtenue
+	StatusError     Status = "error"     // Run a renvoyé une erreur
+	StatusTimeout   Status = "timeout"   // délai dépassé
+	StatusCancelled Status = "cancelled" // plan interrompu
+	StatusPanic     Status = "panic"     // panic récupérée
+	StatusSkipped   Status = "skipped"   // droits insuffisants
+)
+
+// Result est le résultat d'un diagnostic
+type Result struct {
+	ID        string
+	Status    Status       // rempli par le runner
+	Grade     common.Grade // "" si non noté
+	Summary   string       // une ligne lisible
+	Issues    []string
+	Details   any // résultat typé du diagnostic (ex: battery.BatteryHealthTest)
+	Err       error
+	StartedAt time.Time
+	Duration  time.Duration
+}
+
+// Failed indique un diagnostic qui n'a pas pu conclure (erreur, délai,
+// panic) : la machine n'a pas prouvé qu'elle fonctionne.
+func (r Result) Failed() bool {
+	return r.Status == StatusError || r.Status == StatusTimeout || r.Status == StatusPanic
+}
+
+// ResultSet est le résultat consolidé d'un plan
+type ResultSet struct {
+	Results    []Result // dans l'ordre du plan
+	Overall    common.Grade
+	Incomplete bool // au moins un test sauté ou interrompu
+	StartedAt  time.Time
+	EndedAt    time.Time
+}
+
+// ═══════════════════════════════════════════════════════════════════
+// REGISTRE
+// ═══════════════════════════════════════════════════════════════════
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Diagnostic{}
+	order      []string
+)
+
+// Register ajoute un diagnostic au registre. Un ID en double est une
+// erreur de programmation (panic).
+func Register(d Diagnostic) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := registry[d.ID()]; exists {
+		panic(fmt.Sprintf("diagnostic %q déjà enregistré", d.ID()))
+	}
+	registry[d.ID()] = d
+	order = append(order, d.ID())
+}
+
+// Lookup retourne le diagnostic d'ID id.
+func Lookup(id string) (Diagnostic, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	d, ok := registry[id]
+	return d, ok
+}
+
+// All retourne les diagnostics enregistrés, dans l'ordre d'enregistrement.
+func All() []Diagnostic {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	out := make([]Diagnostic, 0, len(order))
+	for _, id := range order {
+		out = append(out, registry[id])
+	}
+	return out
+}
+
+// Plan résout une liste d'IDs ; une liste vide sélectionne tout le
+// registre sauf les diagnostics [OptIn].
+func Plan(ids ...string) ([]Diagnostic, error) {
+	if len(ids) == 0 {
+		var plan []Diagnostic
+		for _, d := range All() {
+			if !IsOptIn(d) {
+				plan = append(plan, d)
+			}
+		}
+		return plan, nil
+	}
+
+	plan := make([]Diagnostic, 0, len(ids))
+	var unknown []string
+	for _, id := range ids {
+		d, ok := Lookup(id)
+		if !ok {
+			unknown = append(unknown, id)
+			continue
+		}
+		plan = append(plan, d)
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("diagnostic(s) inconnu(s) : %v", unknown)
+	}
+	return plan, nil
+}
+
+// ═══════════════════════════════════════════════════════════════════
+// EXÉCUTION
+// ═══════════════════════════════════════════════════════════════════
+
+// Options paramètre l'exécution d'un plan
+type Options struct {
+	Parallel    bool
+	MaxParallel int                      // 0 = pas de limite
+	Timeout     time.Duration            // délai par test ; 0 = 2× l'estimation (min 30 s)
+	Timeouts    map[string]time.Duration // délai spécifique par ID
+	OnProgress  ProgressFunc             // peut être nil
+}
+
+const minTimeout = 30 * time.Second
+
+func isRoot() bool { return os.Geteuid() == 0 }
+
+// Run exécute le plan et consolide les résultats. Il ne renvoie pas
+// d'erreur : chaque échec est porté par le Result du test concerné.
+func Run(ctx context.Context, plan []Diagnostic, opts Options) ResultSet {
+	set := ResultSet{
+		Results:   make([]Result, len(plan)),
+		StartedAt: time.Now(),
+	}
+
+	progress := opts.OnProgress
+	if progress == nil {
+		progress = func(Progress) {}
+	}
+	if opts.Parallel {
+		var mu sync.Mutex
+		user := progress
+		progress = func(p Progress) {
+			mu.Lock()
+			defer mu.Unlock()
+			user(p)
+		}
+	}
+
+	if !opts.Parallel {
+		for i, d := range plan {
+			set.Results[i] = runOne(ctx, d, opts, progress)
+		}
+	} else {
+		limit := opts.MaxParallel
+		if limit <= 0 {
+			limit = len(plan)
+		}
+		sem := make(chan struct{}, max(limit, 1))
+		var wg sync.WaitGroup
+		for i, d := range plan {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				select {
+				case sem <- struct{}{}:
+					defer func() { <-sem }()
+				case <-ctx.Done():
+				}
+				set.Results[i] = runOne(ctx, d, opts, progress)
+			}()
+		}
+		wg.Wait()
+	}
+
+	set.Overall = OverallGrade(set.Results)
+	for _, r := range set.Results {
+		if r.Status == StatusSkipped || r.Status == StatusCancelled {
+			set.Incomplete = true
+		}
+	}
+	set.EndedAt = time.Now()
+	return set
+}
+
+// OverallGrade est la pire note des tests notés, "" si aucun. Un test en
+// échec (erreur, délai, panic) compte pour F.
+func OverallGrade(results []Result) common.Grade {
+	var overall common.Grade
+	for _, r := range results {
+		if r.Failed() {
+			r.Grade = common.GradeF
+		}
+		switch {
+		case r.Grade == "":
+		case overall == "":
+			overall = r.Grade
+		default:
+			overall = common.WorseGrade(overall, r.Grade)
+		}
+	}
+	return overall
+}
+
+// runOne exécute un test dans sa propre goroutine pour pouvoir rendre la
+// main au délai même si Run ignore le contexte.
+func runOne(ctx context.Context, d Diagnostic, opts Options, progress ProgressFunc) Result {
+	id := d.ID()
+	start := time.Now()
+	done := func(r Result) Result {
+		r.ID, r.StartedAt, r.Duration = id, start, time.Since(start)
+		return r
+	}
+
+	if err := ctx.Err(); err != nil {
+		return done(Result{Status: StatusCancelled, Err: err})
+	}
+	if missing := missingPrivileges(d.Privileges()); len(missing) > 0 {
+		return done(Result{
+			Status:  StatusSkipped,
+			Summary: fmt.Sprintf("droits requis : %v", missing),
+		})
+	}
+
+	timeout := timeoutFor(d, opts)
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type outcome struct {
+		result Result
+		err    error
+	}
+	ch := make(chan outcome, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				ch <- outcome{err: &PanicError{Value: r, Stack: debug.Stack()}}
+			}
+		}()
+		result, err := d.Run(runCtx, func(p Progress) {
+			p.ID = id
+			progress(p)
+		})
+		ch <- outcome{result, err}
+	}()
+
+	select {
+	case o := <-ch:
+		r := o.result
+		var panicErr *PanicError
+		switch {
+		case errors.As(o.err, &panicErr):
+			r = Result{Status: StatusPanic, Err: o.err}
+		case o.err != nil && errors.Is(runCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil:
+			r.Status, r.Err = StatusTimeout, o.err
+		case o.err != nil && ctx.Err() != nil:
+			r.Status, r.Err = StatusCancelled, o.err
+		case o.err != nil:
+			r.Status, r.Err = StatusError, o.err
+		default:
+			r.Status = StatusPassed
+		}
+		return done(r)
+
+	case <-runCtx.Done():
+		// Le test n'a pas rendu la main : on l'abandonne
+		if ctx.Err() != nil {
+			return done(Result{Status: StatusCancelled, Err: ctx.Err()})
+		}
+		return done(Result{
+			Status: StatusTimeout,
+			Err:    fmt.Errorf("%s: délai de %s dépassé", id, timeout),
+		})
+	}
+}
+
+func timeoutFor(d Diagnostic, opts Options) time.Duration {
+	if t, ok := opts.Timeouts[d.ID()]; ok && t > 0 {
+		return t
+	}
+	if opts.Timeout > 0 {
+		return opts.Timeout
+	}
+	return max(2*d.EstimatedDuration(), minTimeout)
+}
+
+func missingPrivileges(required []Privilege) []Privilege {
+	var missing []Privilege
+	for _, p := range required {
+		if p == PrivilegeRoot && !isRoot() {
+			missing = append(missing, p)
+		}
+	}
+	return missing
+}
+
+// PanicError enveloppe une panic récupérée pendant un diagnostic
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
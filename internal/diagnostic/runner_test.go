@@ -0,0 +1,314 @@
+package diagnostic
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"gobox/internal/diagnostic/common"
+)
+
+// fakeDiagnostic délègue Run à une fonction fournie par le test.
+type fakeDiagnostic struct {
+	id         string
+	privileges []Privilege
+	run        func(ctx context.Context, progress ProgressFunc) (Result, error)
+}
+
+func (f fakeDiagnostic) ID() string                       { return f.id }
+func (f fakeDiagnostic) Description() string              { return "test " + f.id }
+func (f fakeDiagnostic) Privileges() []Privilege          { return f.privileges }
+func (f fakeDiagnostic) EstimatedDuration() time.Duration { return time.Second }
+func (f fakeDiagnostic) Run(ctx context.Context, progress ProgressFunc) (Result, error) {
+	return f.run(ctx, progress)
+}
+
+func graded(g common.Grade) func(context.Context, ProgressFunc) (Result, error) {
+	return func(context.Context, ProgressFunc) (Result, error) {
+		return Result{Grade: g, Summary: "ok"}, nil
+	}
+}
+
+// honorsCtx bloque jusqu'à l'annulation de son contexte.
+func honorsCtx(ctx context.Context, _ ProgressFunc) (Result, error) {
+	<-ctx.Done()
+	return Result{}, ctx.Err()
+}
+
+// ignoresCtx bloque jusqu'à la fin du test, quel que soit son contexte :
+// le runner doit l'abandonner sans attendre.
+func ignoresCtx(t *testing.T) func(context.Context, ProgressFunc) (Result, error) {
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+	return func(context.Context, ProgressFunc) (Result, error) {
+		<-release
+		return Result{Grade: common.GradeA}, nil
+	}
+}
+
+func TestRunStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		run       func(context.Context, ProgressFunc) (Result, error)
+		root      bool // nécessite les droits root
+		wantState Status
+		wantGrade common.Grade
+	}{
+		{name: "passed", run: graded(common.GradeB), wantState: StatusPassed, wantGrade: common.GradeB},
+		{
+			name: "error",
+			run: func(context.Context, ProgressFunc) (Result, error) {
+				return Result{Summary: "lecture impossible"}, errors.New("EIO")
+			},
+			wantState: StatusError,
+		},
+		{name: "timeout honoring ctx", run: honorsCtx, wantState: StatusTimeout},
+		{name: "timeout ignoring ctx", run: ignoresCtx(t), wantState: StatusTimeout},
+		{
+			name: "panic",
+			run: func(context.Context, ProgressFunc) (Result, error) {
+				var m map[string]int
+				m["boom"]++
+				return Result{}, nil
+			},
+			wantState: StatusPanic,
+		},
+		{name: "missing privileges", run: graded(common.GradeA), root: true, wantState: StatusSkipped},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.root && isRoot() {
+				t.Skip("exécuté en root : aucun droit ne manque")
+			}
+			d := fakeDiagnostic{id: "fake", run: tt.run}
+			if tt.root {
+				d.privileges = []Privilege{PrivilegeRoot}
+			}
+
+			start := time.Now()
+			set := Run(context.Background(), []Diagnostic{d}, Options{Timeout: 50 * time.Millisecond})
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Fatalf("Run a attendu %s", elapsed)
+			}
+
+			r := set.Results[0]
+			if r.ID != "fake" {
+				t.Errorf("ID = %q, want %q", r.ID, "fake")
+			}
+			if r.Status != tt.wantState {
+				t.Errorf("Status = %s, want %s (err %v)", r.Status, tt.wantState, r.Err)
+			}
+			if r.Grade != tt.wantGrade {
+				t.Errorf("Grade = %q, want %q", r.Grade, tt.wantGrade)
+			}
+			if r.Failed() != (r.Err != nil && r.Status != StatusCancelled) {
+				t.Errorf("Failed() = %v with status %s", r.Failed(), r.Status)
+			}
+			if r.Failed() && set.Overall != common.GradeF {
+				t.Errorf("Overall = %q, want F for a failed test", set.Overall)
+			}
+			if got := tt.wantState == StatusSkipped; set.Incomplete != got {
+				t.Errorf("Incomplete = %v, want %v", set.Incomplete, got)
+			}
+		})
+	}
+}
+
+func TestRunPanicKeepsStack(t *testing.T) {
+	d := fakeDiagnostic{id: "boom", run: func(context.Context, ProgressFunc) (Result, error) {
+		panic("capteur absent")
+	}}
+	r := Run(context.Background(), []Diagnostic{d}, Options{}).Results[0]
+
+	var panicErr *PanicError
+	if !errors.As(r.Err, &panicErr) {
+		t.Fatalf("Err = %v, want *PanicError", r.Err)
+	}
+	if panicErr.Value != "capteur absent" || len(panicErr.Stack) == 0 {
+		t.Errorf("PanicError = %q, stack de %d octets", panicErr.Value, len(panicErr.Stack))
+	}
+}
+
+func TestRunTimeoutPerID(t *testing.T) {
+	plan := []Diagnostic{
+		fakeDiagnostic{id: "short", run: honorsCtx},
+		fakeDiagnostic{id: "long", run: func(ctx context.Context, _ ProgressFunc) (Result, error) {
+			select {
+			case <-ctx.Done():
+				return Result{}, ctx.Err()
+			case <-time.After(100 * time.Millisecond):
+				return Result{Grade: common.GradeA}, nil
+			}
+		}},
+	}
+	set := Run(context.Background(), plan, Options{
+		Timeout:  20 * time.Millisecond,
+		Timeouts: map[string]time.Duration{"long": 5 * time.Second},
+	})
+	if got := set.Results[0].Status; got != StatusTimeout {
+		t.Errorf("short: Status = %s, want %s", got, StatusTimeout)
+	}
+	if got := set.Results[1].Status; got != StatusPassed {
+		t.Errorf("long: Status = %s, want %s (err %v)", got, StatusPassed, set.Results[1].Err)
+	}
+}
+
+func TestRunCancellation(t *testing.T) {
+	for _, parallel := range []bool{false, true} {
+		name := "sequential"
+		if parallel {
+			name = "parallel"
+		}
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			// Le premier test démarré, quel qu'il soit en parallèle, annule
+			// le plan ; MaxParallel 1 : les autres attendent leur tour et
+			// ne démarrent jamais.
+			var once sync.Once
+			first := func(run func(context.Context, ProgressFunc) (Result, error)) func(context.Context, ProgressFunc) (Result, error) {
+				return func(ctx context.Context, p ProgressFunc) (Result, error) {
+					once.Do(cancel)
+					return run(ctx, p)
+				}
+			}
+			plan := []Diagnostic{
+				fakeDiagnostic{id: "blocking", run: first(ignoresCtx(t))},
+				fakeDiagnostic{id: "honoring", run: first(honorsCtx)},
+				fakeDiagnostic{id: "next", run: first(ignoresCtx(t))},
+			}
+
+			set := Run(ctx, plan, Options{Parallel: parallel, MaxParallel: 1, Timeout: time.Minute})
+			for _, r := range set.Results {
+				if r.Status != StatusCancelled {
+					t.Errorf("%s: Status = %s, want %s", r.ID, r.Status, StatusCancelled)
+				}
+				if r.Failed() {
+					t.Errorf("%s: une annulation ne compte pas comme un échec", r.ID)
+				}
+			}
+			if !set.Incomplete {
+				t.Error("Incomplete = false, want true")
+			}
+			if set.Overall != "" {
+				t.Errorf("Overall = %q, want none", set.Overall)
+			}
+		})
+	}
+}
+
+func TestRunParallelProgress(t *testing.T) {
+	var mu sync.Mutex
+	var seen []string
+	run := func(_ context.Context, progress ProgressFunc) (Result, error) {
+		progress(Progress{Fraction: 1, Message: "terminé"})
+		return Result{Grade: common.GradeA}, nil
+	}
+	plan := []Diagnostic{
+		fakeDiagnostic{id: "a", run: run},
+		fakeDiagnostic{id: "b", run: run},
+		fakeDiagnostic{id: "c", run: run},
+	}
+
+	set := Run(context.Background(), plan, Options{
+		Parallel:    true,
+		MaxParallel: 2,
+		OnProgress: func(p Progress) {
+			mu.Lock()
+			defer mu.Unlock()
+			seen = append(seen, p.ID)
+		},
+	})
+
+	// Résultats dans l'ordre du plan, progression attribuée par le runner
+	for i, r := range set.Results {
+		if r.ID != plan[i].ID() || r.Status != StatusPassed {
+			t.Errorf("Results[%d] = %s %s, want %s passed", i, r.ID, r.Status, plan[i].ID())
+		}
+	}
+	slices.Sort(seen)
+	if !slices.Equal(seen, []string{"a", "b", "c"}) {
+		t.Errorf("progress IDs = %v", seen)
+	}
+	if set.Overall != common.GradeA || set.Incomplete {
+		t.Errorf("Overall = %q, Incomplete = %v", set.Overall, set.Incomplete)
+	}
+}
+
+func TestPlan(t *testing.T) {
+	ids := func(plan []Diagnostic) []string {
+		var out []string
+		for _, d := range plan {
+			out = append(out, d.ID())
+		}
+		return out
+	}
+
+	plan, err := Plan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan) == 0 {
+		t.Fatal("plan par défaut vide")
+	}
+	for _, d := range plan {
+		if IsOptIn(d) {
+			t.Errorf("%s est opt-in mais figure dans le plan par défaut", d.ID())
+		}
+	}
+	if got := len(plan); got >= len(All()) {
+		t.Errorf("plan par défaut de %d tests, registre de %d : aucun opt-in exclu", got, len(All()))
+	}
+
+	// Demandé explicitement, un opt-in est retenu, dans l'ordre demandé
+	plan, err = Plan("disk-surface", "battery")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(plan); !slices.Equal(got, []string{"disk-surface", "battery"}) {
+		t.Errorf("Plan = %v", got)
+	}
+
+	if _, err := Plan("battery", "flux-capacitor"); err == nil {
+		t.Error("Plan: want error for an unknown ID")
+	}
+}
+
+func TestOverallGrade(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []Result
+		want    common.Grade
+	}{
+		{name: "empty", want: ""},
+		{
+			name:    "ungraded only",
+			results: []Result{{Status: StatusPassed}, {Status: StatusSkipped}, {Status: StatusCancelled}},
+			want:    "",
+		},
+		{
+			name:    "worst grade",
+			results: []Result{{Status: StatusPassed, Grade: common.GradeA}, {Status: StatusPassed, Grade: common.GradeC}},
+			want:    common.GradeC,
+		},
+		{
+			name:    "skipped does not lower",
+			results: []Result{{Status: StatusPassed, Grade: common.GradeB}, {Status: StatusSkipped}},
+			want:    common.GradeB,
+		},
+		{name: "error counts as F", results: []Result{{Status: StatusPassed, Grade: common.GradeA}, {Status: StatusError}}, want: common.GradeF},
+		{name: "timeout counts as F", results: []Result{{Status: StatusTimeout, Grade: common.GradeA}}, want: common.GradeF},
+		{name: "panic counts as F", results: []Result{{Status: StatusPanic}}, want: common.GradeF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := OverallGrade(tt.results); got != tt.want {
+				t.Errorf("OverallGrade = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
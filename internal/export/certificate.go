@@ -0,0 +1,449 @@
+package export
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"gobox/internal/diagnostic/disk/operations"
+	"gobox/internal/probe"
+)
+
+// CertificateVersion est la version du format de certificat d'effacement.
+const CertificateVersion = 1
+
+// Catégories NIST SP 800-88 Rev. 1.
+const (
+	SanitizeClear = "Clear" // écrasement logique des secteurs adressables
+	SanitizePurge = "Purge" // commande firmware (zones réservées incluses)
+)
+
+const (
+	certStandard   = "NIST SP 800-88 Rev. 1"
+	signatureAlgo  = "ed25519"
+	pemKeyType     = "GOBOX ED25519 PRIVATE KEY"
+	keyFileMode    = 0o600
+	keyDirFileMode = 0o700
+)
+
+var (
+	// ErrNoSignature signale un certificat sans bloc de signature
+	ErrNoSignature = errors.New("certificat non signé")
+	// ErrBadSignature signale un certificat modifié après signature
+	ErrBadSignature = errors.New("signature invalide : certificat altéré ou clé différente")
+	// ErrUntrustedKey signale une signature valide par une clé non attendue
+	ErrUntrustedKey = errors.New("signature valide mais clé publique non reconnue")
+	// ErrErasureFailed signale un certificat authentique qui n'atteste pas
+	// un effacement réussi (passes incomplètes ou relecture non conforme)
+	ErrErasureFailed = errors.New("certificat authentique mais effacement non conforme")
+)
+
+// ErasureCertificate atteste l'effacement d'un disque identifié par son
+// numéro de série. Tous les champs hors Signature sont signés.
+type ErasureCertificate struct {
+	Version      int                `json:"version"`
+	ID           string             `json:"id"`
+	Standard     string             `json:"standard"`
+	Sanitization string             `json:"sanitization"` // SanitizeClear ou SanitizePurge
+	IssuedAt     time.Time          `json:"issued_at"`
+	Operator     string             `json:"operator"`
+	Tool         string             `json:"tool"`
+	System       CertificateSystem  `json:"system"`
+	Disk         CertificateDisk    `json:"disk"`
+	Erasure      CertificateErasure `json:"erasure"`
+	Signature    *Signature         `json:"signature,omitempty"`
+}
+
+// CertificateSystem identifie la machine hôte du disque
+type CertificateSystem struct {
+	Manufacturer string `json:"manufacturer,omitempty"`
+	Product      string `json:"product,omitempty"`
+	Version      string `json:"version,omitempty"`
+	Serial       string `json:"serial,omitempty"`
+	UUID         string `json:"uuid,omitempty"`
+	ChassisType  string `json:"chassis_type,omitempty"`
+}
+
+// CertificateDisk identifie le support effacé
+type CertificateDisk struct {
+	Name      string `json:"name"`
+	Vendor    string `json:"vendor,omitempty"`
+	Model     string `json:"model,omitempty"`
+	Serial    string `json:"serial,omitempty"`
+	Type      string `json:"type,omitempty"` // "SSD" ou "HDD"
+	SizeBytes int64  `json:"size_bytes"`
+}
+
+// CertificateErasure décrit l'opération et son résultat
+type CertificateErasure struct {
+	Technique    string            `json:"technique"` // "overwrite" ou "firmware"
+	Method       string            `json:"method"`
+	Passes       []CertificatePass `json:"passes,omitempty"`
+	Verification string            `json:"verification"` // VerificationPassed...
+	VerifyErrors int64             `json:"verify_errors,omitempty"`
+	StartedAt    time.Time         `json:"started_at"`
+	EndedAt      time.Time         `json:"ended_at"`
+	Completed    bool              `json:"completed"`
+}
+
+// CertificatePass détaille une passe d'écrasement
+type CertificatePass struct {
+	Pattern     string  `json:"pattern"`
+	Bytes       int64   `json:"bytes"`
+	DurationSec float64 `json:"duration_sec"`
+}
+
+// Résultats de vérification possibles.
+const (
+	VerificationPassed   = "passed"
+	VerificationFailed   = "failed"
+	VerificationSkipped  = "not performed"
+	VerificationFirmware = "firmware completion status"
+)
+
+// Signature est la signature Ed25519 du certificat sans ce bloc
+type Signature struct {
+	Algorithm   string `json:"algorithm"`
+	PublicKey   string `json:"public_key"`  // base64
+	Fingerprint string `json:"fingerprint"` // SHA-256 de la clé publique, hex
+	Value       string `json:"value"`       // base64
+}
+
+// NewWipeCertificate construit le certificat d'un écrasement logiciel.
+func NewWipeCertificate(system probe.SystemInfo, disk probe.DiskInfo, result operations.WipeResult, operator string) *ErasureCertificate {
+	cert := newCertificate(system, disk, operator, SanitizeClear)
+
+	verification := VerificationSkipped
+	switch {
+	case result.Verified:
+		verification = VerificationPassed
+	case result.VerifyFailures > 0:
+		verification = VerificationFailed
+	}
+
+	cert.Erasure = CertificateErasure{
+		Technique:    "overwrite",
+		Method:       result.Method,
+		Verification: verification,
+		VerifyErrors: result.VerifyFailures,
+		StartedAt:    result.StartedAt.UTC(),
+		EndedAt:      result.EndedAt.UTC(),
+		Completed:    result.Completed,
+	}
+	for _, pass := range result.Passes {
+		cert.Erasure.Passes = append(cert.Erasure.Passes, CertificatePass{
+			Pattern:     pass.Pattern,
+			Bytes:       pass.Bytes,
+			DurationSec: pass.Duration.Seconds(),
+		})
+	}
+	if cert.Disk.SizeBytes == 0 {
+		cert.Disk.SizeBytes = result.SizeBytes
+	}
+	return cert
+}
+
+// NewFirmwareCertificate construit le certificat d'un effacement matériel.
+func NewFirmwareCertificate(system probe.SystemInfo, disk probe.DiskInfo, result operations.FirmwareResult, operator string) *ErasureCertificate {
+	cert := newCertificate(system, disk, operator, SanitizePurge)
+	cert.Erasure = CertificateErasure{
+		Technique:    "firmware",
+		Method:       string(result.Method),
+		Verification: VerificationFirmware,
+		StartedAt:    result.StartedAt.UTC(),
+		EndedAt:      result.EndedAt.UTC(),
+		Completed:    result.Completed,
+	}
+	return cert
+}
+
+func newCertificate(system probe.SystemInfo, disk probe.DiskInfo, operator, sanitization string) *ErasureCertificate {
+	return &ErasureCertificate{
+		Version:      CertificateVersion,
+		ID:           newCertificateID(),
+		Standard:     certStandard,
+		Sanitization: sanitization,
+		IssuedAt:     time.Now().UTC().Truncate(time.Second),
+		Operator:     operator,
+		Tool:         "gobox",
+		System: CertificateSystem{
+			Manufacturer: system.Manufacturer,
+			Product:      system.Product,
+			Version:      system.Version,
+			Serial:       system.Serial,
+			UUID:         system.UUID,
+			ChassisType:  system.ChassisType,
+		},
+		Disk: CertificateDisk{
+			Name:      disk.Name,
+			Vendor:    disk.Vendor,
+			Model:     disk.Model,
+			Serial:    disk.Serial,
+			Type:      disk.Type,
+			SizeBytes: disk.SizeBytes,
+		},
+	}
+}
+
+// newCertificateID génère un UUID v4.
+func newCertificateID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0F | 0x40
+	b[8] = b[8]&0x3F | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// ═══════════════════════════════════════════════════════════════════
+// SIGNATURE
+// ═══════════════════════════════════════════════════════════════════
+
+// signedPayload est l'encodage JSON compact du certificat sans signature.
+func (c *ErasureCertificate) signedPayload() ([]byte, error) {
+	unsigned := *c
+	unsigned.Signature = nil
+	return json.Marshal(unsigned)
+}
+
+// Sign signe le certificat avec la clé privée Ed25519.
+func (c *ErasureCertificate) Sign(key ed25519.PrivateKey) error {
+	payload, err := c.signedPayload()
+	if err != nil {
+		return err
+	}
+	pub := key.Public().(ed25519.PublicKey)
+	c.Signature = &Signature{
+		Algorithm:   signatureAlgo,
+		PublicKey:   EncodePublicKey(pub),
+		Fingerprint: KeyFingerprint(pub),
+		Value:       base64.StdEncoding.EncodeToString(ed25519.Sign(key, payload)),
+	}
+	return nil
+}
+
+// Verify contrôle la signature. Si trusted n'est pas vide, la clé du
+// certificat doit en faire partie (sinon ErrUntrustedKey) ; sans clé de
+// confiance, seule l'intégrité du document est garantie. Un certificat
+// intègre qui ne constate pas un effacement complet et vérifié renvoie
+// ErrErasureFailed.
+func (c *ErasureCertificate) Verify(trusted ...ed25519.PublicKey) error {
+	if c.Signature == nil {
+		return ErrNoSignature
+	}
+	if c.Signature.Algorithm != signatureAlgo {
+		return fmt.Errorf("algorithme de signature inconnu %q", c.Signature.Algorithm)
+	}
+
+	pub, err := base64.StdEncoding.DecodeString(c.Signature.PublicKey)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return fmt.Errorf("clé publique invalide dans le certificat")
+	}
+	sig, err := base64.StdEncoding.DecodeString(c.Signature.Value)
+	if err != nil {
+		return fmt.Errorf("signature mal encodée: %w", err)
+	}
+	payload, err := c.signedPayload()
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(pub, payload, sig) {
+		return ErrBadSignature
+	}
+
+	if len(trusted) > 0 && !slices.ContainsFunc(trusted, func(key ed25519.PublicKey) bool {
+		return key.Equal(ed25519.PublicKey(pub))
+	}) {
+		return fmt.Errorf("%w (empreinte %s)", ErrUntrustedKey, KeyFingerprint(pub))
+	}
+	if !c.Succeeded() {
+		return fmt.Errorf("%w (vérification : %s)", ErrErasureFailed, c.Erasure.Verification)
+	}
+	return nil
+}
+
+// Succeeded indique un effacement terminé dont la relecture n'a pas
+// trouvé d'écart.
+func (c *ErasureCertificate) Succeeded() bool {
+	return c.Erasure.Completed && c.Erasure.Verification != VerificationFailed
+}
+
+// KeyFingerprint retourne l'empreinte SHA-256 (hex) d'une clé publique.
+func KeyFingerprint(pub ed25519.PublicKey) string {
+	sum := sha256.Sum256(pub)
+	return hex.EncodeToString(sum[:])
+}
+
+// LoadOrCreateSigningKey lit la clé privée PEM de path, ou la génère
+// (fichier 0600) si elle n'existe pas encore.
+func LoadOrCreateSigningKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		block, _ := pem.Decode(data)
+		if block == nil || block.Type != pemKeyType || len(block.Bytes) != ed25519.SeedSize {
+			return nil, fmt.Errorf("clé de signature invalide : %s", path)
+		}
+		return ed25519.NewKeyFromSeed(block.Bytes), nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("lecture clé %s: %w", path, err)
+	}
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), keyDirFileMode); err != nil {
+		return nil, err
+	}
+	encoded := pem.EncodeToMemory(&pem.Block{Type: pemKeyType, Bytes: key.Seed()})
+	if err := os.WriteFile(path, encoded, keyFileMode); err != nil {
+		return nil, fmt.Errorf("écriture clé %s: %w", path, err)
+	}
+	return key, nil
+}
+
+// DefaultSigningKeyPath est ~/.config/gobox/signing.key (ou $XDG_CONFIG_HOME).
+func DefaultSigningKeyPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = "."
+	}
+	return filepath.Join(dir, "gobox", "signing.key")
+}
+
+// EncodePublicKey encode une clé publique en base64, le format du champ
+// signature.public_key et des fichiers lus par ReadPublicKey.
+func EncodePublicKey(pub ed25519.PublicKey) string {
+	return base64.StdEncoding.EncodeToString(pub)
+}
+
+// ReadPublicKey lit une clé publique de confiance (base64 de 32 octets),
+// exportée depuis le poste signataire par "gobox pubkey".
+func ReadPublicKey(path string) (ed25519.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data)))
+	if err != nil || len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("clé publique invalide : %s (base64 de 32 octets attendu)", path)
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// ═══════════════════════════════════════════════════════════════════
+// RENDU
+// ═══════════════════════════════════════════════════════════════════
+
+// WriteCertificateJSON écrit le certificat signé en JSON indenté.
+func WriteCertificateJSON(w io.Writer, cert *ErasureCertificate) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(cert)
+}
+
+// ReadCertificateJSON relit un certificat produit par WriteCertificateJSON.
+func ReadCertificateJSON(r io.Reader) (*ErasureCertificate, error) {
+	var cert ErasureCertificate
+	if err := json.NewDecoder(r).Decode(&cert); err != nil {
+		return nil, fmt.Errorf("certificat JSON invalide: %w", err)
+	}
+	if cert.Version != CertificateVersion {
+		return nil, fmt.Errorf("version de certificat non supportée : %d", cert.Version)
+	}
+	return &cert, nil
+}
+
+// WriteCertificatePDF rend le certificat en une page imprimable.
+// Le PDF est une présentation : seule la version JSON est vérifiable.
+func WriteCertificatePDF(w io.Writer, cert *ErasureCertificate) error {
+	doc := newPDFDocument("Certificat d'effacement " + cert.ID)
+	page := doc.AddPage()
+
+	page.Title("Certificat d'effacement de données")
+	page.Field("Certificat", cert.ID)
+	page.Field("Émis le", cert.IssuedAt.Format("02/01/2006 15:04:05 MST"))
+	page.Field("Norme", fmt.Sprintf("%s — %s", cert.Standard, cert.Sanitization))
+	page.Field("Opérateur", cert.Operator)
+
+	page.Section("Machine")
+	page.Field("Fabricant", cert.System.Manufacturer)
+	page.Field("Modèle", joinNonEmpty(cert.System.Product, cert.System.Version))
+	page.Field("N° de série", cert.System.Serial)
+	page.Field("UUID", cert.System.UUID)
+	page.Field("Châssis", cert.System.ChassisType)
+
+	page.Section("Support effacé")
+	page.Field("Périphérique", cert.Disk.Name)
+	page.Field("Fabricant", cert.Disk.Vendor)
+	page.Field("Modèle", cert.Disk.Model)
+	page.Field("N° de série", cert.Disk.Serial)
+	page.Field("Type", cert.Disk.Type)
+	page.Field("Capacité", fmt.Sprintf("%.2f GB (%d octets)", float64(cert.Disk.SizeBytes)/1e9, cert.Disk.SizeBytes))
+
+	page.Section("Effacement")
+	page.Field("Technique", cert.Erasure.Technique)
+	page.Field("Méthode", cert.Erasure.Method)
+	for i, pass := range cert.Erasure.Passes {
+		page.Field(fmt.Sprintf("Passe %d", i+1), fmt.Sprintf("%s — %d octets en %.0f s",
+			pass.Pattern, pass.Bytes, pass.DurationSec))
+	}
+	page.Field("Vérification", cert.Erasure.Verification)
+	page.Field("Début", cert.Erasure.StartedAt.Format("02/01/2006 15:04:05 MST"))
+	page.Field("Fin", cert.Erasure.EndedAt.Format("02/01/2006 15:04:05 MST"))
+	status := "TERMINÉ"
+	switch {
+	case !cert.Erasure.Completed:
+		status = "INCOMPLET"
+	case !cert.Succeeded():
+		status = "ÉCHEC"
+	}
+	page.Field("Statut", status)
+
+	page.Section("Signature")
+	if cert.Signature != nil {
+		page.Field("Algorithme", cert.Signature.Algorithm)
+		page.Label("Empreinte de clé")
+		page.Mono(cert.Signature.Fingerprint, 8)
+		page.Label("Signature")
+		page.Mono(cert.Signature.Value, 8)
+	} else {
+		page.Paragraph("Certificat non signé.", 9.5)
+	}
+	page.y -= 6
+	page.Paragraph("Ce document est la représentation imprimable du certificat. "+
+		"L'authenticité se vérifie hors ligne sur le fichier JSON signé avec : "+
+		"gobox verify-cert <certificat.json>", 8.5)
+
+	page.Footer("gobox — " + cert.ID)
+
+	_, err := doc.WriteTo(w)
+	return err
+}
+
+func joinNonEmpty(parts ...string) string {
+	out := ""
+	for _, p := range parts {
+		if p == "" {
+			continue
+		}
+		if out != "" {
+			out += " "
+		}
+		out += p
+	}
+	return out
+}
@@ -0,0 +1,112 @@
+package export
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"gobox/internal/diagnostic/disk/operations"
+	"gobox/internal/probe"
+)
+
+// testKey est une clé déterministe, pour des tests reproductibles.
+func testKey(seed byte) ed25519.PrivateKey {
+	return ed25519.NewKeyFromSeed(bytes.Repeat([]byte{seed}, ed25519.SeedSize))
+}
+
+func signedWipeCertificate(t *testing.T, result operations.WipeResult) *ErasureCertificate {
+	t.Helper()
+	disk := probe.DiskInfo{Name: "sda", Model: "ST1000DM003", Serial: "Z1D4ABCD", Type: "HDD", SizeBytes: 1e12}
+	cert := NewWipeCertificate(probe.SystemInfo{Product: "OptiPlex 7070"}, disk, result, "atelier")
+	if err := cert.Sign(testKey(1)); err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestCertificateVerify(t *testing.T) {
+	now := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	verified := operations.WipeResult{Method: "zero", Verified: true, Completed: true, StartedAt: now, EndedAt: now.Add(time.Hour)}
+	trusted := testKey(1).Public().(ed25519.PublicKey)
+	other := testKey(2).Public().(ed25519.PublicKey)
+
+	tests := []struct {
+		name    string
+		result  operations.WipeResult
+		edit    func(c *ErasureCertificate)
+		trusted []ed25519.PublicKey
+		wantErr error // nil : certificat accepté
+	}{
+		{name: "valid, trusted key", result: verified, trusted: []ed25519.PublicKey{other, trusted}},
+		{name: "valid, integrity only", result: verified},
+		{name: "wrong key", result: verified, trusted: []ed25519.PublicKey{other}, wantErr: ErrUntrustedKey},
+		{name: "tampered serial", result: verified, trusted: []ed25519.PublicKey{trusted}, wantErr: ErrBadSignature,
+			edit: func(c *ErasureCertificate) { c.Disk.Serial = "Z1D4WXYZ" }},
+		{name: "tampered method", result: verified, wantErr: ErrBadSignature,
+			edit: func(c *ErasureCertificate) { c.Erasure.Method = "dod" }},
+		{name: "nil signature", result: verified, trusted: []ed25519.PublicKey{trusted}, wantErr: ErrNoSignature,
+			edit: func(c *ErasureCertificate) { c.Signature = nil }},
+		{name: "key swapped", result: verified, wantErr: ErrBadSignature,
+			edit: func(c *ErasureCertificate) { c.Signature.PublicKey = EncodePublicKey(other) }},
+		{name: "verify failures", trusted: []ed25519.PublicKey{trusted}, wantErr: ErrErasureFailed,
+			result: operations.WipeResult{Method: "zero", VerifyFailures: 3, Completed: true}},
+		{name: "interrupted", trusted: []ed25519.PublicKey{trusted}, wantErr: ErrErasureFailed,
+			result: operations.WipeResult{Method: "zero", Verified: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cert := signedWipeCertificate(t, tt.result)
+			if tt.edit != nil {
+				tt.edit(cert)
+			}
+			err := cert.Verify(tt.trusted...)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCertificateJSONRoundTrip(t *testing.T) {
+	cert := signedWipeCertificate(t, operations.WipeResult{Method: "zero", Verified: true, Completed: true})
+
+	var buf bytes.Buffer
+	if err := WriteCertificateJSON(&buf, cert); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadCertificateJSON(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := read.Verify(testKey(1).Public().(ed25519.PublicKey)); err != nil {
+		t.Errorf("relu : %v", err)
+	}
+}
+
+func TestReadPublicKey(t *testing.T) {
+	pub := testKey(1).Public().(ed25519.PublicKey)
+	path := t.TempDir() + "/station.pub"
+	if err := os.WriteFile(path, []byte(EncodePublicKey(pub)+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadPublicKey(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Equal(pub) {
+		t.Errorf("ReadPublicKey = %x, want %x", got, pub)
+	}
+
+	if err := os.WriteFile(path, []byte("dG9vIHNob3J0"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadPublicKey(path); err == nil {
+		t.Error("short key accepted")
+	}
+}
@@ -1 +1,134 @@
 package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// csvListSep sépare les éléments multiples dans une cellule (disques, GPU...).
+const csvListSep = " | "
+
+// CSVHeader est l'en-tête du CSV, une colonne par champ du tableur de stock.
+// L'ordre est stable : une nouvelle colonne s'ajoute toujours en fin.
+var CSVHeader = []string{
+	"generated_at",
+	"manufacturer", "product", "version", "serial", "uuid", "chassis",
+	"cpu_model", "cpu_cores", "cpu_freq_max_mhz",
+	"ram_total_mb", "ram_type", "ram_slots",
+	"gpus",
+	"disk_count", "disk_total_gb", "disks",
+	"battery_health_percent", "battery_cycles",
+	"grade_battery", "grade_disks", "grade_overall",
+	"nics", "usb_devices",
+	"issues",
+	"grade_cpu", "grade_ram", "grade_network",
+}
+
+// WriteCSV écrit une ligne par rapport, précédée de l'en-tête si header
+// est vrai (faux pour ajouter une machine à un fichier existant).
+func WriteCSV(w io.Writer, header bool, reports ...*Report) error {
+	cw := csv.NewWriter(w)
+	if header {
+		if err := cw.Write(CSVHeader); err != nil {
+			return err
+		}
+	}
+	for _, r := range reports {
+		if err := cw.Write(csvRow(r)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func csvRow(r *Report) []string {
+	var cpuModel, cpuCores, cpuFreq string
+	if r.CPU != nil {
+		cpuModel = r.CPU.Model
+		cpuCores = strconv.Itoa(r.CPU.Cores)
+		cpuFreq = formatFloat(r.CPU.FreqMaxMHz, 0)
+	}
+
+	var ramTotal, ramType, ramSlots string
+	if r.Memory != nil {
+		ramTotal = strconv.Itoa(r.Memory.TotalMB)
+		ramSlots = strconv.Itoa(len(r.Memory.Slots))
+		if len(r.Memory.Slots) > 0 {
+			ramType = r.Memory.Slots[0].Type
+		}
+	}
+
+	gpus := make([]string, 0, len(r.GPUs))
+	for _, g := range r.GPUs {
+		gpus = append(gpus, strings.TrimSpace(g.Vendor+" "+g.Model))
+	}
+
+	disks := make([]string, 0, len(r.Disks))
+	totalGB := 0.0
+	for _, d := range r.Disks {
+		totalGB += d.SizeGB
+		label := strings.Join(strings.Fields(fmt.Sprintf("%s %s %.0fGB %s", d.Vendor, d.Model, d.SizeGB, d.Type)), " ")
+		if d.Serial != "" {
+			label += " SN:" + d.Serial
+		}
+		disks = append(disks, fmt.Sprintf("%s [%s]", label, d.Grade))
+	}
+
+	var batHealth, batCycles string
+	if r.Battery != nil {
+		batHealth = formatFloat(r.Battery.HealthPercent, 1)
+		batCycles = strconv.Itoa(r.Battery.Cycles)
+	}
+
+	nics := make([]string, 0, len(r.Network))
+	for _, n := range r.Network {
+		nics = append(nics, n.Type+" "+n.MAC)
+	}
+
+	var issues []string
+	for _, d := range r.Disks {
+		for _, issue := range d.Issues {
+			issues = append(issues, d.Name+": "+issue)
+		}
+	}
+	for _, b := range r.Batteries {
+		for _, issue := range b.Issues {
+			issues = append(issues, batteryLabel(b)+": "+issue)
+		}
+	}
+
+	return []string{
+		r.GeneratedAt.Format("2006-01-02T15:04:05Z07:00"),
+		r.System.Manufacturer, r.System.Product, r.System.Version,
+		r.System.Serial, r.System.UUID, r.System.ChassisType,
+		cpuModel, cpuCores, cpuFreq,
+		ramTotal, ramType, ramSlots,
+		strings.Join(gpus, csvListSep),
+		strconv.Itoa(len(r.Disks)), formatFloat(totalGB, 0), strings.Join(disks, csvListSep),
+		batHealth, batCycles,
+		string(r.Grades.Battery), string(r.Grades.Disks), string(r.Grades.Overall),
+		strings.Join(nics, csvListSep), strconv.Itoa(len(r.USB)),
+		strings.Join(issues, csvListSep),
+		string(r.Grades.CPU), string(r.Grades.RAM), string(r.Grades.Network),
+	}
+}
+
+// formatFloat formate sans zéros inutiles, "" pour 0 (valeur inconnue).
+func formatFloat(v float64, prec int) string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(v, 'f', prec, 64)
+}
+
+// batteryLabel nomme une batterie dans les problèmes ("batterie BAT1").
+func batteryLabel(b BatterySection) string {
+	if b.Name == "" {
+		return "batterie"
+	}
+	return "batterie " + b.Name
+}
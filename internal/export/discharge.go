@@ -0,0 +1,37 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+
+	"gobox/internal/diagnostic/battery"
+)
+
+// DischargeCSVHeader est l'en-tête de la courbe de décharge, une ligne par
+// échantillon, prête pour un tableur.
+var DischargeCSVHeader = []string{
+	"elapsed_s", "capacity_percent", "energy_wh", "power_w", "voltage_v",
+}
+
+// WriteDischargeCSV écrit la courbe d'un test de décharge.
+func WriteDischargeCSV(w io.Writer, result battery.DischargeResult) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(DischargeCSVHeader); err != nil {
+		return err
+	}
+	for _, s := range result.Samples {
+		row := []string{
+			strconv.FormatFloat(s.Elapsed.Seconds(), 'f', 0, 64),
+			strconv.Itoa(s.Capacity),
+			strconv.FormatFloat(s.EnergyWh, 'f', 3, 64),
+			strconv.FormatFloat(s.PowerW, 'f', 2, 64),
+			strconv.FormatFloat(s.VoltageV, 'f', 3, 64),
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
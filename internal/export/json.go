@@ -1 +1,39 @@
 package export
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Schema est le JSON Schema (draft 2020-12) du Report version SchemaVersion.
+//
+//go:embed schema/report.v1.json
+var Schema []byte
+
+// WriteJSON écrit le rapport en JSON indenté, champs dans l'ordre du modèle.
+func WriteJSON(w io.Writer, report *Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(report)
+}
+
+// ReadJSON relit un rapport produit par WriteJSON. Un rapport d'une autre
+// version majeure du schéma est refusé.
+func ReadJSON(r io.Reader) (*Report, error) {
+	var report Report
+	if err := json.NewDecoder(r).Decode(&report); err != nil {
+		return nil, fmt.Errorf("rapport JSON invalide: %w", err)
+	}
+	if report.SchemaVersion != SchemaVersion {
+		return nil, fmt.Errorf("version de schéma non supportée : %d (attendu %d)",
+			report.SchemaVersion, SchemaVersion)
+	}
+	// Rapports antérieurs au champ "batteries" : une seule batterie
+	if report.Battery != nil && len(report.Batteries) == 0 {
+		report.Batteries = []BatterySection{*report.Battery}
+	}
+	return &report, nil
+}
@@ -0,0 +1,62 @@
+package export
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSchemaMatchesReport vérifie que chaque champ JSON du Report est
+// décrit dans le schéma embarqué, et réciproquement.
+func TestSchemaMatchesReport(t *testing.T) {
+	var schema map[string]any
+	if err := json.Unmarshal(Schema, &schema); err != nil {
+		t.Fatalf("schéma invalide: %v", err)
+	}
+	defs, _ := schema["$defs"].(map[string]any)
+
+	// resolve suit un "$ref" local vers $defs
+	resolve := func(node map[string]any) map[string]any {
+		if ref, ok := node["$ref"].(string); ok {
+			def, _ := defs[strings.TrimPrefix(ref, "#/$defs/")].(map[string]any)
+			return def
+		}
+		return node
+	}
+
+	var check func(path string, typ reflect.Type, node map[string]any)
+	check = func(path string, typ reflect.Type, node map[string]any) {
+		for typ.Kind() == reflect.Pointer || typ.Kind() == reflect.Slice {
+			typ = typ.Elem()
+			if items, ok := node["items"].(map[string]any); ok {
+				node = resolve(items)
+			}
+		}
+		if typ.Kind() != reflect.Struct || typ == reflect.TypeFor[time.Time]() {
+			return
+		}
+		props, _ := node["properties"].(map[string]any)
+
+		var fields []string
+		for i := range typ.NumField() {
+			field := typ.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+			fields = append(fields, name)
+			prop, ok := props[name].(map[string]any)
+			if !ok {
+				t.Errorf("%s.%s : absent du schéma", path, name)
+				continue
+			}
+			check(path+"."+name, field.Type, resolve(prop))
+		}
+		for name := range props {
+			if !slices.Contains(fields, name) {
+				t.Errorf("%s.%s : dans le schéma mais pas dans %s", path, name, typ.Name())
+			}
+		}
+	}
+	check("report", reflect.TypeFor[Report](), schema)
+}
@@ -1 +1,338 @@
 package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ═══════════════════════════════════════════════════════════════════
+// DOCUMENT PDF MINIMAL (PDF 1.4, polices standard, sans dépendance)
+// ═══════════════════════════════════════════════════════════════════
+
+// Format A4 en points (1/72 pouce).
+const (
+	pageWidth  = 595.28
+	pageHeight = 841.89
+	pageMargin = 50.0
+)
+
+// Polices Type1 standard, toujours disponibles dans un lecteur PDF.
+const (
+	fontRegular = "F1" // Helvetica
+	fontBold    = "F2" // Helvetica-Bold
+	fontMono    = "F3" // Courier
+)
+
+var pdfFonts = []struct{ id, base string }{
+	{fontRegular, "Helvetica"},
+	{fontBold, "Helvetica-Bold"},
+	{fontMono, "Courier"},
+}
+
+// pdfDocument accumule des pages et sérialise un PDF autonome.
+type pdfDocument struct {
+	title string
+	pages []*pdfPage
+}
+
+// pdfPage est le flux de contenu d'une page, avec un curseur vertical
+// pour la mise en page ligne à ligne.
+type pdfPage struct {
+	content bytes.Buffer
+	y       float64
+}
+
+func newPDFDocument(title string) *pdfDocument {
+	return &pdfDocument{title: title}
+}
+
+// AddPage ajoute une page A4 vide, curseur en haut de la zone utile.
+func (d *pdfDocument) AddPage() *pdfPage {
+	page := &pdfPage{y: pageHeight - pageMargin}
+	d.pages = append(d.pages, page)
+	return page
+}
+
+// Text écrit s à la position (x, y), origine en bas à gauche.
+func (p *pdfPage) Text(x, y, size float64, font, s string) {
+	fmt.Fprintf(&p.content, "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n",
+		font, size, x, y, pdfEscape(s))
+}
+
+// Line trace un segment gris.
+func (p *pdfPage) Line(x1, y1, x2, y2, width, gray float64) {
+	fmt.Fprintf(&p.content, "%.2f G %.2f w %.2f %.2f m %.2f %.2f l S 0 G\n",
+		gray, width, x1, y1, x2, y2)
+}
+
+// FillRect remplit un rectangle en niveau de gris (0 noir, 1 blanc).
+func (p *pdfPage) FillRect(x, y, w, h, gray float64) {
+	fmt.Fprintf(&p.content, "%.2f g %.2f %.2f %.2f %.2f re f 0 g\n", gray, x, y, w, h)
+}
+
+// Title écrit un titre et un filet sous le curseur.
+func (p *pdfPage) Title(s string) {
+	p.y -= 20
+	p.Text(pageMargin, p.y, 18, fontBold, s)
+	p.y -= 10
+	p.Line(pageMargin, p.y, pageWidth-pageMargin, p.y, 1.5, 0)
+	p.y -= 6
+}
+
+// Section écrit un intertitre sur fond gris.
+func (p *pdfPage) Section(s string) {
+	p.y -= 22
+	p.FillRect(pageMargin, p.y-4, pageWidth-2*pageMargin, 16, 0.9)
+	p.Text(pageMargin+4, p.y, 11, fontBold, s)
+	p.y -= 6
+}
+
+// Field écrit une ligne « libellé : valeur » (ignorée si valeur vide).
+func (p *pdfPage) Field(label, value string) {
+	if value == "" {
+		return
+	}
+	p.y -= 14
+	p.Text(pageMargin+4, p.y, 9.5, fontBold, label)
+	p.Text(pageMargin+150, p.y, 9.5, fontRegular, value)
+}
+
+// Label écrit un libellé seul, pour une valeur longue sur les lignes suivantes.
+func (p *pdfPage) Label(s string) {
+	p.y -= 14
+	p.Text(pageMargin+4, p.y, 9.5, fontBold, s)
+}
+
+// Mono écrit un bloc à chasse fixe, coupé à la largeur de la page.
+func (p *pdfPage) Mono(s string, size float64) {
+	perLine := int((pageWidth - 2*pageMargin - 8) / (0.6 * size)) // Courier : 600/1000 em
+	for len(s) > 0 {
+		n := min(perLine, len(s))
+		p.y -= size + 3
+		p.Text(pageMargin+4, p.y, size, fontMono, s[:n])
+		s = s[n:]
+	}
+}
+
+// Paragraph écrit un texte courant, coupé aux espaces (largeur estimée).
+func (p *pdfPage) Paragraph(s string, size float64) {
+	perLine := int((pageWidth - 2*pageMargin - 8) / (0.5 * size)) // Helvetica : ~500/1000 em
+	line := ""
+	flush := func() {
+		p.y -= size + 4
+		p.Text(pageMargin+4, p.y, size, fontRegular, line)
+		line = ""
+	}
+	for _, word := range strings.Fields(s) {
+		if line != "" && len([]rune(line))+1+len([]rune(word)) > perLine {
+			flush()
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		flush()
+	}
+}
+
+// Footer écrit une ligne en bas de page.
+func (p *pdfPage) Footer(s string) {
+	p.Line(pageMargin, pageMargin, pageWidth-pageMargin, pageMargin, 0.5, 0.6)
+	p.Text(pageMargin, pageMargin-12, 8, fontRegular, s)
+}
+
+// WriteTo sérialise le document : catalogue, arbre de pages, polices,
+// puis une paire (page, contenu) par page et la table xref.
+func (d *pdfDocument) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	var offsets []int
+
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+
+	// 1 : catalogue, 2 : pages, 3 : infos, 4.. : polices, puis les pages
+	firstPage := 4 + len(pdfFonts)
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object(fmt.Sprintf("<< /Title (%s) /Producer (gobox) >>", pdfEscape(d.title)))
+
+	var fonts []string
+	for i, f := range pdfFonts {
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", f.base))
+		fonts = append(fonts, fmt.Sprintf("/%s %d 0 R", f.id, 4+i))
+	}
+
+	for i, page := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] "+
+			"/Resources << /Font << %s >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, strings.Join(fonts, " "), firstPage+2*i+1))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.content.Len(), page.content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R /Info 3 0 R >>\nstartxref\n%d\n%%%%EOF\n",
+		len(offsets)+1, xref)
+
+	n, err := w.Write(buf.Bytes())
+	return int64(n), err
+}
+
+// winAnsiExtra couvre les caractères CP1252 hors Latin-1 utiles en français.
+var winAnsiExtra = map[rune]byte{
+	'€': 0x80, '…': 0x85, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94,
+	'•': 0x95, '–': 0x96, '—': 0x97, 'Œ': 0x8C, 'œ': 0x9C, '™': 0x99,
+}
+
+// pdfEscape convertit s en WinAnsi et échappe les délimiteurs de chaîne.
+// Les caractères non représentables deviennent « ? ».
+func pdfEscape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20:
+			b.WriteByte(' ')
+		case r < 0x80:
+			b.WriteByte(byte(r))
+		case r >= 0xA0 && r <= 0xFF:
+			b.WriteByte(byte(r))
+		default:
+			if c, ok := winAnsiExtra[r]; ok {
+				b.WriteByte(c)
+			} else {
+				b.WriteByte('?')
+			}
+		}
+	}
+	return b.String()
+}
+
+// ═══════════════════════════════════════════════════════════════════
+// FICHE TECHNIQUE
+// ═══════════════════════════════════════════════════════════════════
+
+// WritePDF rend le rapport en une fiche technique A4 d'une page.
+func WritePDF(w io.Writer, r *Report) error {
+	name := joinNonEmpty(r.System.Manufacturer, r.System.Version)
+	if name == "" {
+		name = joinNonEmpty(r.System.Manufacturer, r.System.Product)
+	}
+	if name == "" {
+		name = "Machine sans identification"
+	}
+
+	doc := newPDFDocument("Fiche technique " + name)
+	page := doc.AddPage()
+	page.Title(name)
+
+	// Pastille de note globale dans le coin supérieur droit
+	if r.Grades.Overall != "" {
+		x, y := pageWidth-pageMargin-44, pageHeight-pageMargin-34
+		page.FillRect(x, y, 44, 44, 0.15)
+		page.content.WriteString("1 g\n")
+		page.Text(x+12, y+12, 26, fontBold, string(r.Grades.Overall))
+		page.content.WriteString("0 g\n")
+	}
+
+	page.Section("Identité")
+	page.Field("Modèle", joinNonEmpty(r.System.Product, r.System.Family))
+	page.Field("N° de série", r.System.Serial)
+	page.Field("UUID", r.System.UUID)
+	page.Field("Châssis", r.System.ChassisType)
+	page.Field("BIOS", joinNonEmpty(r.System.BIOSVendor, r.System.BIOSVersion, r.System.BIOSDate))
+
+	if r.CPU != nil {
+		page.Section("Processeur")
+		page.Field("Modèle", r.CPU.Model)
+		page.Field("Cœurs", strconv.Itoa(r.CPU.Cores))
+		if r.CPU.FreqMaxMHz > 0 {
+			page.Field("Fréquence max", fmt.Sprintf("%.0f MHz", r.CPU.FreqMaxMHz))
+		}
+		page.Field("Cache", fmt.Sprintf("%.1f MB", float64(r.CPU.CacheBytes)/(1024*1024)))
+	}
+
+	if r.Memory != nil {
+		page.Section("Mémoire")
+		page.Field("Total", fmt.Sprintf("%d GB", r.Memory.TotalMB/1024))
+		for _, s := range r.Memory.Slots {
+			detail := joinNonEmpty(fmt.Sprintf("%d GB", s.SizeMB/1024), s.Type, s.FormFactor)
+			if s.SpeedMTs > 0 {
+				detail += fmt.Sprintf(" %d MT/s", s.SpeedMTs)
+			}
+			page.Field(s.Slot, joinNonEmpty(detail, s.Manufacturer, s.PartNumber))
+		}
+	}
+
+	if len(r.GPUs) > 0 {
+		page.Section("Graphique")
+		for i, g := range r.GPUs {
+			page.Field(fmt.Sprintf("GPU %d", i+1), joinNonEmpty(g.Vendor, g.Model))
+		}
+	}
+
+	if len(r.Disks) > 0 {
+		page.Section("Stockage")
+		for _, d := range r.Disks {
+			detail := joinNonEmpty(d.Vendor, d.Model, fmt.Sprintf("%.0f GB", d.SizeGB), d.Type)
+			if d.Serial != "" {
+				detail += " — n° " + d.Serial
+			}
+			page.Field(fmt.Sprintf("%s [%s]", d.Name, d.Grade), detail)
+		}
+	}
+
+	for _, b := range r.Batteries {
+		title := "Batterie"
+		if len(r.Batteries) > 1 {
+			title += " " + b.Name
+		}
+		page.Section(title)
+		page.Field("Modèle", joinNonEmpty(b.Manufacturer, b.Model, b.Technology))
+		page.Field("Santé", fmt.Sprintf("%.1f %% — %d cycles", b.HealthPercent, b.Cycles))
+		page.Field("Note", string(b.Grade))
+	}
+
+	if len(r.Network) > 0 || len(r.USB) > 0 {
+		page.Section("Connectivité")
+		for _, n := range r.Network {
+			page.Field(n.Name, joinNonEmpty(n.Model, "—", n.Type, n.MAC))
+		}
+		if len(r.USB) > 0 {
+			page.Field("USB", fmt.Sprintf("%d périphérique(s) connecté(s)", len(r.USB)))
+		}
+	}
+
+	page.Section("Diagnostic")
+	page.Field("Note globale", string(r.Grades.Overall))
+	page.Field("Stockage", string(r.Grades.Disks))
+	page.Field("Batterie", string(r.Grades.Battery))
+	page.Field("Processeur", string(r.Grades.CPU))
+	page.Field("Mémoire", string(r.Grades.RAM))
+	page.Field("Réseau", string(r.Grades.Network))
+
+	page.Footer(fmt.Sprintf("gobox — rapport du %s — schéma v%d",
+		r.GeneratedAt.Format("02/01/2006 15:04 MST"), r.SchemaVersion))
+
+	_, err := doc.WriteTo(w)
+	return err
+}
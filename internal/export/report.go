@@ -0,0 +1,419 @@
+package export
+
+import (
+	"fmt"
+	"time"
+
+	"gobox/internal/diagnostic"
+	"gobox/internal/diagnostic/battery"
+	"gobox/internal/diagnostic/common"
+	"gobox/internal/diagnostic/disk"
+	"gobox/internal/probe"
+)
+
+// SchemaVersion est la version du modèle Report. Elle n'augmente que sur
+// une rupture (champ renommé ou supprimé) ; un ajout de champ ne la change pas.
+const SchemaVersion = 1
+
+// Report agrège l'identité de la machine, ses composants et les notes des
+// diagnostics. C'est le modèle commun aux exports JSON, CSV et PDF.
+type Report struct {
+	SchemaVersion int              `json:"schema_version"`
+	GeneratedAt   time.Time        `json:"generated_at"`
+	System        SystemSection    `json:"system"`
+	CPU           *CPUSection      `json:"cpu,omitempty"`
+	Memory        *MemorySection   `json:"memory,omitempty"`
+	GPUs          []GPUSection     `json:"gpus"`
+	Disks         []DiskSection    `json:"disks"`
+	Battery       *BatterySection  `json:"battery,omitempty"`   // batterie principale
+	Batteries     []BatterySection `json:"batteries,omitempty"` // toutes les batteries
+	Network       []NICSection     `json:"network"`
+	USB           []USBSection     `json:"usb"`
+	Grades        GradesSection    `json:"grades"`
+	Errors        []string         `json:"errors,omitempty"` // sondes en échec
+}
+
+// SystemSection identifie la machine
+type SystemSection struct {
+	Manufacturer string `json:"manufacturer,omitempty"`
+	Product      string `json:"product,omitempty"`
+	Version      string `json:"version,omitempty"`
+	Family       string `json:"family,omitempty"`
+	SKU          string `json:"sku,omitempty"`
+	Serial       string `json:"serial,omitempty"`
+	UUID         string `json:"uuid,omitempty"`
+	ChassisType  string `json:"chassis_type,omitempty"`
+	BIOSVendor   string `json:"bios_vendor,omitempty"`
+	BIOSVersion  string `json:"bios_version,omitempty"`
+	BIOSDate     string `json:"bios_date,omitempty"`
+}
+
+// CPUSection décrit le processeur
+type CPUSection struct {
+	Vendor       string  `json:"vendor,omitempty"`
+	Model        string  `json:"model"`
+	Architecture string  `json:"architecture,omitempty"`
+	Cores        int     `json:"cores"`
+	CacheBytes   int64   `json:"cache_bytes"`
+	FreqMinMHz   float64 `json:"freq_min_mhz,omitempty"`
+	FreqMaxMHz   float64 `json:"freq_max_mhz,omitempty"`
+}
+
+// MemorySection décrit la mémoire installée
+type MemorySection struct {
+	TotalMB int          `json:"total_mb"`
+	Slots   []MemoryDIMM `json:"slots"`
+}
+
+// MemoryDIMM est une barrette installée
+type MemoryDIMM struct {
+	Slot         string `json:"slot"`
+	SizeMB       int    `json:"size_mb"`
+	Type         string `json:"type,omitempty"`
+	FormFactor   string `json:"form_factor,omitempty"`
+	SpeedMTs     int    `json:"speed_mts,omitempty"`
+	Manufacturer string `json:"manufacturer,omitempty"`
+	PartNumber   string `json:"part_number,omitempty"`
+	Serial       string `json:"serial,omitempty"`
+	ECC          string `json:"ecc,omitempty"`
+}
+
+// GPUSection est une carte graphique
+type GPUSection struct {
+	Vendor string `json:"vendor,omitempty"`
+	Model  string `json:"model"`
+	Driver string `json:"driver,omitempty"`
+}
+
+// DiskSection est un disque avec sa note
+type DiskSection struct {
+	Name           string       `json:"name"`
+	Vendor         string       `json:"vendor,omitempty"`
+	Model          string       `json:"model,omitempty"`
+	Serial         string       `json:"serial,omitempty"`
+	Type           string       `json:"type"` // "SSD" ou "HDD"
+	SizeGB         float64      `json:"size_gb"`
+	PowerOnHours   int64        `json:"power_on_hours,omitempty"`
+	PercentageUsed int          `json:"percentage_used,omitempty"`
+	Grade          common.Grade `json:"grade"`
+	Issues         []string     `json:"issues,omitempty"`
+}
+
+// BatterySection est une batterie avec sa note
+type BatterySection struct {
+	Name          string       `json:"name,omitempty"` // ex: "BAT0"
+	Manufacturer  string       `json:"manufacturer,omitempty"`
+	Model         string       `json:"model,omitempty"`
+	Serial        string       `json:"serial,omitempty"`
+	Technology    string       `json:"technology,omitempty"`
+	Manufactured  string       `json:"manufacture_date,omitempty"` // AAAA-MM-JJ
+	HealthPercent float64      `json:"health_percent"`
+	Cycles        int          `json:"cycles"`
+	Status        string       `json:"status,omitempty"`
+	Grade         common.Grade `json:"grade"`
+	Issues        []string     `json:"issues,omitempty"`
+}
+
+// NICSection est une interface réseau physique
+type NICSection struct {
+	Name      string   `json:"name"`
+	Model     string   `json:"model,omitempty"` // ex: "Intel Ethernet Connection (7) I219-V (e1000e)"
+	MAC       string   `json:"mac"`
+	Type      string   `json:"type"` // "ethernet" ou "wifi"
+	Driver    string   `json:"driver,omitempty"`
+	Firmware  string   `json:"firmware,omitempty"`
+	VendorID  string   `json:"vendor_id,omitempty"`
+	DeviceID  string   `json:"device_id,omitempty"`
+	Addresses []string `json:"addresses,omitempty"`
+}
+
+// USBSection est un périphérique USB connecté
+type USBSection struct {
+	Vendor  string `json:"vendor,omitempty"`
+	Product string `json:"product,omitempty"`
+	Speed   string `json:"speed,omitempty"`
+}
+
+// GradesSection regroupe les notes des diagnostics
+type GradesSection struct {
+	Overall common.Grade `json:"overall,omitempty"` // la pire note, "" si aucun diagnostic
+	Battery common.Grade `json:"battery,omitempty"`
+	Disks   common.Grade `json:"disks,omitempty"` // la pire note des disques
+	CPU     common.Grade `json:"cpu,omitempty"`   // diagnostic "cpu" exécuté
+	RAM     common.Grade `json:"ram,omitempty"`   // diagnostic "ram" exécuté
+	Network common.Grade `json:"network,omitempty"`
+}
+
+// CollectReport interroge toutes les sondes et les diagnostics sans
+// interaction. Une sonde en échec est consignée dans Errors sans
+// interrompre la collecte.
+//
+// results sont les diagnostics longs déjà exécutés : scans de surface et
+// benchmarks sont reportés sur les notes des disques (F s'ils ont échoué),
+// les diagnostics cpu, ram et network donnent leurs propres notes.
+func CollectReport(results ...diagnostic.Result) *Report {
+	report := &Report{
+		SchemaVersion: SchemaVersion,
+		GeneratedAt:   time.Now().UTC().Truncate(time.Second),
+		GPUs:          []GPUSection{},
+		Disks:         []DiskSection{},
+		Network:       []NICSection{},
+		USB:           []USBSection{},
+	}
+	fail := func(what string, err error) {
+		report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", what, err))
+	}
+
+	if sys, err := probe.GetSystemInfo(); err != nil {
+		fail("système", err)
+	} else {
+		report.System = newSystemSection(sys)
+	}
+
+	if cpu, err := probe.GetCPUInfo(); err != nil {
+		fail("cpu", err)
+	} else {
+		report.CPU = &CPUSection{
+			Vendor:       cpu.VendorID,
+			Model:        cpu.ModelName,
+			Architecture: cpu.Architect,
+			Cores:        cpu.NumberCore,
+			CacheBytes:   cpu.CacheSize,
+			FreqMinMHz:   cpu.FreqMinMHz,
+			FreqMaxMHz:   cpu.FreqMaxMHz,
+		}
+	}
+
+	if mem, err := probe.GetMemoryInfo(); err != nil {
+		fail("mémoire", err)
+	} else {
+		report.Memory = &MemorySection{TotalMB: mem.TotalMB, Slots: []MemoryDIMM{}}
+		for _, s := range mem.Slots {
+			report.Memory.Slots = append(report.Memory.Slots, MemoryDIMM{
+				Slot:         s.Slot,
+				SizeMB:       s.SizeMB,
+				Type:         s.Type,
+				FormFactor:   s.FormFactor,
+				SpeedMTs:     s.Speed,
+				Manufacturer: s.Manufacturer,
+				PartNumber:   s.PartNumber,
+				Serial:       s.SerialNumber,
+				ECC:          s.ErrorCorrection,
+			})
+		}
+	}
+
+	if gpus, err := probe.DetectGPUs(); err != nil {
+		fail("gpu", err)
+	} else {
+		for _, g := range gpus {
+			report.GPUs = append(report.GPUs, GPUSection{Vendor: g.Vendor, Model: g.Model, Driver: g.Driver})
+		}
+	}
+
+	if disks, err := disk.RunAllDiskTests(); err != nil {
+		fail("disques", err)
+	} else {
+		measured := diskMeasurements(results)
+		for _, d := range disks {
+			if m, ok := measured[d.DiskName]; ok {
+				if m.Benchmark != nil {
+					disk.ApplyBenchmark(&d, *m.Benchmark)
+				}
+				if m.Surface != nil {
+					disk.ApplySurfaceScan(&d, *m.Surface)
+				}
+			}
+			report.AddDisk(d)
+		}
+	}
+
+	if bat, err := battery.RunAllBatteryTests(); err == nil {
+		for _, b := range bat.Batteries {
+			report.AddBattery(b)
+		}
+	}
+	// Pas de batterie : cas normal d'un poste fixe, pas une erreur
+
+	if nics, err := probe.ListNetworkInterfaces(); err != nil {
+		fail("réseau", err)
+	} else {
+		for _, n := range nics {
+			report.Network = append(report.Network, NICSection{
+				Name:      n.Name,
+				Model:     n.Model(),
+				MAC:       n.MACAddress,
+				Type:      n.Type,
+				Driver:    n.Driver,
+				Firmware:  n.FirmwareVersion,
+				VendorID:  n.VendorID,
+				DeviceID:  n.DeviceID,
+				Addresses: n.Addresses,
+			})
+		}
+	}
+
+	if devices, err := probe.ListUSBDevices(); err != nil {
+		fail("usb", err)
+	} else {
+		for _, d := range devices {
+			report.USB = append(report.USB, USBSection{Vendor: d.Vendor, Product: d.Product, Speed: d.SpeedClass})
+		}
+	}
+
+	report.addDiagnosticGrades(results)
+	report.computeOverall()
+	return report
+}
+
+// diskMeasurements regroupe par disque les benchmarks et scans de surface
+// présents dans les résultats de diagnostics.
+func diskMeasurements(results []diagnostic.Result) map[string]disk.DiskHealthTest {
+	measured := make(map[string]disk.DiskHealthTest)
+	for _, r := range results {
+		tests, ok := r.Details.([]disk.DiskHealthTest)
+		if !ok {
+			continue
+		}
+		for _, t := range tests {
+			m := measured[t.DiskName]
+			if t.Benchmark != nil {
+				m.Benchmark = t.Benchmark
+			}
+			if t.Surface != nil {
+				m.Surface = t.Surface
+			}
+			measured[t.DiskName] = m
+		}
+	}
+	return measured
+}
+
+// AddDisk ajoute un disque testé et met à jour la note disques.
+func (r *Report) AddDisk(d disk.DiskHealthTest) {
+	section := DiskSection{
+		Name:   d.DiskName,
+		Vendor: d.Vendor,
+		Model:  d.Model,
+		Serial: d.Serial,
+		Type:   d.Type,
+		SizeGB: d.SizeGB,
+		Grade:  d.Grade,
+		Issues: d.Issues,
+	}
+	if d.Health != nil {
+		section.PowerOnHours = d.Health.PowerOnHours
+		section.PercentageUsed = d.Health.PercentageUsed
+	}
+	r.Disks = append(r.Disks, section)
+
+	if r.Grades.Disks == "" {
+		r.Grades.Disks = d.Grade
+	} else {
+		r.Grades.Disks = common.WorseGrade(r.Grades.Disks, d.Grade)
+	}
+}
+
+// AddBattery ajoute une batterie testée et met à jour la note batterie.
+// La première batterie ajoutée devient la batterie principale.
+func (r *Report) AddBattery(b battery.BatteryHealthTest) {
+	section := BatterySection{
+		Name:          b.Name,
+		Manufacturer:  deref(b.Info.Manufacturer),
+		Model:         deref(b.Info.Model),
+		Serial:        deref(b.Info.Serial),
+		Technology:    deref(b.Info.Technology),
+		HealthPercent: b.HealthPercentage,
+		Cycles:        b.CycleCount,
+		Status:        b.Status,
+		Grade:         b.Grade,
+		Issues:        b.Issues,
+	}
+	if d := b.Info.ManufactureDate; d != nil {
+		section.Manufactured = d.Format(time.DateOnly)
+	}
+	if r.Battery == nil {
+		primary := section
+		r.Battery = &primary
+	}
+	r.Batteries = append(r.Batteries, section)
+
+	if r.Grades.Battery == "" {
+		r.Grades.Battery = b.Grade
+	} else {
+		r.Grades.Battery = common.WorseGrade(r.Grades.Battery, b.Grade)
+	}
+}
+
+// addDiagnosticGrades reporte les notes des diagnostics CPU, mémoire et
+// réseau. Un diagnostic en échec compte F, comme dans la note globale du
+// runner, et sa cause est consignée dans Errors ; pour benchmark et scan
+// de surface, c'est la note disques qui tombe à F (un scan réussi est
+// déjà reporté disque par disque).
+func (r *Report) addDiagnosticGrades(results []diagnostic.Result) {
+	for _, res := range results {
+		var target *common.Grade
+		switch res.ID {
+		case "cpu":
+			target = &r.Grades.CPU
+		case "ram":
+			target = &r.Grades.RAM
+		case "network":
+			target = &r.Grades.Network
+		case "disk-bench", "disk-surface":
+			target = &r.Grades.Disks
+		default:
+			continue
+		}
+
+		grade := res.Grade
+		if res.Failed() {
+			grade = common.GradeF
+			r.Errors = append(r.Errors, fmt.Sprintf("diagnostic %s (%s): %v", res.ID, res.Status, res.Err))
+		}
+		switch {
+		case grade == "":
+		case *target == "":
+			*target = grade
+		default:
+			*target = common.WorseGrade(*target, grade)
+		}
+	}
+}
+
+// computeOverall retient la pire des notes disponibles.
+func (r *Report) computeOverall() {
+	r.Grades.Overall = ""
+	for _, g := range []common.Grade{r.Grades.Battery, r.Grades.Disks, r.Grades.CPU, r.Grades.RAM, r.Grades.Network} {
+		switch {
+		case g == "":
+		case r.Grades.Overall == "":
+			r.Grades.Overall = g
+		default:
+			r.Grades.Overall = common.WorseGrade(r.Grades.Overall, g)
+		}
+	}
+}
+
+func newSystemSection(sys probe.SystemInfo) SystemSection {
+	return SystemSection{
+		Manufacturer: sys.Manufacturer,
+		Product:      sys.Product,
+		Version:      sys.Version,
+		Family:       sys.Family,
+		SKU:          sys.SKU,
+		Serial:       sys.Serial,
+		UUID:         sys.UUID,
+		ChassisType:  sys.ChassisType,
+		BIOSVendor:   sys.BIOSVendor,
+		BIOSVersion:  sys.BIOSVersion,
+		BIOSDate:     sys.BIOSDate,
+	}
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,72 @@
+package export
+
+import (
+	"errors"
+	"testing"
+
+	"gobox/internal/diagnostic"
+	"gobox/internal/diagnostic/common"
+)
+
+func TestAddDiagnosticGrades(t *testing.T) {
+	failed := func(id string, status diagnostic.Status) diagnostic.Result {
+		return diagnostic.Result{ID: id, Status: status, Err: errors.New("boom")}
+	}
+	passed := func(id string, grade common.Grade) diagnostic.Result {
+		return diagnostic.Result{ID: id, Status: diagnostic.StatusPassed, Grade: grade}
+	}
+
+	tests := []struct {
+		name       string
+		disks      common.Grade // note issue des disques eux-mêmes
+		results    []diagnostic.Result
+		want       GradesSection
+		wantErrors int
+	}{
+		{
+			name:    "passed diagnostics",
+			disks:   common.GradeA,
+			results: []diagnostic.Result{passed("cpu", common.GradeB), passed("disk-surface", common.GradeA)},
+			want:    GradesSection{Disks: common.GradeA, CPU: common.GradeB},
+		},
+		{
+			name:       "surface scan timeout",
+			disks:      common.GradeA,
+			results:    []diagnostic.Result{failed("disk-surface", diagnostic.StatusTimeout)},
+			want:       GradesSection{Disks: common.GradeF},
+			wantErrors: 1,
+		},
+		{
+			name:       "benchmark panic without disk section",
+			results:    []diagnostic.Result{failed("disk-bench", diagnostic.StatusPanic)},
+			want:       GradesSection{Disks: common.GradeF},
+			wantErrors: 1,
+		},
+		{
+			name:       "ram error",
+			disks:      common.GradeB,
+			results:    []diagnostic.Result{failed("ram", diagnostic.StatusError), passed("network", common.GradeC)},
+			want:       GradesSection{Disks: common.GradeB, RAM: common.GradeF, Network: common.GradeC},
+			wantErrors: 1,
+		},
+		{
+			// Sauté ou annulé : pas de preuve de panne, pas de note
+			name:    "skipped",
+			disks:   common.GradeA,
+			results: []diagnostic.Result{{ID: "disk-surface", Status: diagnostic.StatusSkipped}},
+			want:    GradesSection{Disks: common.GradeA},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Report{Grades: GradesSection{Disks: tt.disks}}
+			r.addDiagnosticGrades(tt.results)
+			if r.Grades != tt.want {
+				t.Errorf("Grades = %+v, want %+v", r.Grades, tt.want)
+			}
+			if len(r.Errors) != tt.wantErrors {
+				t.Errorf("Errors = %q, want %d entries", r.Errors, tt.wantErrors)
+			}
+		})
+	}
+}
@@ -3,16 +3,16 @@ package probe
 import (
 	"errors"
 	"fmt"
-	"os"
+	"math"
 	"path/filepath"
 	"strings"
-	"sync"
+	"time"
 
-	"gobox/internal/sysfs"
 	"gobox/internal/utils"
 )
 
 type BatteryInfo struct {
+	Name            string // Ex: "BAT0"
 	Status          string
 	Manufacturer    *string
 	Model           *string
@@ -20,32 +20,64 @@ type BatteryInfo struct {
 	Technology      *string
 	Capacity        int
 	Cycle           int
-	EnergyAH        float64
-	VoltageNow      float64
-	DesignCapacity  float64 // 🆕 Capacité théorique (neuve)
-	CurrentCapacity float64 // 🆕 Capacité actuelle
+	Unit            BatteryUnit // unité des valeurs brutes ci-dessous
+	VoltageNow      float64     // µV
+	VoltageDesign   float64     // µV, tension nominale (0 si inconnue)
+	DesignCapacity  float64     // 🆕 Capacité théorique (neuve), dans Unit
+	CurrentCapacity float64     // 🆕 Capacité actuelle, dans Unit
+	ChargeNow       float64     // Charge restante, dans Unit
+	PowerNow        float64     // µW, débit instantané (0 si inconnu)
+	CurrentNow      float64     // µA, courant instantané (0 si inconnu)
+
+	// Capacités normalisées dans les deux unités (0 sans tension nominale
+	// pour l'unité non native)
+	FullWh, DesignWh, NowWh float64
+	FullAh, DesignAh, NowAh float64
+
+	// Champs facultatifs, nil si le pilote ne les expose pas
+	TemperatureC    *float64   // temp, en °C
+	Alarm           *float64   // seuil d'alerte de la jauge, dans Unit
+	ManufactureDate *time.Time // manufacture_year/month/day
+	CapacityLevel   *string    // "Normal", "Low", "Critical", "Full"...
+	Health          *string    // santé selon le firmware : "Good", "Overheat", "Dead"...
 }
 
-var (
-	batteryPath string
-	once        sync.Once
-)
+// BatteryUnit est la famille de fichiers exposée par le pilote
+type BatteryUnit string
 
 const (
-	pathPowerSupply = "/sys/class/power_supply"
+	UnitEnergy BatteryUnit = "µWh" // energy_full, energy_now, power_now
+	UnitCharge BatteryUnit = "µAh" // charge_full, charge_now, current_now
 )
 
-func findBatteryPathCached() (string, error) {
-	var err error
-	once.Do(func() {
-		batteryPath, err = findBatteryPath()
-	})
-	if batteryPath == "" || err != nil {
-		return "", errors.New("battery path not found")
-	}
-	return batteryPath, nil
+// PowerSupply est une alimentation externe : secteur, port USB-C / PD...
+type PowerSupply struct {
+	Name    string // Ex: "AC", "ucsi-source-psy-USBC000:001"
+	Type    string // "Mains", "USB", "UPS", "Wireless"
+	Online  bool   // alimentation branchée et utilisée
+	USBType string // type USB négocié, ex: "PD", "PD_PPS", "SDP" ; vide hors USB
 }
 
+// PowerStatus est la vue d'ensemble des alimentations de la machine
+type PowerStatus struct {
+	Batteries []BatteryInfo
+	Supplies  []PowerSupply
+	OnAC      bool // au moins une alimentation externe en ligne
+
+	// Agrégat des batteries (portables à deux batteries), en Wh pour
+	// additionner des batteries energy_* et charge_*
+	Capacity int     // charge globale en %, pondérée par la capacité
+	FullWh   float64 // somme des capacités actuelles
+	DesignWh float64 // somme des capacités neuves
+}
+
+const (
+	pathPowerSupply = "/sys/class/power_supply"
+
+	powerSupplyBattery = "Battery"
+	scopeDevice        = "Device" // batterie d'un périphérique (souris, casque)
+)
+
 func strPtrIfNotEmpty(s string) *string {
 	if s == "" {
 		return nil
@@ -53,54 +85,203 @@ func strPtrIfNotEmpty(s string) *string {
 	return &s
 }
 
+// GetPowerStatus lit toutes les entrées de /sys/class/power_supply.
+// Relu à chaque appel : une batterie échangée à chaud est vue aussitôt.
+func GetPowerStatus() (PowerStatus, error) {
+	entries, err := rootFS.ReadDir(pathPowerSupply)
+	if err != nil {
+		return PowerStatus{}, fmt.Errorf("listing power_supply: %w", err)
+	}
+
+	var status PowerStatus
+	for _, entry := range entries {
+		name := entry.Name()
+		path := filepath.Join(pathPowerSupply, name)
+		kind, _ := rootFS.ReadFileOptional(filepath.Join(path, "type"))
+
+		if kind != powerSupplyBattery {
+			supply := readPowerSupply(path, name, kind)
+			status.Supplies = append(status.Supplies, supply)
+			if supply.Online {
+				status.OnAC = true
+			}
+			continue
+		}
+
+		if scope, _ := rootFS.ReadFileOptional(filepath.Join(path, "scope")); scope == scopeDevice {
+			continue
+		}
+		// Baie vide d'une batterie amovible
+		if present, err := rootFS.ReadBool(filepath.Join(path, "present")); err == nil && !present {
+			continue
+		}
+		info, err := readBattery(path)
+		if err != nil {
+			return PowerStatus{}, fmt.Errorf("%s: %w", name, err)
+		}
+		status.Batteries = append(status.Batteries, info)
+	}
+
+	status.aggregate()
+	return status, nil
+}
+
+// ListBatteries retourne les batteries du système (BAT0, BAT1...).
+// Retourne une liste vide sur un poste fixe.
+func ListBatteries() ([]BatteryInfo, error) {
+	status, err := GetPowerStatus()
+	if err != nil {
+		return nil, err
+	}
+	if status.Batteries == nil {
+		return []BatteryInfo{}, nil
+	}
+	return status.Batteries, nil
+}
+
+// GetBatteryInfo retourne la première batterie.
 func GetBatteryInfo() (BatteryInfo, error) {
-	path, err := findBatteryPathCached()
+	batteries, err := ListBatteries()
 	if err != nil {
 		return BatteryInfo{}, err
 	}
+	if len(batteries) == 0 {
+		return BatteryInfo{}, errors.New("no battery found")
+	}
+	return batteries[0], nil
+}
+
+// ReadBattery relit une batterie par son nom (ex: "BAT0"), sans parcourir
+// les autres alimentations : adapté à un échantillonnage périodique.
+func ReadBattery(name string) (BatteryInfo, error) {
+	if err := validateSysfsName(name); err != nil {
+		return BatteryInfo{}, err
+	}
+	return readBattery(filepath.Join(pathPowerSupply, name))
+}
+
+func (s *PowerStatus) aggregate() {
+	weighted, plain := 0.0, 0
+	for _, b := range s.Batteries {
+		s.FullWh += b.FullWh
+		s.DesignWh += b.DesignWh
+		weighted += float64(b.Capacity) * b.FullWh
+		plain += b.Capacity
+	}
+	switch {
+	case s.FullWh > 0:
+		s.Capacity = int(weighted/s.FullWh + 0.5)
+	case len(s.Batteries) > 0:
+		s.Capacity = plain / len(s.Batteries)
+	}
+}
+
+func readPowerSupply(path, name, kind string) PowerSupply {
+	supply := PowerSupply{Name: name, Type: kind}
+	if online, err := rootFS.ReadBool(filepath.Join(path, "online")); err == nil {
+		supply.Online = online
+	}
+	if usbType, err := rootFS.ReadFileOptional(filepath.Join(path, "usb_type")); err == nil {
+		supply.USBType = activeUSBType(usbType)
+	}
+	return supply
+}
+
+// activeUSBType extrait le type actif de usb_type, ex: "C [PD] PD_PPS" → "PD".
+func activeUSBType(s string) string {
+	for _, field := range strings.Fields(s) {
+		if strings.HasPrefix(field, "[") && strings.HasSuffix(field, "]") {
+			return strings.Trim(field, "[]")
+		}
+	}
+	if fields := strings.Fields(s); len(fields) == 1 {
+		return fields[0]
+	}
+	return ""
+}
 
+func readBattery(path string) (BatteryInfo, error) {
 	// Required fields
-	capacity, err := sysfs.ReadInt(filepath.Join(path, "capacity"))
+	capacity, err := rootFS.ReadInt(filepath.Join(path, "capacity"))
 	if err != nil {
 		return BatteryInfo{}, fmt.Errorf("reading capacity: %w", err)
 	}
 
-	status, err := sysfs.ReadFile(filepath.Join(path, "status"))
+	status, err := rootFS.ReadFile(filepath.Join(path, "status"))
 	if err != nil {
 		return BatteryInfo{}, fmt.Errorf("reading status: %w", err)
 	}
 
 	// Optional fields (no error handling, use empty string if missing)
-	manufacturer, _ := sysfs.ReadFileOptional(filepath.Join(path, "manufacturer"))
-	model, _ := sysfs.ReadFileOptional(filepath.Join(path, "model_name"))
-	serialNumber, _ := sysfs.ReadFileOptional(filepath.Join(path, "serial_number"))
-	technology, _ := sysfs.ReadFileOptional(filepath.Join(path, "technology"))
+	manufacturer, _ := rootFS.ReadFileOptional(filepath.Join(path, "manufacturer"))
+	model, _ := rootFS.ReadFileOptional(filepath.Join(path, "model_name"))
+	serialNumber, _ := rootFS.ReadFileOptional(filepath.Join(path, "serial_number"))
+	technology, _ := rootFS.ReadFileOptional(filepath.Join(path, "technology"))
 
 	cycle := 0
-	if c, err := sysfs.ReadInt(filepath.Join(path, "cycle_count")); err == nil {
+	if c, err := rootFS.ReadInt(filepath.Join(path, "cycle_count")); err == nil {
 		cycle = c
 	}
 
 	voltNow := 0.0
-	if v, err := sysfs.ReadFloat(filepath.Join(path, "voltage_now")); err == nil {
+	if v, err := rootFS.ReadFloat(filepath.Join(path, "voltage_now")); err == nil {
 		voltNow = v
 	}
 
+	// Tension nominale : voltage_min_design est la référence des pilotes
+	// ACPI ; à défaut la tension maximale, puis la tension instantanée.
+	voltDesign := 0.0
+	for _, file := range []string{"voltage_min_design", "voltage_max_design"} {
+		if v, err := rootFS.ReadFloat(filepath.Join(path, file)); err == nil && v > 0 {
+			voltDesign = v
+			break
+		}
+	}
+	if voltDesign == 0 {
+		voltDesign = voltNow
+	}
+
+	// energy_* (µWh) ou charge_* (µAh) selon le pilote
+	unit, prefix := UnitEnergy, "energy_"
+	if _, err := rootFS.Stat(filepath.Join(path, "energy_full")); err != nil {
+		if _, err := rootFS.Stat(filepath.Join(path, "charge_full")); err == nil {
+			unit, prefix = UnitCharge, "charge_"
+		}
+	}
+
 	currentCapacity := 0.0
-	if e, err := sysfs.ReadFloat(filepath.Join(path, "energy_full")); err == nil {
+	if e, err := rootFS.ReadFloat(filepath.Join(path, prefix+"full")); err == nil {
 		currentCapacity = e
 	}
 
 	// Capacité théorique (neuve)
 	designCapacity := 0.0
-	if e, err := sysfs.ReadFloat(filepath.Join(path, "energy_full_design")); err == nil {
+	if e, err := rootFS.ReadFloat(filepath.Join(path, prefix+"full_design")); err == nil {
 		designCapacity = e
 	}
 
-	// Convert Wh to Ah (silent error handling)
-	energyAH, _ := utils.ConvertWattToAmpere(path, currentCapacity)
+	chargeNow := 0.0
+	if e, err := rootFS.ReadFloat(filepath.Join(path, prefix+"now")); err == nil {
+		chargeNow = e
+	}
+
+	// Certains pilotes signent le courant (négatif en décharge)
+	powerNow, currentNow := 0.0, 0.0
+	if p, err := rootFS.ReadFloat(filepath.Join(path, "power_now")); err == nil {
+		powerNow = math.Abs(p)
+	}
+	if c, err := rootFS.ReadFloat(filepath.Join(path, "current_now")); err == nil {
+		currentNow = math.Abs(c)
+	}
+	if powerNow == 0 && currentNow > 0 && voltNow > 0 {
+		powerNow = currentNow * voltNow / 1_000_000
+	}
+	if currentNow == 0 && powerNow > 0 && voltNow > 0 {
+		currentNow = powerNow / (voltNow / 1_000_000)
+	}
 
-	return BatteryInfo{
+	info := BatteryInfo{
+		Name:            filepath.Base(path),
 		Status:          status,
 		Manufacturer:    strPtrIfNotEmpty(manufacturer),
 		Model:           strPtrIfNotEmpty(model),
@@ -108,23 +289,73 @@ func GetBatteryInfo() (BatteryInfo, error) {
 		Technology:      strPtrIfNotEmpty(technology),
 		Capacity:        capacity,
 		Cycle:           cycle,
-		EnergyAH:        energyAH,
+		Unit:            unit,
 		VoltageNow:      voltNow,
+		VoltageDesign:   voltDesign,
 		CurrentCapacity: currentCapacity,
 		DesignCapacity:  designCapacity,
-	}, nil
+		ChargeNow:       chargeNow,
+		PowerNow:        powerNow,
+		CurrentNow:      currentNow,
+	}
+	if t, err := rootFS.ReadFloat(filepath.Join(path, "temp")); err == nil {
+		t /= 10 // dixièmes de degré
+		info.TemperatureC = &t
+	}
+	if a, err := rootFS.ReadFloat(filepath.Join(path, "alarm")); err == nil && a > 0 {
+		info.Alarm = &a
+	}
+	info.ManufactureDate = readManufactureDate(path)
+	level, _ := rootFS.ReadFileOptional(filepath.Join(path, "capacity_level"))
+	info.CapacityLevel = strPtrIfNotEmpty(level)
+	health, _ := rootFS.ReadFileOptional(filepath.Join(path, "health"))
+	info.Health = strPtrIfNotEmpty(health)
+
+	info.normalize()
+	return info, nil
 }
 
-func findBatteryPath() (string, error) {
-	entries, err := os.ReadDir(pathPowerSupply)
-	if err != nil {
-		return "", fmt.Errorf("listing power_supply: %w", err)
+// readManufactureDate lit la date de fabrication ; le mois et le jour
+// sont facultatifs. Les années absurdes (jauge non programmée) sont ignorées.
+func readManufactureDate(path string) *time.Time {
+	year, err := rootFS.ReadInt(filepath.Join(path, "manufacture_year"))
+	if err != nil || year < 1990 || year > time.Now().Year() {
+		return nil
 	}
-	for _, entry := range entries {
-		name := entry.Name()
-		if strings.HasPrefix(name, "BAT") {
-			return filepath.Join(pathPowerSupply, name), nil
-		}
+	month, day := 1, 1
+	if m, err := rootFS.ReadInt(filepath.Join(path, "manufacture_month")); err == nil && m >= 1 && m <= 12 {
+		month = m
+	}
+	if d, err := rootFS.ReadInt(filepath.Join(path, "manufacture_day")); err == nil && d >= 1 && d <= 31 {
+		day = d
+	}
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	return &date
+}
+
+// normalize remplit les capacités en Wh et en Ah depuis l'unité native.
+// L'autre unité reste à 0 sans tension nominale.
+func (b *BatteryInfo) normalize() {
+	// µWh → Ah ou µAh → Wh, via la tension nominale
+	other := func(convert func(v, voltage float64) (float64, error), v float64) float64 {
+		converted, _ := convert(v, b.VoltageDesign)
+		return converted / 1_000_000
+	}
+
+	switch b.Unit {
+	case UnitEnergy:
+		b.FullWh = b.CurrentCapacity / 1_000_000
+		b.DesignWh = b.DesignCapacity / 1_000_000
+		b.NowWh = b.ChargeNow / 1_000_000
+		b.FullAh = other(utils.ConvertWattToAmpere, b.CurrentCapacity)
+		b.DesignAh = other(utils.ConvertWattToAmpere, b.DesignCapacity)
+		b.NowAh = other(utils.ConvertWattToAmpere, b.ChargeNow)
+	case UnitCharge:
+		b.FullAh = b.CurrentCapacity / 1_000_000
+		b.DesignAh = b.DesignCapacity / 1_000_000
+		b.NowAh = b.ChargeNow / 1_000_000
+		b.FullWh = other(utils.ConvertAmpereToWatt, b.CurrentCapacity)
+		b.DesignWh = other(utils.ConvertAmpereToWatt, b.DesignCapacity)
+		b.NowWh = other(utils.ConvertAmpereToWatt, b.ChargeNow)
 	}
-	return "", errors.New("no battery found")
 }
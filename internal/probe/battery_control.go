@@ -0,0 +1,132 @@
+package probe
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Comportements de charge_behaviour
+const (
+	ChargeBehaviourAuto           = "auto"
+	ChargeBehaviourInhibitCharge  = "inhibit-charge"
+	ChargeBehaviourForceDischarge = "force-discharge"
+)
+
+// ChargeControl est le pilotage de la charge exposé par le pilote
+// (thinkpad_acpi, dell-laptop, asus-wmi, huawei-wmi...). Un champ nil ou
+// vide signale un réglage absent.
+type ChargeControl struct {
+	StartThreshold *int     // charge_control_start_threshold (%)
+	EndThreshold   *int     // charge_control_end_threshold (%)
+	Behaviour      string   // comportement actif, ex: "auto"
+	Behaviours     []string // comportements supportés
+}
+
+// Supported indique si le pilote expose au moins un réglage.
+func (c ChargeControl) Supported() bool {
+	return c.StartThreshold != nil || c.EndThreshold != nil || c.Behaviour != ""
+}
+
+// GetChargeControl lit les seuils et le comportement de charge.
+func GetChargeControl(name string) (ChargeControl, error) {
+	if err := validateSysfsName(name); err != nil {
+		return ChargeControl{}, err
+	}
+	path := filepath.Join(pathPowerSupply, name)
+	if _, err := rootFS.Stat(path); err != nil {
+		return ChargeControl{}, fmt.Errorf("batterie %s introuvable", name)
+	}
+
+	var control ChargeControl
+	if v, err := rootFS.ReadInt(filepath.Join(path, "charge_control_start_threshold")); err == nil {
+		control.StartThreshold = &v
+	}
+	if v, err := rootFS.ReadInt(filepath.Join(path, "charge_control_end_threshold")); err == nil {
+		control.EndThreshold = &v
+	}
+	// "[auto] inhibit-charge force-discharge" : l'actif entre crochets
+	if v, err := rootFS.ReadFileOptional(filepath.Join(path, "charge_behaviour")); err == nil && v != "" {
+		for _, field := range strings.Fields(v) {
+			if strings.HasPrefix(field, "[") && strings.HasSuffix(field, "]") {
+				field = strings.Trim(field, "[]")
+				control.Behaviour = field
+			}
+			control.Behaviours = append(control.Behaviours, field)
+		}
+	}
+	return control, nil
+}
+
+// SetChargeThresholds règle les seuils de charge (0 ≤ start < end ≤ 100).
+// Un pilote qui n'expose que le seuil de fin ignore start. L'ordre des
+// écritures respecte la contrainte start < end vérifiée par le noyau.
+// Système réel uniquement.
+func SetChargeThresholds(name string, start, end int) error {
+	if start < 0 || end > 100 || start >= end {
+		return fmt.Errorf("seuils invalides %d–%d %% (0 ≤ début < fin ≤ 100)", start, end)
+	}
+	control, err := GetChargeControl(name)
+	if err != nil {
+		return err
+	}
+	if control.EndThreshold == nil {
+		return fmt.Errorf("%s : seuils de charge non supportés par le pilote", name)
+	}
+
+	path := filepath.Join(pathPowerSupply, name)
+	writeStart := func() error {
+		if control.StartThreshold == nil {
+			return nil
+		}
+		return writeSysfs(filepath.Join(path, "charge_control_start_threshold"), strconv.Itoa(start))
+	}
+	writeEnd := func() error {
+		return writeSysfs(filepath.Join(path, "charge_control_end_threshold"), strconv.Itoa(end))
+	}
+
+	// Relever la fin avant le début, ou baisser le début avant la fin
+	if control.StartThreshold != nil && start >= *control.EndThreshold {
+		if err := writeEnd(); err != nil {
+			return err
+		}
+		return writeStart()
+	}
+	if err := writeStart(); err != nil {
+		return err
+	}
+	return writeEnd()
+}
+
+// SetChargeBehaviour choisit le comportement de charge : "auto",
+// "inhibit-charge" (secteur branché, batterie au repos) ou
+// "force-discharge" (batterie sollicitée malgré le secteur).
+// Système réel uniquement.
+func SetChargeBehaviour(name, behaviour string) error {
+	control, err := GetChargeControl(name)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(control.Behaviours, behaviour) {
+		if len(control.Behaviours) == 0 {
+			return fmt.Errorf("%s : charge_behaviour non supporté par le pilote", name)
+		}
+		return fmt.Errorf("%s : comportement %q non supporté (%s)", name, behaviour, strings.Join(control.Behaviours, ", "))
+	}
+	return writeSysfs(filepath.Join(pathPowerSupply, name, "charge_behaviour"), behaviour)
+}
+
+// writeSysfs écrit un attribut sysfs ; refusé en mode rejeu.
+func writeSysfs(path, value string) error {
+	if !rootFS.IsHost() {
+		return errors.New("écriture impossible en mode rejeu")
+	}
+	if err := os.WriteFile(rootFS.Path(path), []byte(value), 0); err != nil {
+		return fmt.Errorf("écriture %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
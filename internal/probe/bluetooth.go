@@ -1,34 +1,488 @@
 package probe
 
-//
-// import (
-// 	"fmt"
-// 	"os"
-// )
-//
-// const (
-// 	bluetoothRoot = "/sys/class/bluetooth/" // Périphériques Bluetooth
-// 	hciRoot       = "/sys/class/net/hci"    // Adaptateurs HCI (hci0, hci1...)
-// )
-//
-// type BluetoothAdapter struct {
-// 	Name       string // Ex: "hci0"
-// 	MACAddress string // Adresse MAC Bluetooth
-// 	IsUp       bool   // État actif
-// 	Type       string // "USB", "PCI", etc.
-// 	Vendor     string // Fabricant
-// }
-
-// func ListBluetoothAdapters() ([]BluetoothAdapter, error) {
-// 	// Logique pour lister les adaptateurs Bluetooth
-// 	entries, err := os.ReadDir(bluetoothRoot)
-// 	if err != nil {
-// 		// Si le répertoire n'existe pas, pas de Bluetooth
-// 		if os.IsNotExist(err) {
-// 			return []BluetoothAdapter{}, nil
-// 		}
-// 		return nil, fmt.Errorf("lecture des adaptateurs Bluetooth: %w", err)
-// 	}
-//
-// 	// ... traitement
-// }
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	bluetoothRoot = "/sys/class/bluetooth/" // Adaptateurs HCI (hci0, hci1...)
+	rfkillRoot    = "/sys/class/rfkill/"    // Interrupteurs radio
+)
+
+// BluetoothAdapter représente un contrôleur Bluetooth
+type BluetoothAdapter struct {
+	Name        string // Ex: "hci0"
+	MACAddress  string // Adresse BD_ADDR (système réel uniquement)
+	IsUp        bool   // Adaptateur activé (HCI_UP)
+	Type        string // "USB", "UART", "PCI", "SDIO"...
+	Driver      string // Ex: "btusb"
+	VendorID    string // Ex: "0x8087"
+	ProductID   string // Ex: "0x0026"
+	Vendor      string // Fabricant du périphérique parent
+	Product     string // Ex: "AX201 Bluetooth"
+	SoftBlocked bool   // rfkill logiciel
+	HardBlocked bool   // rfkill matériel (interrupteur, BIOS)
+
+	// Read Local Version Information (adaptateur actif, système réel)
+	HCIVersion    string // Ex: "5.2"
+	LMPSubversion uint16
+	Manufacturer  string // Fabricant de la puce (identifiant Bluetooth SIG)
+}
+
+// BluetoothDevice est un appareil vu pendant une découverte
+type BluetoothDevice struct {
+	Address string // Ex: "a4:c1:38:12:34:56"
+	LE      bool   // Bluetooth Low Energy (sinon BR/EDR classique)
+	RSSI    int    // dBm
+	Name    string // Nom annoncé, souvent vide en LE
+}
+
+// ═══════════════════════════════════════════════════════════════════
+// INVENTAIRE
+// ═══════════════════════════════════════════════════════════════════
+
+// ListBluetoothAdapters liste les contrôleurs Bluetooth.
+// Retourne une liste vide si la machine n'a pas de Bluetooth.
+func ListBluetoothAdapters() ([]BluetoothAdapter, error) {
+	entries, err := rootFS.ReadDir(bluetoothRoot)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []BluetoothAdapter{}, nil
+		}
+		return nil, fmt.Errorf("lecture des adaptateurs Bluetooth: %w", err)
+	}
+
+	adapters := make([]BluetoothAdapter, 0, len(entries))
+	for _, entry := range entries {
+		name := entry.Name()
+
+		// "hci0:3" sont des connexions, pas des adaptateurs
+		if !strings.HasPrefix(name, "hci") || strings.Contains(name, ":") {
+			continue
+		}
+		if err := validateSysfsName(name); err != nil {
+			continue
+		}
+		basePath := filepath.Join(bluetoothRoot, name)
+
+		adapter := BluetoothAdapter{Name: name}
+		if id, ok := readDeviceIdentity(basePath); ok {
+			adapter.Driver = id.Driver
+			adapter.Type = bluetoothBusName(id.Bus)
+			adapter.VendorID = id.VendorID
+			adapter.ProductID = id.DeviceID
+			adapter.Vendor = id.Vendor
+			adapter.Product = id.Product
+		}
+		adapter.SoftBlocked, adapter.HardBlocked = readRfkill(basePath, name)
+
+		if rootFS.IsHost() {
+			readHCIInfo(&adapter)
+		}
+
+		adapters = append(adapters, adapter)
+	}
+
+	return adapters, nil
+}
+
+// bluetoothBusName traduit le sous-système sysfs du parent
+func bluetoothBusName(subsystem string) string {
+	switch subsystem {
+	case "usb":
+		return "USB"
+	case "pci":
+		return "PCI"
+	case "sdio":
+		return "SDIO"
+	case "serial", "serial-base", "tty":
+		return "UART"
+	case "":
+		return ""
+	}
+	return strings.ToUpper(subsystem)
+}
+
+// readRfkill lit l'état rfkill de l'adaptateur : d'abord l'interrupteur
+// rattaché à hciN, sinon un interrupteur Bluetooth global (portables).
+func readRfkill(basePath, name string) (soft, hard bool) {
+	switches, _ := rootFS.Glob(filepath.Join(basePath, "rfkill[0-9]*"))
+	if len(switches) == 0 {
+		entries, err := rootFS.ReadDir(rfkillRoot)
+		if err != nil {
+			return false, false
+		}
+		for _, e := range entries {
+			p := filepath.Join(rfkillRoot, e.Name())
+			if kind, _ := rootFS.ReadFileOptional(filepath.Join(p, "type")); kind != "bluetooth" {
+				continue
+			}
+			switches = append(switches, p)
+		}
+	}
+
+	for _, p := range switches {
+		if s, err := rootFS.ReadBool(filepath.Join(p, "soft")); err == nil && s {
+			soft = true
+		}
+		if h, err := rootFS.ReadBool(filepath.Join(p, "hard")); err == nil && h {
+			hard = true
+		}
+	}
+	return soft, hard
+}
+
+// ═══════════════════════════════════════════════════════════════════
+// SOCKET HCI
+// ═══════════════════════════════════════════════════════════════════
+
+const (
+	hciGetDevInfo = 0x800448d3 // HCIGETDEVINFO = _IOR('H', 211, int)
+	hciDevNone    = 0xffff
+	hciFlagUp     = 1 << 0 // HCI_UP
+
+	hciCommandPkt     = 0x01
+	hciEventPkt       = 0x04
+	hciEvCmdComplete  = 0x0e
+	hciOpReadLocalVer = 0x1001 // OGF 0x04 (informationnel), OCF 0x0001
+	hciFilterOpt      = 2      // HCI_FILTER
+
+	hciTimeout = 2 * time.Second
+)
+
+// hciDevInfo est struct hci_dev_info
+type hciDevInfo struct {
+	DevID      uint16
+	Name       [8]byte
+	Bdaddr     [6]byte
+	Flags      uint32
+	Type       uint8
+	Features   [8]byte
+	PktType    uint32
+	LinkPolicy uint32
+	LinkMode   uint32
+	ACLMtu     uint16
+	ACLPkts    uint16
+	SCOMtu     uint16
+	SCOPkts    uint16
+	Stat       [10]uint32
+}
+
+// hciFilter est struct hci_filter
+type hciFilter struct {
+	TypeMask  uint32
+	EventMask [2]uint32
+	Opcode    uint16
+	_         uint16
+}
+
+// hciBusNames indexe le type de bus de hci_dev_info (HCI_USB...)
+var hciBusNames = []string{"Virtuel", "USB", "PCCARD", "UART", "RS232", "PCI", "SDIO", "SPI", "I2C", "SMD", "VIRTIO"}
+
+// hciVersions nomme les versions HCI/LMP de la spécification
+var hciVersions = []string{"1.0b", "1.1", "1.2", "2.0", "2.1", "3.0", "4.0", "4.1", "4.2", "5.0", "5.1", "5.2", "5.3", "5.4", "6.0"}
+
+// bluetoothCompanies sont les fabricants de puces les plus courants
+// (Bluetooth SIG, Assigned Numbers).
+var bluetoothCompanies = map[uint16]string{
+	2:   "Intel",
+	10:  "Qualcomm (CSR)",
+	13:  "Texas Instruments",
+	15:  "Broadcom",
+	29:  "Qualcomm",
+	69:  "Atheros",
+	70:  "MediaTek",
+	93:  "Realtek",
+	305: "Cypress",
+	760: "Infineon",
+}
+
+func hciDevID(name string) (uint16, error) {
+	id, err := strconv.ParseUint(strings.TrimPrefix(name, "hci"), 10, 16)
+	return uint16(id), err
+}
+
+func openHCI() (int, error) {
+	return unix.Socket(unix.AF_BLUETOOTH, unix.SOCK_RAW|unix.SOCK_CLOEXEC, unix.BTPROTO_HCI)
+}
+
+// readHCIInfo complète l'adaptateur par HCIGETDEVINFO puis, s'il est
+// actif, par la commande Read Local Version Information. Les deux sont
+// autorisées sans privilège.
+func readHCIInfo(adapter *BluetoothAdapter) {
+	id, err := hciDevID(adapter.Name)
+	if err != nil {
+		return
+	}
+	fd, err := openHCI()
+	if err != nil {
+		return
+	}
+	defer unix.Close(fd)
+
+	info := hciDevInfo{DevID: id}
+	if _, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), hciGetDevInfo, uintptr(unsafe.Pointer(&info))); errno != 0 {
+		return
+	}
+	adapter.MACAddress = bdaddrString(info.Bdaddr[:])
+	adapter.IsUp = info.Flags&hciFlagUp != 0
+	if bus := int(info.Type & 0x0f); bus < len(hciBusNames) && (adapter.Type == "" || bus != 0) {
+		adapter.Type = hciBusNames[bus]
+	}
+
+	if adapter.IsUp {
+		readLocalVersion(id, adapter)
+	}
+}
+
+// readLocalVersion envoie Read Local Version Information sur un socket
+// HCI brut et lit l'événement Command Complete.
+func readLocalVersion(id uint16, adapter *BluetoothAdapter) error {
+	fd, err := openHCI()
+	if err != nil {
+		return err
+	}
+	defer unix.Close(fd)
+
+	if err := unix.Bind(fd, &unix.SockaddrHCI{Dev: id, Channel: unix.HCI_CHANNEL_RAW}); err != nil {
+		return err
+	}
+	filter := hciFilter{
+		TypeMask:  1 << hciEventPkt,
+		EventMask: [2]uint32{1 << hciEvCmdComplete, 0},
+		Opcode:    hciOpReadLocalVer,
+	}
+	if _, _, errno := unix.Syscall6(unix.SYS_SETSOCKOPT, uintptr(fd), unix.SOL_HCI, hciFilterOpt,
+		uintptr(unsafe.Pointer(&filter)), unsafe.Sizeof(filter), 0); errno != 0 {
+		return errno
+	}
+	tv := unix.NsecToTimeval(hciTimeout.Nanoseconds())
+	if err := unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &tv); err != nil {
+		return err
+	}
+
+	cmd := []byte{hciCommandPkt, 0, 0, 0}
+	binary.LittleEndian.PutUint16(cmd[1:], hciOpReadLocalVer)
+	if _, err := unix.Write(fd, cmd); err != nil {
+		return err
+	}
+
+	buf := make([]byte, 260)
+	for {
+		n, err := unix.Read(fd, buf)
+		if err != nil {
+			return err
+		}
+		// type, event, plen, ncmd, opcode(2), status, hci_ver, hci_rev(2),
+		// lmp_ver, manufacturer(2), lmp_subver(2)
+		ev := buf[:n]
+		if len(ev) < 15 || ev[0] != hciEventPkt || ev[1] != hciEvCmdComplete ||
+			binary.LittleEndian.Uint16(ev[4:]) != hciOpReadLocalVer {
+			continue
+		}
+		if ev[6] != 0 {
+			return fmt.Errorf("Read Local Version: statut 0x%02x", ev[6])
+		}
+		if v := int(ev[7]); v < len(hciVersions) {
+			adapter.HCIVersion = hciVersions[v]
+		}
+		company := binary.LittleEndian.Uint16(ev[11:])
+		adapter.Manufacturer = bluetoothCompanies[company]
+		if adapter.Manufacturer == "" {
+			adapter.Manufacturer = fmt.Sprintf("0x%04x", company)
+		}
+		adapter.LMPSubversion = binary.LittleEndian.Uint16(ev[13:])
+		return nil
+	}
+}
+
+// ═══════════════════════════════════════════════════════════════════
+// DÉCOUVERTE (API mgmt)
+// ═══════════════════════════════════════════════════════════════════
+
+const (
+	mgmtOpStartDiscovery = 0x0023
+	mgmtOpStopDiscovery  = 0x0024
+	mgmtEvCmdComplete    = 0x0001
+	mgmtEvCmdStatus      = 0x0002
+	mgmtEvDeviceFound    = 0x0012
+
+	mgmtStatusBusy = 0x0a
+
+	mgmtDiscoveryAll = 0x07 // BR/EDR + LE public + LE aléatoire
+	mgmtAddrBREDR    = 0x00
+
+	eirNameShort    = 0x08
+	eirNameComplete = 0x09
+)
+
+// mgmtStatusText traduit les statuts mgmt les plus courants
+var mgmtStatusText = map[uint8]string{
+	0x03: "non supporté",
+	0x0a: "occupé",
+	0x0d: "paramètres invalides",
+	0x0f: "adaptateur éteint",
+	0x11: "adaptateur inconnu",
+	0x12: "bloqué par rfkill",
+	0x14: "permission refusée",
+}
+
+// DiscoverBluetooth lance une découverte BR/EDR et LE pendant duration et
+// retourne les appareils vus, ce qui prouve que la radio reçoit. Passe
+// par l'API mgmt du noyau (cohabite avec bluetoothd) et nécessite
+// CAP_NET_ADMIN. Système réel uniquement.
+func DiscoverBluetooth(ctx context.Context, name string, duration time.Duration) ([]BluetoothDevice, error) {
+	if !rootFS.IsHost() {
+		return nil, errors.New("découverte Bluetooth impossible en mode rejeu")
+	}
+	index, err := hciDevID(name)
+	if err != nil {
+		return nil, fmt.Errorf("adaptateur invalide %q", name)
+	}
+
+	fd, err := openHCI()
+	if err != nil {
+		return nil, err
+	}
+	defer unix.Close(fd)
+	if err := unix.Bind(fd, &unix.SockaddrHCI{Dev: hciDevNone, Channel: unix.HCI_CHANNEL_CONTROL}); err != nil {
+		return nil, err
+	}
+	tv := unix.NsecToTimeval((250 * time.Millisecond).Nanoseconds())
+	if err := unix.SetsockoptTimeval(fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &tv); err != nil {
+		return nil, err
+	}
+
+	if err := mgmtSend(fd, mgmtOpStartDiscovery, index, []byte{mgmtDiscoveryAll}); err != nil {
+		return nil, err
+	}
+	defer mgmtSend(fd, mgmtOpStopDiscovery, index, []byte{mgmtDiscoveryAll})
+
+	found := map[string]*BluetoothDevice{}
+	var order []string
+	started := false
+	deadline := time.Now().Add(duration)
+	buf := make([]byte, 1024)
+
+	for time.Now().Before(deadline) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		n, err := unix.Read(fd, buf)
+		if err != nil {
+			if isTimeout(err) {
+				continue
+			}
+			return nil, err
+		}
+		if n < 6 {
+			continue
+		}
+		event := binary.LittleEndian.Uint16(buf[0:])
+		evIndex := binary.LittleEndian.Uint16(buf[2:])
+		params := buf[6:n]
+		if evIndex != index {
+			continue
+		}
+
+		switch event {
+		case mgmtEvCmdComplete, mgmtEvCmdStatus:
+			if len(params) < 3 || binary.LittleEndian.Uint16(params) != mgmtOpStartDiscovery {
+				continue
+			}
+			// "occupé" : une découverte tourne déjà (bluetoothd), on en profite
+			if status := params[2]; status != 0 && status != mgmtStatusBusy {
+				return nil, fmt.Errorf("découverte %s : %s", name, mgmtStatus(status))
+			}
+			started = true
+
+		case mgmtEvDeviceFound:
+			// addr(6) addr_type rssi flags(4) eir_len(2) eir
+			if len(params) < 14 {
+				continue
+			}
+			dev := BluetoothDevice{
+				Address: bdaddrString(params[0:6]),
+				LE:      params[6] != mgmtAddrBREDR,
+				RSSI:    int(int8(params[7])),
+			}
+			eirLen := int(binary.LittleEndian.Uint16(params[12:]))
+			if 14+eirLen <= len(params) {
+				dev.Name = eirName(params[14 : 14+eirLen])
+			}
+			if prev, ok := found[dev.Address]; ok {
+				prev.RSSI = max(prev.RSSI, dev.RSSI)
+				if prev.Name == "" {
+					prev.Name = dev.Name
+				}
+				continue
+			}
+			found[dev.Address] = &dev
+			order = append(order, dev.Address)
+		}
+	}
+	if !started {
+		return nil, fmt.Errorf("découverte %s : pas de réponse de l'adaptateur", name)
+	}
+
+	devices := make([]BluetoothDevice, 0, len(order))
+	for _, addr := range order {
+		devices = append(devices, *found[addr])
+	}
+	return devices, nil
+}
+
+func mgmtSend(fd int, opcode, index uint16, params []byte) error {
+	msg := make([]byte, 6, 6+len(params))
+	binary.LittleEndian.PutUint16(msg[0:], opcode)
+	binary.LittleEndian.PutUint16(msg[2:], index)
+	binary.LittleEndian.PutUint16(msg[4:], uint16(len(params)))
+	_, err := unix.Write(fd, append(msg, params...))
+	return err
+}
+
+func mgmtStatus(status uint8) string {
+	if text, ok := mgmtStatusText[status]; ok {
+		return text
+	}
+	return fmt.Sprintf("statut 0x%02x", status)
+}
+
+// bdaddrString formate une adresse stockée octet de poids faible en tête
+func bdaddrString(b []byte) string {
+	parts := make([]string, len(b))
+	for i, v := range b {
+		parts[len(b)-1-i] = fmt.Sprintf("%02x", v)
+	}
+	return strings.Join(parts, ":")
+}
+
+// eirName extrait le nom annoncé des données EIR / advertising
+func eirName(eir []byte) string {
+	short := ""
+	for len(eir) >= 2 {
+		l := int(eir[0])
+		if l == 0 || 1+l > len(eir) {
+			break
+		}
+		switch eir[1] {
+		case eirNameComplete:
+			return string(eir[2 : 1+l])
+		case eirNameShort:
+			short = string(eir[2 : 1+l])
+		}
+		eir = eir[1+l:]
+	}
+	return short
+}
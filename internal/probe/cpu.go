@@ -3,7 +3,6 @@ package probe
 import (
 	"bufio"
 	"fmt"
-	"os"
 	"path/filepath"
 	"runtime"
 	"strconv"
@@ -38,11 +37,7 @@ type CacheID struct {
 
 // readFile lit un fichier et retourne son contenu nettoyé
 func readFile(path string) (string, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return "", err
-	}
-	return strings.TrimSpace(string(data)), nil
+	return rootFS.ReadFile(path)
 }
 
 // readLevel lit le niveau de cache
@@ -108,13 +103,13 @@ func getTotalCacheSize() (int64, error) {
 	var totalCache int64
 	seenCaches := make(map[CacheID]struct{}) // struct{} au lieu de bool
 
-	cpuPaths, err := filepath.Glob(filepath.Join(pathSystemCpu, "cpu[0-9]*"))
+	cpuPaths, err := rootFS.Glob(filepath.Join(pathSystemCpu, "cpu[0-9]*"))
 	if err != nil {
 		return 0, fmt.Errorf("glob CPUs: %w", err)
 	}
 
 	for _, cpu := range cpuPaths {
-		indexPaths, err := filepath.Glob(filepath.Join(cpu, "cache", "index*"))
+		indexPaths, err := rootFS.Glob(filepath.Join(cpu, "cache", "index*"))
 		if err != nil {
 			continue
 		}
@@ -194,7 +189,7 @@ func parseCPUInfo(lines []string) CPUInfo {
 
 // readCPUInfo lit et filtre /proc/cpuinfo avec lecture bufférisée
 func readCPUInfo() ([]string, error) {
-	file, err := os.Open(pathCpuInfo)
+	file, err := rootFS.Open(pathCpuInfo)
 	if err != nil {
 		return nil, fmt.Errorf("ouverture %s: %w", pathCpuInfo, err)
 	}
@@ -227,12 +222,11 @@ func readCPUInfo() ([]string, error) {
 
 // readFreqKHz lit une fréquence en KHz depuis sysfs
 func readFreqKHz(path string) (int64, error) {
-	data, err := os.ReadFile(path)
+	freqStr, err := rootFS.ReadFile(path)
 	if err != nil {
 		return 0, err
 	}
 
-	freqStr := strings.TrimSpace(string(data))
 	return strconv.ParseInt(freqStr, 10, 64)
 }
 
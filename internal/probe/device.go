@@ -0,0 +1,112 @@
+package probe
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// deviceIdentity is the hardware behind a class device (net, bluetooth).
+type deviceIdentity struct {
+	Driver   string // kernel driver, e.g. "e1000e", "btusb"
+	Bus      string // "pci", "usb", "sdio", "serial"...
+	VendorID string // e.g. "0x8086"
+	DeviceID string // e.g. "0x15bc"
+	Vendor   string // e.g. "Intel Corporation"
+	Product  string
+}
+
+// readDeviceIdentity follows the device link of a class entry such as
+// /sys/class/net/eth0 or /sys/class/bluetooth/hci0. It returns false for
+// virtual devices, which have no device link.
+func readDeviceIdentity(classPath string) (deviceIdentity, bool) {
+	var id deviceIdentity
+	entryPath, err := resolveLink(classPath)
+	if err != nil {
+		entryPath = classPath
+	}
+	devicePath, err := resolveLink(filepath.Join(entryPath, "device"))
+	if err != nil {
+		return id, false
+	}
+
+	if driver, err := resolveLink(filepath.Join(devicePath, "driver")); err == nil {
+		id.Driver = filepath.Base(driver)
+	}
+
+	// virtio-net and SDIO functions sit below the PCI or MMC device that
+	// actually identifies the hardware.
+	bus := linkBase(filepath.Join(devicePath, "subsystem"))
+	if bus != "pci" && bus != "usb" {
+		if parent := linkBase(filepath.Join(filepath.Dir(devicePath), "subsystem")); parent == "pci" {
+			devicePath, bus = filepath.Dir(devicePath), parent
+		}
+	}
+	id.Bus = bus
+
+	switch bus {
+	case "pci":
+		id.VendorID, _ = rootFS.ReadFileOptional(filepath.Join(devicePath, "vendor"))
+		id.DeviceID, _ = rootFS.ReadFileOptional(filepath.Join(devicePath, "device"))
+		if dev, err := readLspci(filepath.Base(devicePath)); err == nil {
+			id.Vendor = stripPCIID(dev.Vendor)
+			id.Product = stripPCIID(dev.Model)
+		}
+
+	case "usb":
+		// Class devices hang off a USB interface (1-1:1.0); the
+		// descriptors are on its parent USB device.
+		usbPath := devicePath
+		if _, err := rootFS.Stat(filepath.Join(usbPath, "idVendor")); err != nil {
+			usbPath = filepath.Dir(devicePath)
+		}
+		if v, err := rootFS.ReadFileOptional(filepath.Join(usbPath, "idVendor")); err == nil && v != "" {
+			id.VendorID = "0x" + v
+		}
+		if v, err := rootFS.ReadFileOptional(filepath.Join(usbPath, "idProduct")); err == nil && v != "" {
+			id.DeviceID = "0x" + v
+		}
+		id.Vendor, _ = rootFS.ReadFileOptional(filepath.Join(usbPath, "manufacturer"))
+		id.Product, _ = rootFS.ReadFileOptional(filepath.Join(usbPath, "product"))
+	}
+	return id, true
+}
+
+// resolveLink follows one symlink and returns the logical target path.
+func resolveLink(p string) (string, error) {
+	target, err := rootFS.Readlink(p)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(p), target)
+	}
+	return target, nil
+}
+
+func linkBase(p string) string {
+	target, err := rootFS.Readlink(p)
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(target)
+}
+
+// stripPCIID removes the " [8086]" suffix that lspci -nn appends.
+func stripPCIID(s string) string {
+	if i := strings.LastIndex(s, " ["); i > 0 && strings.HasSuffix(s, "]") {
+		return s[:i]
+	}
+	return s
+}
+
+// shortVendor drops corporate suffixes: "Intel Corporation" → "Intel",
+// "Realtek Semiconductor Co., Ltd." → "Realtek".
+func shortVendor(vendor string) string {
+	for _, suffix := range []string{
+		" Corporation", " Corp.", ", Inc.", " Inc.", " Co., Ltd.", " Co., Ltd",
+		" Semiconductor", " Technology", " Technologies", " Communications",
+	} {
+		vendor = strings.TrimSuffix(vendor, suffix)
+	}
+	return vendor
+}
@@ -14,12 +14,22 @@ const (
 	sectorSizeBytes = 512
 )
 
+// Bus d'attache d'un disque
+const (
+	BusNVMe = "NVMe"
+	BusSATA = "SATA"
+	BusUSB  = "USB"
+	BusMMC  = "MMC"
+)
+
 // DiskInfo contient les informations d'un disque
 type DiskInfo struct {
 	Name       string   // Nom du périphérique (ex: "sda", "nvme0n1")
 	Vendor     string   // Fabricant (ex: "Samsung", peut être vide)
 	Model      string   // Modèle (ex: "860 EVO", peut être vide)
+	Serial     string   // Numéro de série (ex: "S3Z9NB0K123456A", peut être vide)
 	Type       string   // Type de disque : "SSD" ou "HDD"
+	Bus        string   // Bus d'attache : "NVMe", "SATA", "USB", "MMC" ou "" si inconnu
 	SizeBytes  int64    // Taille totale en octets
 	Partitions []string // Liste des partitions (ex: ["nvme0n1p1", "nvme0n1p2"])
 }
@@ -53,6 +63,9 @@ func GetDiskInfo(diskName string) (*DiskInfo, error) {
 	info.Vendor = vendor // 4. Lire vendor
 	info.Model = model   // 5. Lire modèle
 
+	info.Serial = readDiskSerial(diskName) // 6. Lire numéro de série (optionnel)
+	info.Bus = readDiskBus(diskName)       // 7. Déduire le bus (optionnel)
+
 	// Ajouter les partitions
 	partitions, err := listPartitions(diskName)
 	if err != nil {
@@ -65,7 +78,7 @@ func GetDiskInfo(diskName string) (*DiskInfo, error) {
 }
 
 func ListDisks() ([]string, error) {
-	entries, err := os.ReadDir(pathRoot)
+	entries, err := rootFS.ReadDir(pathRoot)
 	if err != nil {
 		return nil, fmt.Errorf("lecture %s: %w", pathRoot, err)
 	}
@@ -85,7 +98,7 @@ func ListDisks() ([]string, error) {
 		// Vérifier si c'est un symlink pointant vers un répertoire
 		if entry.Type()&os.ModeSymlink != 0 {
 			fullPath := filepath.Join(pathRoot, name)
-			info, err := os.Stat(fullPath)
+			info, err := rootFS.Stat(fullPath)
 			if err != nil || !info.IsDir() {
 				continue
 			}
@@ -102,7 +115,7 @@ func ListDisks() ([]string, error) {
 func listPartitions(diskName string) ([]string, error) {
 	diskPath := filepath.Join(pathRoot, diskName)
 
-	entries, err := os.ReadDir(diskPath)
+	entries, err := rootFS.ReadDir(diskPath)
 	if err != nil {
 		return nil, fmt.Errorf("lecture %s: %w", diskPath, err)
 	}
@@ -141,12 +154,11 @@ func validateDiskName(diskName string) error {
 
 func readDiskSize(diskName string) (int64, error) {
 	path := filepath.Join(pathRoot, diskName, "size")
-	data, err := os.ReadFile(path)
+	dataStr, err := rootFS.ReadFile(path)
 	if err != nil {
 		return 0, fmt.Errorf("lecture %s: %w", path, err)
 	}
 
-	dataStr := strings.TrimSpace(string(data))
 	dataInt, err := strconv.ParseInt(dataStr, 10, 64)
 	if err != nil {
 		return 0, fmt.Errorf("conversion taille disque %s: %w", diskName, err)
@@ -158,11 +170,10 @@ func readDiskSize(diskName string) (int64, error) {
 
 func readDiskType(diskName string) (string, error) {
 	path := filepath.Join(pathRoot, diskName, "queue", "rotational")
-	data, err := os.ReadFile(path)
+	dataStr, err := rootFS.ReadFile(path)
 	if err != nil {
 		return "", fmt.Errorf("lecteur %s : %w", path, err)
 	}
-	dataStr := strings.TrimSpace(string(data))
 	typeDIsk := "HDD"
 	if dataStr == "0" {
 		typeDIsk = "SSD"
@@ -177,19 +188,18 @@ func readModelAndVendor(diskName string) (string, string, error) {
 
 	// Lecture vendor (optionnel)
 	vendor := ""
-	if data, err := os.ReadFile(vendorPath); err == nil {
-		vendor = strings.TrimSpace(string(data))
+	if data, err := rootFS.ReadFile(vendorPath); err == nil {
+		vendor = data
 	} else if !os.IsNotExist(err) {
 		// Si erreur autre que fichier absent, renvoyer erreur
 		return "", "", fmt.Errorf("lecture vendor %s: %w", vendorPath, err)
 	}
 
 	// Lecture modèle (obligatoire)
-	modelData, err := os.ReadFile(modelPath)
+	modelStr, err := rootFS.ReadFile(modelPath)
 	if err != nil {
 		return "", "", fmt.Errorf("lecture model %s: %w", modelPath, err)
 	}
-	modelStr := strings.TrimSpace(string(modelData))
 
 	// Si vendor vide, tenter d'extraire vendor + modèle à partir de modelStr
 	if vendor == "" {
@@ -204,3 +214,50 @@ func readModelAndVendor(diskName string) (string, string, error) {
 
 	return vendor, modelStr, nil
 }
+
+// readDiskSerial lit device/serial (NVMe, virtio) ou, à défaut, la page
+// VPD 0x80 « Unit Serial Number » exposée par le noyau pour SCSI/SATA.
+// Retourne "" si aucun numéro n'est disponible.
+func readDiskSerial(diskName string) string {
+	deviceDir := filepath.Join(pathRoot, diskName, "device")
+
+	if serial, err := rootFS.ReadFileOptional(filepath.Join(deviceDir, "serial")); err == nil && serial != "" {
+		return strings.TrimSpace(serial)
+	}
+
+	// En-tête VPD de 4 octets : qualificatif, code page, longueur (big-endian)
+	vpd, err := rootFS.ReadFileOptional(filepath.Join(deviceDir, "vpd_pg80"))
+	if err != nil || len(vpd) < 4 || vpd[1] != 0x80 {
+		return ""
+	}
+	length := int(vpd[2])<<8 | int(vpd[3])
+	if 4+length > len(vpd) {
+		length = len(vpd) - 4
+	}
+	return strings.TrimSpace(strings.Trim(vpd[4:4+length], "\x00"))
+}
+
+// readDiskBus déduit le bus du chemin du périphérique dans /sys/devices
+// (le lien /sys/block/<disk> traverse le contrôleur USB, ATA...).
+// Retourne "" si le bus n'est pas reconnu (virtio, SCSI, SAS...).
+func readDiskBus(diskName string) string {
+	switch {
+	case strings.HasPrefix(diskName, "nvme"):
+		return BusNVMe
+	case strings.HasPrefix(diskName, "mmcblk"):
+		return BusMMC
+	}
+
+	target, err := rootFS.Readlink(filepath.Join(pathRoot, diskName))
+	if err != nil {
+		return ""
+	}
+	switch {
+	case strings.Contains(target, "/usb"):
+		return BusUSB
+	case strings.Contains(target, "/ata"):
+		return BusSATA
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,197 @@
+package probe
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gobox/internal/blockdev"
+)
+
+const (
+	HealthSourceATA  = "ATA SMART"
+	HealthSourceNVMe = "NVMe SMART/Health"
+
+	smartPageSize   = 512
+	smartAttrOffset = 2
+	smartAttrCount  = 30
+	smartAttrSize   = 12
+	smartTimeout    = 10 * time.Second
+)
+
+// Attributs ATA SMART exploités (identifiants usuels, non normalisés par l'ATA).
+const (
+	attrReallocatedSectors = 5
+	attrPowerOnHours       = 9
+	attrPowerCycleCount    = 12
+	attrUnexpectedPowerOff = 174 // SSD
+	attrWearLevelingCount  = 177 // Samsung
+	attrReportedUncorrect  = 187
+	attrAirflowTemperature = 190
+	attrPowerOffRetract    = 192 // HDD : arrêts brutaux
+	attrTemperature        = 194
+	attrPendingSectors     = 197
+	attrOfflineUncorrect   = 198
+	attrSSDLifeLeft        = 231
+	attrMediaWearout       = 233 // Intel
+)
+
+// DiskHealth contient l'état de santé remonté par le firmware du disque
+type DiskHealth struct {
+	Source             string // HealthSourceATA ou HealthSourceNVMe
+	PowerOnHours       int64  // Heures de fonctionnement
+	PowerCycles        int64  // Nombre d'allumages
+	ReallocatedSectors int64  // ATA attr 5
+	PendingSectors     int64  // ATA attr 197 (secteurs instables en attente)
+	Uncorrectable      int64  // ATA attr 198/187
+	PercentageUsed     int    // Usure estimée (NVMe, ou attributs SSD ATA), 0 si inconnu
+	AvailableSpare     int    // NVMe : réserve disponible (%), 0 si inconnu
+	MediaErrors        int64  // NVMe : erreurs média/intégrité
+	UnsafeShutdowns    int64  // Coupures sans arrêt propre
+	TemperatureC       int    // Température actuelle (°C), 0 si inconnue
+	CriticalWarning    uint8  // NVMe : bits d'alerte critique
+}
+
+// GetDiskHealth lit les données SMART (ATA via SG_IO) ou le journal
+// SMART/Health NVMe (log 0x02) directement depuis /dev, sans smartctl.
+// Nécessite les droits root.
+func GetDiskHealth(diskName string) (*DiskHealth, error) {
+	if err := validateDiskName(diskName); err != nil {
+		return nil, err
+	}
+	if !rootFS.IsHost() {
+		return nil, errors.New("santé disque indisponible hors système réel (rejeu)")
+	}
+
+	dev, err := blockdev.Open(diskName, os.O_RDONLY)
+	if err != nil {
+		return nil, err
+	}
+	defer dev.Close()
+
+	if strings.HasPrefix(diskName, "nvme") {
+		page := make([]byte, smartPageSize)
+		if err := dev.NVMeGetLogPage(blockdev.NVMeLogSMART, blockdev.NVMeNSAll, page); err != nil {
+			return nil, fmt.Errorf("lecture log SMART NVMe %s: %w", diskName, err)
+		}
+		return ParseNVMeSmartLog(page)
+	}
+
+	page := make([]byte, smartPageSize)
+	cmd := blockdev.ATACommand{
+		Command:  0xB0, // SMART
+		Features: 0xD0, // READ DATA
+		Count:    1,
+		LBA:      0xC24F00, // signature SMART : LBA mid 0x4F, high 0xC2
+		Protocol: blockdev.ATAProtoPIOIn,
+	}
+	if _, err := dev.ATAPassThrough(cmd, page, smartTimeout); err != nil {
+		return nil, fmt.Errorf("lecture SMART ATA %s: %w", diskName, err)
+	}
+	return ParseATASmartData(page)
+}
+
+// ParseATASmartData décode la page SMART READ DATA (512 octets).
+func ParseATASmartData(page []byte) (*DiskHealth, error) {
+	if len(page) < smartPageSize {
+		return nil, fmt.Errorf("page SMART tronquée (%d octets)", len(page))
+	}
+
+	var sum byte
+	for _, b := range page[:smartPageSize] {
+		sum += b
+	}
+	if sum != 0 {
+		return nil, errors.New("checksum page SMART invalide")
+	}
+
+	health := &DiskHealth{Source: HealthSourceATA}
+
+	attributes := 0
+	for i := range smartAttrCount {
+		attr := page[smartAttrOffset+i*smartAttrSize:][:smartAttrSize]
+		id := attr[0]
+		if id == 0 {
+			continue
+		}
+		attributes++
+		current := int(attr[3])
+		raw := attr[5:11]
+		raw48 := int64(raw[0]) | int64(raw[1])<<8 | int64(raw[2])<<16 |
+			int64(raw[3])<<24 | int64(raw[4])<<32 | int64(raw[5])<<40
+		raw32 := raw48 & 0xFFFFFFFF
+
+		switch id {
+		case attrReallocatedSectors:
+			health.ReallocatedSectors = raw32
+		case attrPowerOnHours:
+			// Certains firmwares stockent les minutes dans les octets hauts
+			health.PowerOnHours = raw32
+		case attrPowerCycleCount:
+			health.PowerCycles = raw32
+		case attrUnexpectedPowerOff, attrPowerOffRetract:
+			health.UnsafeShutdowns = max(health.UnsafeShutdowns, raw32)
+		case attrReportedUncorrect, attrOfflineUncorrect:
+			health.Uncorrectable = max(health.Uncorrectable, raw32)
+		case attrPendingSectors:
+			health.PendingSectors = raw32
+		case attrTemperature:
+			health.TemperatureC = int(raw[0])
+		case attrAirflowTemperature:
+			if health.TemperatureC == 0 {
+				health.TemperatureC = int(raw[0])
+			}
+		case attrWearLevelingCount, attrSSDLifeLeft, attrMediaWearout:
+			// Valeur normalisée 100 → 0 au fil de l'usure
+			if current > 0 && current <= 100 {
+				health.PercentageUsed = max(health.PercentageUsed, 100-current)
+			}
+		}
+	}
+
+	// Une page sans aucun attribut (tout à zéro, checksum compris) vient
+	// d'un pont USB ou d'un firmware qui ne renseigne rien : elle ne
+	// prouve pas un disque sain.
+	if attributes == 0 {
+		return nil, errors.New("page SMART vide : aucun attribut renseigné")
+	}
+	return health, nil
+}
+
+// ParseNVMeSmartLog décode le journal SMART/Health Information (log 0x02).
+func ParseNVMeSmartLog(page []byte) (*DiskHealth, error) {
+	if len(page) < smartPageSize {
+		return nil, fmt.Errorf("log SMART NVMe tronqué (%d octets)", len(page))
+	}
+
+	health := &DiskHealth{
+		Source:          HealthSourceNVMe,
+		CriticalWarning: page[0],
+		AvailableSpare:  int(page[3]),
+		PercentageUsed:  int(page[5]),
+		PowerCycles:     le128(page[112:128]),
+		PowerOnHours:    le128(page[128:144]),
+		UnsafeShutdowns: le128(page[144:160]),
+		MediaErrors:     le128(page[160:176]),
+	}
+
+	// Température composite en kelvins
+	if kelvin := int(binary.LittleEndian.Uint16(page[1:3])); kelvin > 0 {
+		health.TemperatureC = kelvin - 273
+	}
+
+	return health, nil
+}
+
+// le128 lit un compteur NVMe 128 bits little-endian, saturé à int64.
+func le128(b []byte) int64 {
+	low := binary.LittleEndian.Uint64(b[:8])
+	high := binary.LittleEndian.Uint64(b[8:16])
+	if high != 0 || low > 1<<63-1 {
+		return 1<<63 - 1
+	}
+	return int64(low)
+}
@@ -0,0 +1,125 @@
+package probe
+
+import (
+	"os"
+	"testing"
+)
+
+func readPageFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	page, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return page
+}
+
+func TestParseATASmartData(t *testing.T) {
+	fixture := readPageFixture(t, "smart-ata.bin")
+
+	// Page modifiée sans recalcul du checksum
+	corrupted := append([]byte(nil), fixture...)
+	corrupted[2+1*12+5]++ // heures de fonctionnement
+	// Checksum mis à zéro : ne doit plus être accepté comme « non renseigné »
+	zeroChecksum := append([]byte(nil), fixture...)
+	zeroChecksum[511] = 0
+
+	tests := []struct {
+		name    string
+		page    []byte
+		want    *DiskHealth
+		wantErr bool
+	}{
+		{
+			name: "Samsung SATA SSD",
+			page: fixture,
+			want: &DiskHealth{
+				Source:             HealthSourceATA,
+				PowerOnHours:       12345,
+				PowerCycles:        1500,
+				ReallocatedSectors: 8,
+				PendingSectors:     2,
+				Uncorrectable:      1,
+				PercentageUsed:     7,
+				UnsafeShutdowns:    40,
+				TemperatureC:       37, // attribut 194 prioritaire sur 190
+			},
+		},
+		// Checksum valide mais aucun attribut : ne doit pas être noté A
+		{name: "all-zero page", page: make([]byte, 512), wantErr: true},
+		{name: "bad checksum", page: corrupted, wantErr: true},
+		{name: "zeroed checksum byte", page: zeroChecksum, wantErr: true},
+		{name: "truncated", page: fixture[:256], wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseATASmartData(tt.page)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %+v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseATASmartData: %v", err)
+			}
+			if *got != *tt.want {
+				t.Errorf("got  %+v\nwant %+v", *got, *tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNVMeSmartLog(t *testing.T) {
+	fixture := readPageFixture(t, "smart-nvme.bin")
+
+	// Compteur 128 bits au-delà d'int64 : saturé plutôt que négatif
+	saturated := append([]byte(nil), fixture...)
+	saturated[128+8] = 1
+
+	warning := append([]byte(nil), fixture...)
+	warning[0] = 0x04 // fiabilité dégradée
+
+	base := DiskHealth{
+		Source:          HealthSourceNVMe,
+		PowerOnHours:    4321,
+		PowerCycles:     1500,
+		PercentageUsed:  3,
+		AvailableSpare:  100,
+		UnsafeShutdowns: 25,
+		TemperatureC:    37,
+	}
+	withPOH := base
+	withPOH.PowerOnHours = 1<<63 - 1
+	withWarning := base
+	withWarning.CriticalWarning = 0x04
+
+	tests := []struct {
+		name    string
+		page    []byte
+		want    DiskHealth
+		wantErr bool
+	}{
+		{name: "Samsung PM981", page: fixture, want: base},
+		{name: "saturated counter", page: saturated, want: withPOH},
+		{name: "critical warning", page: warning, want: withWarning},
+		{name: "truncated", page: fixture[:128], wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseNVMeSmartLog(tt.page)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("got %+v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseNVMeSmartLog: %v", err)
+			}
+			if *got != tt.want {
+				t.Errorf("got  %+v\nwant %+v", *got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,544 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// LinkSettings is the negotiated and advertised state of an Ethernet link,
+// read with ETHTOOL_GLINKSETTINGS.
+type LinkSettings struct {
+	SpeedMbps    int      // negotiated speed, 0 if unknown or no link
+	Duplex       string   // "full", "half" or ""
+	Autoneg      bool     // autonegotiation enabled
+	Supported    []string // link modes the NIC supports, e.g. "1000baseT/Full"
+	Advertised   []string // link modes we advertise
+	Partner      []string // link modes advertised by the link partner (may be empty)
+	MaxSupported int      // fastest supported mode in Mbps
+	MaxAdvertise int      // fastest advertised mode in Mbps
+	MaxPartner   int      // fastest partner mode in Mbps, 0 if unknown
+}
+
+// ethtoolLinkModes names the ETHTOOL_LINK_MODE_*_BIT bits (uapi/linux/ethtool.h).
+// Bits that are not a speed/duplex pair (port types, pause, FEC) are left
+// empty.
+var ethtoolLinkModes = []string{
+	0: "10baseT/Half", 1: "10baseT/Full", 2: "100baseT/Half", 3: "100baseT/Full",
+	4: "1000baseT/Half", 5: "1000baseT/Full", 12: "10000baseT/Full", 15: "2500baseX/Full",
+	17: "1000baseKX/Full", 18: "10000baseKX4/Full", 19: "10000baseKR/Full",
+	21: "20000baseMLD2/Full", 22: "20000baseKR2/Full", 23: "40000baseKR4/Full",
+	24: "40000baseCR4/Full", 25: "40000baseSR4/Full", 26: "40000baseLR4/Full",
+	27: "56000baseKR4/Full", 28: "56000baseCR4/Full", 29: "56000baseSR4/Full",
+	30: "56000baseLR4/Full", 31: "25000baseCR/Full", 32: "25000baseKR/Full",
+	33: "25000baseSR/Full", 34: "50000baseCR2/Full", 35: "50000baseKR2/Full",
+	36: "100000baseKR4/Full", 37: "100000baseSR4/Full", 38: "100000baseCR4/Full",
+	39: "100000baseLR4_ER4/Full", 40: "50000baseSR2/Full", 41: "1000baseX/Full",
+	42: "10000baseCR/Full", 43: "10000baseSR/Full", 44: "10000baseLR/Full",
+	45: "10000baseLRM/Full", 46: "10000baseER/Full", 47: "2500baseT/Full",
+	48: "5000baseT/Full", 52: "50000baseKR/Full", 53: "50000baseSR/Full",
+	54: "50000baseCR/Full", 55: "50000baseLR_ER_FR/Full", 56: "50000baseDR/Full",
+	57: "100000baseKR2/Full", 58: "100000baseSR2/Full", 59: "100000baseCR2/Full",
+	60: "100000baseLR2_ER2_FR2/Full", 61: "100000baseDR2/Full", 62: "200000baseKR4/Full",
+	63: "200000baseSR4/Full", 64: "200000baseLR4_ER4_FR4/Full", 65: "200000baseDR4/Full",
+	66: "200000baseCR4/Full", 67: "100baseT1/Full", 68: "1000baseT1/Full",
+	69: "400000baseKR8/Full", 70: "400000baseSR8/Full", 71: "400000baseLR8_ER8_FR8/Full",
+	72: "400000baseDR8/Full", 73: "400000baseCR8/Full", 75: "100000baseKR/Full",
+	76: "100000baseSR/Full", 77: "100000baseLR_ER_FR/Full", 78: "100000baseCR/Full",
+	79: "100000baseDR/Full", 80: "200000baseKR2/Full", 81: "200000baseSR2/Full",
+	82: "200000baseLR2_ER2_FR2/Full", 83: "200000baseDR2/Full", 84: "200000baseCR2/Full",
+	85: "400000baseKR4/Full", 86: "400000baseSR4/Full", 87: "400000baseLR4_ER4_FR4/Full",
+	88: "400000baseDR4/Full", 89: "400000baseCR4/Full", 90: "100baseFX/Half",
+	91: "100baseFX/Full",
+}
+
+// ethtoolLinkSettingsHeader is struct ethtool_link_settings without its
+// trailing link_mode_masks[] flexible array (48 bytes).
+type ethtoolLinkSettingsHeader struct {
+	Cmd             uint32
+	Speed           uint32
+	Duplex          uint8
+	Port            uint8
+	PhyAddress      uint8
+	Autoneg         uint8
+	MdioSupport     uint8
+	EthTpMdix       uint8
+	EthTpMdixCtrl   uint8
+	LinkModeNwords  int8
+	Transceiver     uint8
+	MasterSlaveCfg  uint8
+	MasterSlaveStat uint8
+	RateMatching    uint8
+	Reserved        [7]uint32
+}
+
+// Values of ethtool_link_settings fields (uapi/linux/ethtool.h).
+const (
+	ethtoolDuplexHalf    = 0x00
+	ethtoolDuplexFull    = 0x01
+	ethtoolAutonegEnable = 0x01
+	ethtoolSpeedUnknown  = ^uint32(0)
+)
+
+// maxLinkModeNwords bounds the mask size (the kernel uses 3 words today).
+const maxLinkModeNwords = 8
+
+type ethtoolLinkSettings struct {
+	ethtoolLinkSettingsHeader
+	Masks [3 * maxLinkModeNwords]uint32 // supported, advertising, lp_advertising
+}
+
+// ifreqPtr is struct ifreq carrying a pointer in its union (SIOCETHTOOL).
+type ifreqPtr struct {
+	Name [unix.IFNAMSIZ]byte
+	Data unsafe.Pointer
+	_    [24 - unsafe.Sizeof(uintptr(0))]byte
+}
+
+// ethtoolIoctl issues SIOCETHTOOL on name with data as the command buffer.
+func ethtoolIoctl(name string, data unsafe.Pointer) error {
+	if len(name) >= unix.IFNAMSIZ {
+		return fmt.Errorf("interface name too long: %q", name)
+	}
+	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(fd)
+
+	var ifr ifreqPtr
+	copy(ifr.Name[:], name)
+	ifr.Data = data
+	if _, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), unix.SIOCETHTOOL, uintptr(unsafe.Pointer(&ifr))); errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// GetLinkSettings reads link modes and negotiated speed of an Ethernet
+// interface. It needs the live system (no replay support).
+func GetLinkSettings(name string) (LinkSettings, error) {
+	if !rootFS.IsHost() {
+		return LinkSettings{}, errors.New("ethtool is not available in replay mode")
+	}
+
+	// Handshake: with nwords = 0 the kernel answers with -nwords.
+	var req ethtoolLinkSettings
+	req.Cmd = unix.ETHTOOL_GLINKSETTINGS
+	if err := ethtoolIoctl(name, unsafe.Pointer(&req)); err != nil {
+		return LinkSettings{}, fmt.Errorf("ETHTOOL_GLINKSETTINGS %s: %w", name, err)
+	}
+	nwords := -int(req.LinkModeNwords)
+	if nwords <= 0 || nwords > maxLinkModeNwords {
+		return LinkSettings{}, fmt.Errorf("ETHTOOL_GLINKSETTINGS %s: unexpected mask size %d", name, nwords)
+	}
+
+	req = ethtoolLinkSettings{}
+	req.Cmd = unix.ETHTOOL_GLINKSETTINGS
+	req.LinkModeNwords = int8(nwords)
+	if err := ethtoolIoctl(name, unsafe.Pointer(&req)); err != nil {
+		return LinkSettings{}, fmt.Errorf("ETHTOOL_GLINKSETTINGS %s: %w", name, err)
+	}
+
+	settings := LinkSettings{
+		Autoneg:    req.Autoneg == ethtoolAutonegEnable,
+		Supported:  linkModeNames(req.Masks[0:nwords]),
+		Advertised: linkModeNames(req.Masks[nwords : 2*nwords]),
+		Partner:    linkModeNames(req.Masks[2*nwords : 3*nwords]),
+	}
+	if req.Speed != 0 && req.Speed != ethtoolSpeedUnknown {
+		settings.SpeedMbps = int(req.Speed)
+	}
+	switch req.Duplex {
+	case ethtoolDuplexFull:
+		settings.Duplex = "full"
+	case ethtoolDuplexHalf:
+		settings.Duplex = "half"
+	}
+	settings.MaxSupported = maxLinkModeSpeed(settings.Supported)
+	settings.MaxAdvertise = maxLinkModeSpeed(settings.Advertised)
+	settings.MaxPartner = maxLinkModeSpeed(settings.Partner)
+	return settings, nil
+}
+
+// linkModeNames decodes a link mode bitmap into mode names.
+func linkModeNames(mask []uint32) []string {
+	var names []string
+	for bit := range len(mask) * 32 {
+		if mask[bit/32]&(1<<(bit%32)) == 0 || bit >= len(ethtoolLinkModes) || ethtoolLinkModes[bit] == "" {
+			continue
+		}
+		names = append(names, ethtoolLinkModes[bit])
+	}
+	return names
+}
+
+// LinkModeSpeed returns the speed in Mbps of a mode name ("2500baseT/Full" → 2500).
+func LinkModeSpeed(mode string) int {
+	digits, _, ok := strings.Cut(mode, "base")
+	if !ok {
+		return 0
+	}
+	speed, _ := strconv.Atoi(digits)
+	return speed
+}
+
+func maxLinkModeSpeed(modes []string) int {
+	best := 0
+	for _, mode := range modes {
+		best = max(best, LinkModeSpeed(mode))
+	}
+	return best
+}
+
+// DriverInfo is what ETHTOOL_GDRVINFO reports about a NIC driver.
+type DriverInfo struct {
+	Driver          string // e.g. "e1000e"
+	Version         string // driver version, often the kernel version
+	FirmwareVersion string // e.g. "0.4-4", empty if not reported
+	BusInfo         string // e.g. "0000:00:1f.6"
+}
+
+// ethtoolDrvinfo is struct ethtool_drvinfo.
+type ethtoolDrvinfo struct {
+	Cmd         uint32
+	Driver      [32]byte
+	Version     [32]byte
+	FwVersion   [32]byte
+	BusInfo     [32]byte
+	EromVersion [32]byte
+	Reserved2   [12]byte
+	NPrivFlags  uint32
+	NStats      uint32
+	TestinfoLen uint32
+	EedumpLen   uint32
+	RegdumpLen  uint32
+}
+
+// GetDriverInfo reads driver and firmware versions of an interface.
+// It needs the live system (no replay support).
+func GetDriverInfo(name string) (DriverInfo, error) {
+	if !rootFS.IsHost() {
+		return DriverInfo{}, errors.New("ethtool is not available in replay mode")
+	}
+
+	req := ethtoolDrvinfo{Cmd: unix.ETHTOOL_GDRVINFO}
+	if err := ethtoolIoctl(name, unsafe.Pointer(&req)); err != nil {
+		return DriverInfo{}, fmt.Errorf("ETHTOOL_GDRVINFO %s: %w", name, err)
+	}
+	info := DriverInfo{
+		Driver:          cString(req.Driver[:]),
+		Version:         cString(req.Version[:]),
+		FirmwareVersion: cString(req.FwVersion[:]),
+		BusInfo:         cString(req.BusInfo[:]),
+	}
+	if info.FirmwareVersion == "N/A" {
+		info.FirmwareVersion = ""
+	}
+	return info, nil
+}
+
+// ═══════════════════════════════════════════════════════════════════
+// WAKE-ON-LAN
+// ═══════════════════════════════════════════════════════════════════
+
+// WakeOnLan lists Wake-on-LAN modes in ethtool letters, e.g. "pumbg".
+type WakeOnLan struct {
+	Supported string // empty if the NIC cannot wake the machine
+	Enabled   string // "" when disabled ("d" in ethtool)
+}
+
+// wolModes are the WAKE_* bits (uapi/linux/ethtool.h) and their letters.
+var wolModes = []struct {
+	bit    uint32
+	letter byte
+}{
+	{1 << 0, 'p'}, // WAKE_PHY
+	{1 << 1, 'u'}, // WAKE_UCAST
+	{1 << 2, 'm'}, // WAKE_MCAST
+	{1 << 3, 'b'}, // WAKE_BCAST
+	{1 << 4, 'a'}, // WAKE_ARP
+	{1 << 5, 'g'}, // WAKE_MAGIC
+	{1 << 6, 's'}, // WAKE_MAGICSECURE
+	{1 << 7, 'f'}, // WAKE_FILTER
+}
+
+// ethtoolWolinfo is struct ethtool_wolinfo.
+type ethtoolWolinfo struct {
+	Cmd       uint32
+	Supported uint32
+	Wolopts   uint32
+	Sopass    [6]byte
+}
+
+// GetWakeOnLan reads Wake-on-LAN support (ETHTOOL_GWOL). It needs the
+// live system (no replay support).
+func GetWakeOnLan(name string) (WakeOnLan, error) {
+	if !rootFS.IsHost() {
+		return WakeOnLan{}, errors.New("ethtool is not available in replay mode")
+	}
+	req := ethtoolWolinfo{Cmd: unix.ETHTOOL_GWOL}
+	if err := ethtoolIoctl(name, unsafe.Pointer(&req)); err != nil {
+		return WakeOnLan{}, fmt.Errorf("ETHTOOL_GWOL %s: %w", name, err)
+	}
+	return WakeOnLan{Supported: wolLetters(req.Supported), Enabled: wolLetters(req.Wolopts)}, nil
+}
+
+func wolLetters(mask uint32) string {
+	var b strings.Builder
+	for _, m := range wolModes {
+		if mask&m.bit != 0 {
+			b.WriteByte(m.letter)
+		}
+	}
+	return b.String()
+}
+
+// ═══════════════════════════════════════════════════════════════════
+// CABLE TEST
+// ═══════════════════════════════════════════════════════════════════
+
+// CablePair is the cable test result of one twisted pair.
+type CablePair struct {
+	Pair          string // "A" to "D"
+	Status        string // "ok", "open", "short", "cross-short", "unknown"
+	FaultLengthCM int    // distance to the fault, 0 if not reported
+}
+
+var cablePairNames = map[uint8]string{
+	unix.ETHTOOL_A_CABLE_PAIR_A: "A",
+	unix.ETHTOOL_A_CABLE_PAIR_B: "B",
+	unix.ETHTOOL_A_CABLE_PAIR_C: "C",
+	unix.ETHTOOL_A_CABLE_PAIR_D: "D",
+}
+
+var cableResultCodes = map[uint8]string{
+	unix.ETHTOOL_A_CABLE_RESULT_CODE_OK:          "ok",
+	unix.ETHTOOL_A_CABLE_RESULT_CODE_OPEN:        "open",
+	unix.ETHTOOL_A_CABLE_RESULT_CODE_SAME_SHORT:  "short",
+	unix.ETHTOOL_A_CABLE_RESULT_CODE_CROSS_SHORT: "cross-short",
+}
+
+// CableTestTimeout bounds a PHY cable test; PHYs report within seconds.
+const CableTestTimeout = 10 * time.Second
+
+// RunCableTest asks the PHY to test the copper pairs (ethtool --cable-test).
+// The link drops for the duration of the test. It needs CAP_NET_ADMIN, a
+// PHY driver that implements the test, and the live system. Drivers
+// without support return EOPNOTSUPP.
+func RunCableTest(ctx context.Context, name string) ([]CablePair, error) {
+	if !rootFS.IsHost() {
+		return nil, errors.New("ethtool is not available in replay mode")
+	}
+	ifi, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := dialGenl()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+	family, groups, err := c.family(unix.ETHTOOL_GENL_NAME)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := dialGenl()
+	if err != nil {
+		return nil, err
+	}
+	defer events.Close()
+	if err := events.join(groups[unix.ETHTOOL_MCGRP_MONITOR_NAME]); err != nil {
+		return nil, err
+	}
+	if err := events.setTimeout(500 * time.Millisecond); err != nil {
+		return nil, err
+	}
+
+	header := appendAttrUint32(nil, unix.ETHTOOL_A_HEADER_DEV_INDEX, uint32(ifi.Index))
+	if _, err := c.execute(family, unix.ETHTOOL_MSG_CABLE_TEST_ACT, 0,
+		appendAttr(nil, unix.ETHTOOL_A_CABLE_TEST_HEADER|unix.NLA_F_NESTED, header)); err != nil {
+		return nil, fmt.Errorf("cable test %s: %w", name, err)
+	}
+
+	deadline := time.Now().Add(CableTestTimeout)
+	for time.Now().Before(deadline) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		msgs, err := events.next()
+		if err != nil {
+			if isTimeout(err) {
+				continue
+			}
+			return nil, err
+		}
+		for _, m := range msgs {
+			if m.Cmd != unix.ETHTOOL_MSG_CABLE_TEST_NTF || cableTestIfindex(m) != ifi.Index {
+				continue
+			}
+			status, ok := findAttr(m.Attrs, unix.ETHTOOL_A_CABLE_TEST_NTF_STATUS)
+			if !ok || len(status.Value) == 0 || status.Value[0] != unix.ETHTOOL_A_CABLE_TEST_NTF_STATUS_COMPLETED {
+				continue
+			}
+			return parseCableResults(m), nil
+		}
+	}
+	return nil, fmt.Errorf("cable test %s: timed out", name)
+}
+
+func cableTestIfindex(m genlMessage) int {
+	header, ok := findAttr(m.Attrs, unix.ETHTOOL_A_CABLE_TEST_NTF_HEADER)
+	if !ok {
+		return 0
+	}
+	index, ok := findAttr(parseAttrs(header.Value), unix.ETHTOOL_A_HEADER_DEV_INDEX)
+	if !ok {
+		return 0
+	}
+	return int(attrUint32(index))
+}
+
+func parseCableResults(m genlMessage) []CablePair {
+	nest, ok := findAttr(m.Attrs, unix.ETHTOOL_A_CABLE_TEST_NTF_NEST)
+	if !ok {
+		return nil
+	}
+
+	byPair := map[uint8]*CablePair{}
+	var order []uint8
+	pair := func(id uint8) *CablePair {
+		if p, ok := byPair[id]; ok {
+			return p
+		}
+		name, ok := cablePairNames[id]
+		if !ok {
+			name = strconv.Itoa(int(id))
+		}
+		byPair[id] = &CablePair{Pair: name, Status: "unknown"}
+		order = append(order, id)
+		return byPair[id]
+	}
+
+	for _, a := range parseAttrs(nest.Value) {
+		var id uint8
+		var code uint8
+		var cm uint32
+		hasCode := false
+		for _, f := range parseAttrs(a.Value) {
+			switch {
+			case a.Type == unix.ETHTOOL_A_CABLE_NEST_RESULT && f.Type == unix.ETHTOOL_A_CABLE_RESULT_PAIR,
+				a.Type == unix.ETHTOOL_A_CABLE_NEST_FAULT_LENGTH && f.Type == unix.ETHTOOL_A_CABLE_FAULT_LENGTH_PAIR:
+				if len(f.Value) > 0 {
+					id = f.Value[0]
+				}
+			case a.Type == unix.ETHTOOL_A_CABLE_NEST_RESULT && f.Type == unix.ETHTOOL_A_CABLE_RESULT_CODE:
+				if len(f.Value) > 0 {
+					code, hasCode = f.Value[0], true
+				}
+			case a.Type == unix.ETHTOOL_A_CABLE_NEST_FAULT_LENGTH && f.Type == unix.ETHTOOL_A_CABLE_FAULT_LENGTH_CM:
+				cm = attrUint32(f)
+			}
+		}
+		switch a.Type {
+		case unix.ETHTOOL_A_CABLE_NEST_RESULT:
+			if status, ok := cableResultCodes[code]; ok && hasCode {
+				pair(id).Status = status
+			}
+		case unix.ETHTOOL_A_CABLE_NEST_FAULT_LENGTH:
+			pair(id).FaultLengthCM = int(cm)
+		}
+	}
+
+	pairs := make([]CablePair, 0, len(order))
+	for _, id := range order {
+		pairs = append(pairs, *byPair[id])
+	}
+	return pairs
+}
+
+// ═══════════════════════════════════════════════════════════════════
+// STATISTICS
+// ═══════════════════════════════════════════════════════════════════
+
+// NICStats are the error-related interface counters from
+// /sys/class/net/<if>/statistics.
+type NICStats struct {
+	RxPackets      uint64
+	TxPackets      uint64
+	RxErrors       uint64
+	TxErrors       uint64
+	RxDropped      uint64
+	TxDropped      uint64
+	RxCRCErrors    uint64
+	RxFrameErrors  uint64
+	RxMissedErrors uint64
+	TxCarrierErrs  uint64
+	Collisions     uint64
+}
+
+// ReadNICStats reads the interface counters.
+func ReadNICStats(name string) (NICStats, error) {
+	dir := filepath.Join(netRoot, name, "statistics")
+	var s NICStats
+	fields := []struct {
+		file string
+		dst  *uint64
+	}{
+		{"rx_packets", &s.RxPackets},
+		{"tx_packets", &s.TxPackets},
+		{"rx_errors", &s.RxErrors},
+		{"tx_errors", &s.TxErrors},
+		{"rx_dropped", &s.RxDropped},
+		{"tx_dropped", &s.TxDropped},
+		{"rx_crc_errors", &s.RxCRCErrors},
+		{"rx_frame_errors", &s.RxFrameErrors},
+		{"rx_missed_errors", &s.RxMissedErrors},
+		{"tx_carrier_errors", &s.TxCarrierErrs},
+		{"collisions", &s.Collisions},
+	}
+	for _, f := range fields {
+		raw, err := rootFS.ReadFile(filepath.Join(dir, f.file))
+		if err != nil {
+			return NICStats{}, err
+		}
+		if *f.dst, err = strconv.ParseUint(raw, 10, 64); err != nil {
+			return NICStats{}, fmt.Errorf("%s/%s: %w", dir, f.file, err)
+		}
+	}
+	return s, nil
+}
+
+// Sub returns the counter increments since before. Counters that went
+// backwards (driver reset) count as zero.
+func (s NICStats) Sub(before NICStats) NICStats {
+	d := func(a, b uint64) uint64 {
+		if a < b {
+			return 0
+		}
+		return a - b
+	}
+	return NICStats{
+		RxPackets:      d(s.RxPackets, before.RxPackets),
+		TxPackets:      d(s.TxPackets, before.TxPackets),
+		RxErrors:       d(s.RxErrors, before.RxErrors),
+		TxErrors:       d(s.TxErrors, before.TxErrors),
+		RxDropped:      d(s.RxDropped, before.RxDropped),
+		TxDropped:      d(s.TxDropped, before.TxDropped),
+		RxCRCErrors:    d(s.RxCRCErrors, before.RxCRCErrors),
+		RxFrameErrors:  d(s.RxFrameErrors, before.RxFrameErrors),
+		RxMissedErrors: d(s.RxMissedErrors, before.RxMissedErrors),
+		TxCarrierErrs:  d(s.TxCarrierErrs, before.TxCarrierErrs),
+		Collisions:     d(s.Collisions, before.Collisions),
+	}
+}
@@ -0,0 +1,238 @@
+package probe
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// genlConn is a minimal generic netlink socket: enough to resolve a
+// family, run requests and dumps, and listen to a multicast group.
+type genlConn struct {
+	fd  int
+	seq uint32
+	buf []byte
+}
+
+// genlMessage is one generic netlink reply or event.
+type genlMessage struct {
+	Cmd   uint8
+	Attrs []nlAttr
+}
+
+// nlAttr is a raw netlink attribute; the nested and byte-order flags are
+// stripped from Type.
+type nlAttr struct {
+	Type  uint16
+	Value []byte
+}
+
+const (
+	genlRequestTimeout = 5 * time.Second
+
+	sizeofGenlmsghdr = 4      // struct genlmsghdr: cmd, version, reserved
+	nlaTypeMask      = 0x3fff // ^(NLA_F_NESTED | NLA_F_NET_BYTEORDER)
+)
+
+func dialGenl() (*genlConn, error) {
+	fd, err := unix.Socket(unix.AF_NETLINK, unix.SOCK_RAW|unix.SOCK_CLOEXEC, unix.NETLINK_GENERIC)
+	if err != nil {
+		return nil, err
+	}
+	if err := unix.Bind(fd, &unix.SockaddrNetlink{Family: unix.AF_NETLINK}); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+	c := &genlConn{fd: fd, buf: make([]byte, 256<<10)}
+	if err := c.setTimeout(genlRequestTimeout); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *genlConn) Close() error {
+	return unix.Close(c.fd)
+}
+
+func (c *genlConn) setTimeout(d time.Duration) error {
+	tv := unix.NsecToTimeval(d.Nanoseconds())
+	return unix.SetsockoptTimeval(c.fd, unix.SOL_SOCKET, unix.SO_RCVTIMEO, &tv)
+}
+
+// join subscribes the socket to a multicast group (see family).
+func (c *genlConn) join(group uint32) error {
+	return unix.SetsockoptInt(c.fd, unix.SOL_NETLINK, unix.NETLINK_ADD_MEMBERSHIP, int(group))
+}
+
+// execute sends one request and collects its replies. Dumps end with
+// NLMSG_DONE; other requests are sent with NLM_F_ACK and end with the ack.
+func (c *genlConn) execute(family uint16, cmd uint8, flags uint16, attrs []byte) ([]genlMessage, error) {
+	dump := flags&unix.NLM_F_DUMP == unix.NLM_F_DUMP
+	if !dump {
+		flags |= unix.NLM_F_ACK
+	}
+	c.seq++
+
+	msg := make([]byte, unix.NLMSG_HDRLEN+sizeofGenlmsghdr, unix.NLMSG_HDRLEN+sizeofGenlmsghdr+len(attrs))
+	msg[unix.NLMSG_HDRLEN] = cmd
+	msg[unix.NLMSG_HDRLEN+1] = 1 // version
+	msg = append(msg, attrs...)
+	binary.NativeEndian.PutUint32(msg[0:], uint32(len(msg)))
+	binary.NativeEndian.PutUint16(msg[4:], family)
+	binary.NativeEndian.PutUint16(msg[6:], unix.NLM_F_REQUEST|flags)
+	binary.NativeEndian.PutUint32(msg[8:], c.seq)
+
+	if err := unix.Sendto(c.fd, msg, 0, &unix.SockaddrNetlink{Family: unix.AF_NETLINK}); err != nil {
+		return nil, err
+	}
+
+	var out []genlMessage
+	for {
+		msgs, err := c.receive()
+		if err != nil {
+			return out, err
+		}
+		for _, m := range msgs {
+			if m.Header.Seq != c.seq {
+				continue
+			}
+			switch m.Header.Type {
+			case unix.NLMSG_DONE, unix.NLMSG_ERROR:
+				if len(m.Data) >= 4 {
+					if errno := -int32(binary.NativeEndian.Uint32(m.Data)); errno != 0 {
+						return out, syscall.Errno(errno)
+					}
+				}
+				return out, nil
+			default:
+				if g, ok := parseGenlMessage(m.Data); ok {
+					out = append(out, g)
+				}
+			}
+		}
+	}
+}
+
+// next waits for the next multicast event.
+func (c *genlConn) next() ([]genlMessage, error) {
+	msgs, err := c.receive()
+	if err != nil {
+		return nil, err
+	}
+	var out []genlMessage
+	for _, m := range msgs {
+		if g, ok := parseGenlMessage(m.Data); ok && m.Header.Type >= unix.NLMSG_MIN_TYPE {
+			out = append(out, g)
+		}
+	}
+	return out, nil
+}
+
+func (c *genlConn) receive() ([]syscall.NetlinkMessage, error) {
+	n, _, err := unix.Recvfrom(c.fd, c.buf, 0)
+	if err != nil {
+		return nil, err
+	}
+	return syscall.ParseNetlinkMessage(c.buf[:n])
+}
+
+// family resolves a generic netlink family and its multicast groups.
+func (c *genlConn) family(name string) (uint16, map[string]uint32, error) {
+	replies, err := c.execute(unix.GENL_ID_CTRL, unix.CTRL_CMD_GETFAMILY, 0,
+		appendAttr(nil, unix.CTRL_ATTR_FAMILY_NAME, append([]byte(name), 0)))
+	if err != nil {
+		return 0, nil, fmt.Errorf("generic netlink family %s: %w", name, err)
+	}
+
+	var id uint16
+	groups := map[string]uint32{}
+	for _, r := range replies {
+		for _, a := range r.Attrs {
+			switch a.Type {
+			case unix.CTRL_ATTR_FAMILY_ID:
+				id = attrUint16(a)
+			case unix.CTRL_ATTR_MCAST_GROUPS:
+				for _, g := range parseAttrs(a.Value) {
+					var grpName string
+					var grpID uint32
+					for _, ga := range parseAttrs(g.Value) {
+						switch ga.Type {
+						case unix.CTRL_ATTR_MCAST_GRP_NAME:
+							grpName = cString(ga.Value)
+						case unix.CTRL_ATTR_MCAST_GRP_ID:
+							grpID = attrUint32(ga)
+						}
+					}
+					groups[grpName] = grpID
+				}
+			}
+		}
+	}
+	if id == 0 {
+		return 0, nil, fmt.Errorf("generic netlink family %s: not found", name)
+	}
+	return id, groups, nil
+}
+
+func parseGenlMessage(data []byte) (genlMessage, bool) {
+	if len(data) < sizeofGenlmsghdr {
+		return genlMessage{}, false
+	}
+	return genlMessage{Cmd: data[0], Attrs: parseAttrs(data[sizeofGenlmsghdr:])}, true
+}
+
+func parseAttrs(b []byte) []nlAttr {
+	var attrs []nlAttr
+	for len(b) >= unix.SizeofNlAttr {
+		l := int(binary.NativeEndian.Uint16(b[0:]))
+		if l < unix.SizeofNlAttr || l > len(b) {
+			break
+		}
+		attrs = append(attrs, nlAttr{
+			Type:  binary.NativeEndian.Uint16(b[2:]) & nlaTypeMask,
+			Value: b[unix.SizeofNlAttr:l],
+		})
+		b = b[min(nlaAlign(l), len(b)):]
+	}
+	return attrs
+}
+
+func appendAttr(b []byte, typ uint16, value []byte) []byte {
+	l := unix.SizeofNlAttr + len(value)
+	b = binary.NativeEndian.AppendUint16(b, uint16(l))
+	b = binary.NativeEndian.AppendUint16(b, typ)
+	b = append(b, value...)
+	return append(b, make([]byte, nlaAlign(l)-l)...)
+}
+
+func appendAttrUint32(b []byte, typ uint16, v uint32) []byte {
+	return appendAttr(b, typ, binary.NativeEndian.AppendUint32(nil, v))
+}
+
+func nlaAlign(n int) int {
+	return (n + unix.NLA_ALIGNTO - 1) &^ (unix.NLA_ALIGNTO - 1)
+}
+
+func attrUint16(a nlAttr) uint16 {
+	if len(a.Value) < 2 {
+		return 0
+	}
+	return binary.NativeEndian.Uint16(a.Value)
+}
+
+func attrUint32(a nlAttr) uint32 {
+	if len(a.Value) < 4 {
+		return 0
+	}
+	return binary.NativeEndian.Uint32(a.Value)
+}
+
+// isTimeout reports a receive timeout (SO_RCVTIMEO).
+func isTimeout(err error) bool {
+	return errors.Is(err, unix.EAGAIN) || errors.Is(err, unix.EWOULDBLOCK)
+}
@@ -2,8 +2,6 @@ package probe
 
 import (
 	"fmt"
-	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -28,6 +26,8 @@ type GPUReader struct {
 	CardName string
 }
 
+const drmRoot = "/sys/class/drm"
+
 const (
 	VendorNvidia string = "nvidia"
 	VendorIntel  string = "intel"
@@ -45,7 +45,7 @@ var cardRegex = regexp.MustCompile(`^card\d+$`)
 // NewGPUReader crée un lecteur GPU pour la carte spécifiée.
 func NewGPUReader(cardName string) *GPUReader {
 	return &GPUReader{
-		BasePath: "/sys/class/drm",
+		BasePath: drmRoot,
 		CardName: cardName,
 	}
 }
@@ -137,7 +137,7 @@ func readDriverVersion(vendor string) (string, error) {
 		return "", fmt.Errorf("vendor non supporté: %q (attendus: nvidia, intel, amd)", vendor)
 	}
 
-	data, err := os.ReadFile(path)
+	data, err := rootFS.ReadBytes(path)
 	if err != nil {
 		return "", fmt.Errorf("lecture version driver %s depuis %q: %w", v, path, err)
 	}
@@ -166,7 +166,7 @@ func readDriverVersion(vendor string) (string, error) {
 // Extrait le driver, l'ID vendor PCI et l'adresse PCI slot.
 func readUevent(cardPath string) (UeventInfo, error) {
 	ueventPath := filepath.Join(cardPath, "device", "uevent")
-	data, err := os.ReadFile(ueventPath)
+	data, err := rootFS.ReadBytes(ueventPath)
 	if err != nil {
 		return UeventInfo{}, fmt.Errorf("lecture uevent %q: %w", ueventPath, err)
 	}
@@ -227,7 +227,7 @@ func readLspci(pciSlot string) (PCIDevice, error) {
 		)
 	}
 
-	out, err := exec.Command("lspci", "-mm", "-nn", "-D", "-s", pciSlot).Output()
+	out, err := runCommand("lspci", "-mm", "-nn", "-D", "-s", pciSlot)
 	if err != nil {
 		return PCIDevice{}, fmt.Errorf("échec lspci pour slot %q: %w", pciSlot, err)
 	}
@@ -243,23 +243,22 @@ func readLspci(pciSlot string) (PCIDevice, error) {
 	}
 
 	return PCIDevice{
-		Vendor: parts[3],
-		Model:  parts[5],
+		Vendor: stripPCIID(parts[3]),
+		Model:  stripPCIID(parts[5]),
 	}, nil
 }
 
 // listCards retourne les chemins de toutes les cartes DRM du système.
 func listCards() ([]string, error) {
-	const drmPath = "/sys/class/drm/"
-	entries, err := os.ReadDir(drmPath)
+	entries, err := rootFS.ReadDir(drmRoot)
 	if err != nil {
-		return nil, fmt.Errorf("lecture répertoire DRM %q: %w", drmPath, err)
+		return nil, fmt.Errorf("lecture répertoire DRM %q: %w", drmRoot, err)
 	}
 
 	cards := make([]string, 0, len(entries)/2)
 	for _, entry := range entries {
 		if cardRegex.MatchString(entry.Name()) {
-			cards = append(cards, filepath.Join(drmPath, entry.Name()))
+			cards = append(cards, filepath.Join(drmRoot, entry.Name()))
 		}
 	}
 
@@ -269,17 +268,15 @@ func listCards() ([]string, error) {
 // readConnectorStatus lit le statut d'un connecteur DRM.
 func readConnectorStatus(path string) (string, error) {
 	fullPath := filepath.Join(path, "status")
-	data, err := os.ReadFile(fullPath)
+	status, err := rootFS.ReadFile(fullPath)
 	if err != nil {
 		return "", fmt.Errorf("lecture du statut du connecteur %q: %w", path, err)
 	}
-	return strings.TrimSpace(string(data)), nil
+	return status, nil
 }
 
 // listConnectors retourne les connecteurs connectés d'une carte GPU.
 func listConnectors(cardPath string) ([]string, error) {
-	const drmRoot = "/sys/class/drm"
-
 	cleanPath := filepath.Clean(cardPath)
 	if !strings.HasPrefix(cleanPath, drmRoot) {
 		return nil, fmt.Errorf("cardPath invalide (hors de %s): %q", drmRoot, cardPath)
@@ -287,7 +284,7 @@ func listConnectors(cardPath string) ([]string, error) {
 
 	cardName := filepath.Base(cleanPath)
 
-	entries, err := os.ReadDir(drmRoot)
+	entries, err := rootFS.ReadDir(drmRoot)
 	if err != nil {
 		return nil, fmt.Errorf("lecture drm root: %w", err)
 	}
@@ -0,0 +1,148 @@
+package probe
+
+import (
+	"encoding/binary"
+	"net"
+	"strconv"
+	"syscall"
+	"unsafe"
+)
+
+// netlinkLink is what RTM_GETLINK reports for one interface.
+type netlinkLink struct {
+	Name string
+	MTU  int
+}
+
+// netlinkState is a snapshot of links, addresses and default routes,
+// indexed by interface index.
+type netlinkState struct {
+	Links     map[int]netlinkLink
+	Addresses map[int][]string // CIDR, in kernel order
+	Gateways  map[int][]string // next hops of default routes
+}
+
+// readNetlinkState dumps links, addresses and routes over rtnetlink
+// (no dependency on the ip binary). Only valid on the live system.
+func readNetlinkState() (netlinkState, error) {
+	state := netlinkState{
+		Links:     map[int]netlinkLink{},
+		Addresses: map[int][]string{},
+		Gateways:  map[int][]string{},
+	}
+
+	msgs, err := netlinkDump(syscall.RTM_GETLINK, syscall.AF_UNSPEC)
+	if err != nil {
+		return state, err
+	}
+	for _, m := range msgs {
+		if m.Header.Type != syscall.RTM_NEWLINK || len(m.Data) < syscall.SizeofIfInfomsg {
+			continue
+		}
+		info := (*syscall.IfInfomsg)(unsafe.Pointer(&m.Data[0]))
+		attrs, err := syscall.ParseNetlinkRouteAttr(&m)
+		if err != nil {
+			continue
+		}
+		var link netlinkLink
+		for _, a := range attrs {
+			switch a.Attr.Type {
+			case syscall.IFLA_IFNAME:
+				link.Name = cString(a.Value)
+			case syscall.IFLA_MTU:
+				if len(a.Value) >= 4 {
+					link.MTU = int(binary.NativeEndian.Uint32(a.Value))
+				}
+			}
+		}
+		state.Links[int(info.Index)] = link
+	}
+
+	msgs, err = netlinkDump(syscall.RTM_GETADDR, syscall.AF_UNSPEC)
+	if err != nil {
+		return state, err
+	}
+	for _, m := range msgs {
+		if m.Header.Type != syscall.RTM_NEWADDR || len(m.Data) < syscall.SizeofIfAddrmsg {
+			continue
+		}
+		msg := (*syscall.IfAddrmsg)(unsafe.Pointer(&m.Data[0]))
+		attrs, err := syscall.ParseNetlinkRouteAttr(&m)
+		if err != nil {
+			continue
+		}
+		// IFA_LOCAL is the local address on point-to-point links, where
+		// IFA_ADDRESS is the peer; prefer it when present.
+		var ip net.IP
+		for _, a := range attrs {
+			switch a.Attr.Type {
+			case syscall.IFA_LOCAL:
+				ip = net.IP(a.Value)
+			case syscall.IFA_ADDRESS:
+				if ip == nil {
+					ip = net.IP(a.Value)
+				}
+			}
+		}
+		if ip == nil {
+			continue
+		}
+		cidr := ip.String() + "/" + strconv.Itoa(int(msg.Prefixlen))
+		state.Addresses[int(msg.Index)] = append(state.Addresses[int(msg.Index)], cidr)
+	}
+
+	for _, family := range []int{syscall.AF_INET, syscall.AF_INET6} {
+		msgs, err = netlinkDump(syscall.RTM_GETROUTE, family)
+		if err != nil {
+			return state, err
+		}
+		for _, m := range msgs {
+			if m.Header.Type != syscall.RTM_NEWROUTE || len(m.Data) < syscall.SizeofRtMsg {
+				continue
+			}
+			rt := (*syscall.RtMsg)(unsafe.Pointer(&m.Data[0]))
+			if rt.Dst_len != 0 || rt.Table != syscall.RT_TABLE_MAIN || rt.Type != syscall.RTN_UNICAST {
+				continue
+			}
+			attrs, err := syscall.ParseNetlinkRouteAttr(&m)
+			if err != nil {
+				continue
+			}
+			var gw net.IP
+			oif := 0
+			for _, a := range attrs {
+				switch a.Attr.Type {
+				case syscall.RTA_GATEWAY:
+					gw = net.IP(a.Value)
+				case syscall.RTA_OIF:
+					if len(a.Value) >= 4 {
+						oif = int(binary.NativeEndian.Uint32(a.Value))
+					}
+				}
+			}
+			if gw != nil && oif != 0 {
+				state.Gateways[oif] = append(state.Gateways[oif], gw.String())
+			}
+		}
+	}
+
+	return state, nil
+}
+
+// netlinkDump sends an NLM_F_DUMP request and returns the parsed replies.
+func netlinkDump(proto, family int) ([]syscall.NetlinkMessage, error) {
+	rib, err := syscall.NetlinkRIB(proto, family)
+	if err != nil {
+		return nil, err
+	}
+	return syscall.ParseNetlinkMessage(rib)
+}
+
+func cString(b []byte) string {
+	for i, c := range b {
+		if c == 0 {
+			return string(b[:i])
+		}
+	}
+	return string(b)
+}
@@ -2,8 +2,9 @@ package probe
 
 import (
 	"fmt"
-	"os"
+	"net"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"gobox/internal/sysfs"
@@ -20,15 +21,53 @@ type NetworkInterface struct {
 	Speed      string // link speed (e.g., "1000 Mbps")
 	Carrier    bool   // physical signal detected
 	IPAddress  string // current IPv4 address (optional)
+
+	Addresses []string // all addresses in CIDR notation (live system only)
+	MTU       int
+	Gateways  []string // next hops of default routes through this interface
+
+	Driver          string // kernel driver, e.g. "e1000e"
+	DriverVersion   string // live system only
+	FirmwareVersion string // live system only, empty if not reported
+	Bus             string // "pci", "usb", "sdio"... empty for virtual interfaces
+	VendorID        string // e.g. "0x8086"
+	DeviceID        string // e.g. "0x15bc"
+	Vendor          string // e.g. "Intel Corporation"
+	Product         string // e.g. "Ethernet Connection (7) I219-V"
+
+	Link *LinkSettings     // link modes, duplex, autoneg (ethtool, live system only)
+	WoL  *WakeOnLan        // Wake-on-LAN support (ethtool, live system only)
+	Wifi *WifiCapabilities // radio capabilities, Wi-Fi on the live system only
+}
+
+// Model names the adapter for reports, e.g.
+// "Intel Ethernet Connection (7) I219-V (e1000e)". Falls back to the
+// interface name when the hardware could not be identified.
+func (n NetworkInterface) Model() string {
+	name := strings.TrimSpace(shortVendor(n.Vendor) + " " + n.Product)
+	if name == "" {
+		name = n.Name
+	}
+	if n.Driver != "" {
+		name += " (" + n.Driver + ")"
+	}
+	return name
 }
 
 // ListNetworkInterfaces lists all physical network interfaces
 func ListNetworkInterfaces() ([]NetworkInterface, error) {
-	entries, err := os.ReadDir(netRoot)
+	entries, err := rootFS.ReadDir(netRoot)
 	if err != nil {
 		return nil, fmt.Errorf("reading %s: %w", netRoot, err)
 	}
 
+	// Addresses and routes are not in sysfs: ask rtnetlink on the live
+	// system, and leave them empty when replaying a capture.
+	var nl netlinkState
+	if rootFS.IsHost() {
+		nl, _ = readNetlinkState()
+	}
+
 	interfaces := make([]NetworkInterface, 0, 8)
 
 	for _, entry := range entries {
@@ -55,36 +94,102 @@ func ListNetworkInterfaces() ([]NetworkInterface, error) {
 		}
 
 		// MAC address (required)
-		if mac, err := sysfs.ReadFile(filepath.Join(basePath, "address")); err == nil {
+		if mac, err := rootFS.ReadFile(filepath.Join(basePath, "address")); err == nil {
 			iface.MACAddress = mac
 		}
 
 		// Operational state
-		if operstate, err := sysfs.ReadFileOptional(filepath.Join(basePath, "operstate")); err == nil {
+		if operstate, err := rootFS.ReadFileOptional(filepath.Join(basePath, "operstate")); err == nil {
 			iface.IsUp = (operstate == "up")
 		}
 
 		// Carrier (cable connected)
-		if carrier, err := sysfs.ReadBool(filepath.Join(basePath, "carrier")); err == nil {
+		if carrier, err := rootFS.ReadBool(filepath.Join(basePath, "carrier")); err == nil {
 			iface.Carrier = carrier
 		}
 
 		// Speed (optional, may not exist if disconnected)
-		if speed, err := sysfs.ReadFileOptional(filepath.Join(basePath, "speed")); err == nil &&
+		if speed, err := rootFS.ReadFileOptional(filepath.Join(basePath, "speed")); err == nil &&
 			speed != "" {
 			iface.Speed = speed + " Mbps"
 		}
 
 		// Determine type (WiFi vs Ethernet)
 		wirelessPath := filepath.Join(basePath, "wireless")
-		if _, err := os.Stat(wirelessPath); err == nil {
+		if _, err := rootFS.Stat(wirelessPath); err == nil {
 			iface.Type = "wifi"
 		} else {
 			iface.Type = "ethernet"
 		}
 
+		if mtu, err := rootFS.ReadInt(filepath.Join(basePath, "mtu")); err == nil {
+			iface.MTU = mtu
+		}
+		if index, err := rootFS.ReadInt(filepath.Join(basePath, "ifindex")); err == nil {
+			if link, ok := nl.Links[index]; ok && link.MTU > 0 {
+				iface.MTU = link.MTU
+			}
+			iface.Addresses = sortAddresses(nl.Addresses[index])
+			iface.Gateways = nl.Gateways[index]
+			for _, addr := range iface.Addresses {
+				if ip, _, err := net.ParseCIDR(addr); err == nil && ip.To4() != nil {
+					iface.IPAddress = ip.String()
+					break
+				}
+			}
+		}
+
+		if iface.Type == "wifi" && rootFS.IsHost() {
+			if caps, err := GetWifiCapabilities(name); err == nil {
+				iface.Wifi = &caps
+			}
+		}
+
+		readNICIdentity(basePath, &iface)
+		if rootFS.IsHost() {
+			if link, err := GetLinkSettings(name); err == nil {
+				iface.Link = &link
+			}
+			if wol, err := GetWakeOnLan(name); err == nil {
+				iface.WoL = &wol
+			}
+			if info, err := GetDriverInfo(name); err == nil {
+				if iface.Driver == "" {
+					iface.Driver = info.Driver
+				}
+				iface.DriverVersion = info.Version
+				iface.FirmwareVersion = info.FirmwareVersion
+			}
+		}
+
 		interfaces = append(interfaces, iface)
 	}
 
 	return interfaces, nil
 }
+
+// sortAddresses puts IPv4 addresses before IPv6 ones, keeping kernel order
+// otherwise.
+func sortAddresses(addrs []string) []string {
+	out := slices.Clone(addrs)
+	slices.SortStableFunc(out, func(a, b string) int {
+		return strings.Count(a, ":") - strings.Count(b, ":")
+	})
+	return out
+}
+
+// readNICIdentity resolves driver, bus and vendor/device of an interface
+// through its /sys/class/net/<if>/device link. Virtual interfaces have no
+// device and are left untouched.
+func readNICIdentity(basePath string, iface *NetworkInterface) {
+	id, ok := readDeviceIdentity(basePath)
+	if !ok {
+		return
+	}
+	iface.Driver = id.Driver
+	iface.Bus = id.Bus
+	iface.VendorID = id.VendorID
+	iface.DeviceID = id.DeviceID
+	iface.Vendor = id.Vendor
+	iface.Product = id.Product
+}
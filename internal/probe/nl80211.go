@@ -0,0 +1,338 @@
+package probe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/bits"
+	"net"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+// WifiCapabilities describes what a Wi-Fi adapter (wiphy) can do,
+// read with NL80211_CMD_GET_WIPHY.
+type WifiCapabilities struct {
+	Phy            string   // e.g. "phy0"
+	Bands          []string // "2.4 GHz", "5 GHz", "6 GHz", "60 GHz"
+	PHYModes       []string // "n", "ac", "ax", "be"
+	Generation     string   // "Wi-Fi 4" to "Wi-Fi 7", "Wi-Fi 6E" with 6 GHz
+	AntennasTX     int      // 0 if the driver does not report antennas
+	AntennasRX     int
+	SpatialStreams int // from the HT MCS set, 0 if unknown
+	Channels       int // enabled channels across all bands
+}
+
+// WifiBSS is an access point seen by a scan.
+type WifiBSS struct {
+	BSSID        string
+	SSID         string // empty for hidden networks
+	FrequencyMHz int
+	SignalDBm    float64
+	Associated   bool
+}
+
+// WifiScan is the outcome of ScanWifi.
+type WifiScan struct {
+	Triggered bool      // a fresh scan ran; false means cached results only
+	BSS       []WifiBSS // strongest first
+}
+
+// nl80211 band identifiers, in enum nl80211_band order.
+var nl80211Bands = map[uint16]string{
+	unix.NL80211_BAND_2GHZ:  "2.4 GHz",
+	unix.NL80211_BAND_5GHZ:  "5 GHz",
+	unix.NL80211_BAND_60GHZ: "60 GHz",
+	unix.NL80211_BAND_6GHZ:  "6 GHz",
+}
+
+// WifiScanTimeout bounds a triggered scan; a full dual-band scan takes
+// 3 to 10 seconds depending on the driver.
+const WifiScanTimeout = 15 * time.Second
+
+// GetWifiCapabilities reads bands, PHY modes and antennas of the radio
+// behind a Wi-Fi interface. It needs the live system (no replay support).
+func GetWifiCapabilities(name string) (WifiCapabilities, error) {
+	if !rootFS.IsHost() {
+		return WifiCapabilities{}, errors.New("nl80211 is not available in replay mode")
+	}
+	wiphy, err := rootFS.ReadInt(filepath.Join(netRoot, name, "phy80211", "index"))
+	if err != nil {
+		return WifiCapabilities{}, fmt.Errorf("%s is not a wireless interface: %w", name, err)
+	}
+
+	c, err := dialGenl()
+	if err != nil {
+		return WifiCapabilities{}, err
+	}
+	defer c.Close()
+	family, _, err := c.family("nl80211")
+	if err != nil {
+		return WifiCapabilities{}, err
+	}
+
+	// Split dumps are required for the band details of modern drivers;
+	// the wiphy attribute filters the dump to our radio.
+	attrs := appendAttr(nil, unix.NL80211_ATTR_SPLIT_WIPHY_DUMP, nil)
+	attrs = appendAttrUint32(attrs, unix.NL80211_ATTR_WIPHY, uint32(wiphy))
+	replies, err := c.execute(family, unix.NL80211_CMD_GET_WIPHY, unix.NLM_F_DUMP, attrs)
+	if err != nil {
+		return WifiCapabilities{}, fmt.Errorf("NL80211_CMD_GET_WIPHY %s: %w", name, err)
+	}
+	return parseWiphy(replies, uint32(wiphy)), nil
+}
+
+// parseWiphy merges the messages of a split wiphy dump.
+func parseWiphy(replies []genlMessage, wiphy uint32) WifiCapabilities {
+	var caps WifiCapabilities
+	freqs := map[uint16]map[uint32]bool{} // band → enabled frequencies
+	modes := map[string]bool{}
+
+	for _, r := range replies {
+		if id, ok := findAttr(r.Attrs, unix.NL80211_ATTR_WIPHY); ok && attrUint32(id) != wiphy {
+			continue
+		}
+		for _, a := range r.Attrs {
+			switch a.Type {
+			case unix.NL80211_ATTR_WIPHY_NAME:
+				caps.Phy = cString(a.Value)
+			case unix.NL80211_ATTR_WIPHY_ANTENNA_AVAIL_TX:
+				caps.AntennasTX = bits.OnesCount32(attrUint32(a))
+			case unix.NL80211_ATTR_WIPHY_ANTENNA_AVAIL_RX:
+				caps.AntennasRX = bits.OnesCount32(attrUint32(a))
+			case unix.NL80211_ATTR_WIPHY_BANDS:
+				for _, band := range parseAttrs(a.Value) {
+					if freqs[band.Type] == nil {
+						freqs[band.Type] = map[uint32]bool{}
+					}
+					caps.SpatialStreams = max(caps.SpatialStreams, parseBand(band.Value, freqs[band.Type], modes))
+				}
+			}
+		}
+	}
+
+	for _, id := range []uint16{unix.NL80211_BAND_2GHZ, unix.NL80211_BAND_5GHZ, unix.NL80211_BAND_6GHZ, unix.NL80211_BAND_60GHZ} {
+		if len(freqs[id]) > 0 {
+			caps.Bands = append(caps.Bands, nl80211Bands[id])
+			caps.Channels += len(freqs[id])
+		}
+	}
+	for _, mode := range []string{"n", "ac", "ax", "be"} {
+		if modes[mode] {
+			caps.PHYModes = append(caps.PHYModes, mode)
+		}
+	}
+	caps.Generation = wifiGeneration(caps)
+	return caps
+}
+
+// parseBand records enabled frequencies and PHY modes of one band and
+// returns the number of HT spatial streams it supports.
+func parseBand(b []byte, freqs map[uint32]bool, modes map[string]bool) int {
+	streams := 0
+	for _, a := range parseAttrs(b) {
+		switch a.Type {
+		case unix.NL80211_BAND_ATTR_FREQS:
+			for _, f := range parseAttrs(a.Value) {
+				var mhz uint32
+				disabled := false
+				for _, fa := range parseAttrs(f.Value) {
+					switch fa.Type {
+					case unix.NL80211_FREQUENCY_ATTR_FREQ:
+						mhz = attrUint32(fa)
+					case unix.NL80211_FREQUENCY_ATTR_DISABLED:
+						disabled = true
+					}
+				}
+				if mhz != 0 && !disabled {
+					freqs[mhz] = true
+				}
+			}
+		case unix.NL80211_BAND_ATTR_HT_CAPA:
+			modes["n"] = true
+		case unix.NL80211_BAND_ATTR_HT_MCS_SET:
+			// rx_mask: one byte per stream for MCS 0-31
+			for _, m := range a.Value[:min(4, len(a.Value))] {
+				if m != 0 {
+					streams++
+				}
+			}
+		case unix.NL80211_BAND_ATTR_VHT_CAPA:
+			modes["ac"] = true
+		case unix.NL80211_BAND_ATTR_IFTYPE_DATA:
+			for _, data := range parseAttrs(a.Value) {
+				for _, da := range parseAttrs(data.Value) {
+					switch da.Type {
+					case unix.NL80211_BAND_IFTYPE_ATTR_HE_CAP_PHY:
+						modes["ax"] = true
+					case unix.NL80211_BAND_IFTYPE_ATTR_EHT_CAP_PHY:
+						modes["be"] = true
+					}
+				}
+			}
+		}
+	}
+	return streams
+}
+
+func wifiGeneration(caps WifiCapabilities) string {
+	switch {
+	case slices.Contains(caps.PHYModes, "be"):
+		return "Wi-Fi 7"
+	case slices.Contains(caps.PHYModes, "ax") && slices.Contains(caps.Bands, "6 GHz"):
+		return "Wi-Fi 6E"
+	case slices.Contains(caps.PHYModes, "ax"):
+		return "Wi-Fi 6"
+	case slices.Contains(caps.PHYModes, "ac"):
+		return "Wi-Fi 5"
+	case slices.Contains(caps.PHYModes, "n"):
+		return "Wi-Fi 4"
+	}
+	return ""
+}
+
+// ScanWifi triggers a scan on a Wi-Fi interface and lists the access points
+// it sees, proving the radio receives. Triggering needs CAP_NET_ADMIN and
+// an interface that is up; without the privilege the results cached by the
+// kernel are returned with Triggered false.
+func ScanWifi(ctx context.Context, name string) (WifiScan, error) {
+	if !rootFS.IsHost() {
+		return WifiScan{}, errors.New("nl80211 is not available in replay mode")
+	}
+	ifi, err := net.InterfaceByName(name)
+	if err != nil {
+		return WifiScan{}, err
+	}
+	ifindex := uint32(ifi.Index)
+
+	c, err := dialGenl()
+	if err != nil {
+		return WifiScan{}, err
+	}
+	defer c.Close()
+	family, groups, err := c.family("nl80211")
+	if err != nil {
+		return WifiScan{}, err
+	}
+
+	var scan WifiScan
+	ifAttr := appendAttrUint32(nil, unix.NL80211_ATTR_IFINDEX, ifindex)
+
+	// Subscribe before triggering so the completion event cannot be missed.
+	events, err := dialGenl()
+	if err != nil {
+		return WifiScan{}, err
+	}
+	defer events.Close()
+	group, ok := groups["scan"]
+	if !ok {
+		return WifiScan{}, errors.New("nl80211: no scan multicast group")
+	}
+	if err := events.join(group); err != nil {
+		return WifiScan{}, err
+	}
+
+	_, err = c.execute(family, unix.NL80211_CMD_TRIGGER_SCAN, 0, ifAttr)
+	switch {
+	case err == nil, errors.Is(err, unix.EBUSY): // EBUSY: a scan is already running, wait for it
+		if err := waitScan(ctx, events, ifindex); err != nil {
+			return WifiScan{}, fmt.Errorf("scan %s: %w", name, err)
+		}
+		scan.Triggered = true
+	case errors.Is(err, unix.EPERM), errors.Is(err, unix.EACCES):
+		// Unprivileged: fall back to cached results
+	default:
+		return WifiScan{}, fmt.Errorf("NL80211_CMD_TRIGGER_SCAN %s: %w", name, err)
+	}
+
+	replies, err := c.execute(family, unix.NL80211_CMD_GET_SCAN, unix.NLM_F_DUMP, ifAttr)
+	if err != nil {
+		return WifiScan{}, fmt.Errorf("NL80211_CMD_GET_SCAN %s: %w", name, err)
+	}
+	for _, r := range replies {
+		if a, ok := findAttr(r.Attrs, unix.NL80211_ATTR_BSS); ok {
+			scan.BSS = append(scan.BSS, parseBSS(a.Value))
+		}
+	}
+	sort.SliceStable(scan.BSS, func(i, j int) bool { return scan.BSS[i].SignalDBm > scan.BSS[j].SignalDBm })
+	return scan, nil
+}
+
+// waitScan waits for NEW_SCAN_RESULTS (or SCAN_ABORTED) on ifindex.
+func waitScan(ctx context.Context, events *genlConn, ifindex uint32) error {
+	if err := events.setTimeout(500 * time.Millisecond); err != nil {
+		return err
+	}
+	deadline := time.Now().Add(WifiScanTimeout)
+	for time.Now().Before(deadline) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		msgs, err := events.next()
+		if err != nil {
+			if isTimeout(err) {
+				continue
+			}
+			return err
+		}
+		for _, m := range msgs {
+			if a, ok := findAttr(m.Attrs, unix.NL80211_ATTR_IFINDEX); !ok || attrUint32(a) != ifindex {
+				continue
+			}
+			switch m.Cmd {
+			case unix.NL80211_CMD_NEW_SCAN_RESULTS:
+				return nil
+			case unix.NL80211_CMD_SCAN_ABORTED:
+				return errors.New("scan aborted")
+			}
+		}
+	}
+	return errors.New("scan timed out")
+}
+
+func parseBSS(b []byte) WifiBSS {
+	var bss WifiBSS
+	for _, a := range parseAttrs(b) {
+		switch a.Type {
+		case unix.NL80211_BSS_BSSID:
+			bss.BSSID = net.HardwareAddr(a.Value).String()
+		case unix.NL80211_BSS_FREQUENCY:
+			bss.FrequencyMHz = int(attrUint32(a))
+		case unix.NL80211_BSS_SIGNAL_MBM:
+			bss.SignalDBm = float64(int32(attrUint32(a))) / 100
+		case unix.NL80211_BSS_STATUS:
+			bss.Associated = attrUint32(a) == unix.NL80211_BSS_STATUS_ASSOCIATED
+		case unix.NL80211_BSS_INFORMATION_ELEMENTS:
+			bss.SSID = ieSSID(a.Value)
+		}
+	}
+	return bss
+}
+
+// ieSSID extracts the SSID element (ID 0) from 802.11 information elements.
+func ieSSID(ies []byte) string {
+	for len(ies) >= 2 {
+		id, l := ies[0], int(ies[1])
+		if len(ies) < 2+l {
+			break
+		}
+		if id == 0 {
+			return string(ies[2 : 2+l])
+		}
+		ies = ies[2+l:]
+	}
+	return ""
+}
+
+func findAttr(attrs []nlAttr, typ uint16) (nlAttr, bool) {
+	for _, a := range attrs {
+		if a.Type == typ {
+			return a, true
+		}
+	}
+	return nlAttr{}, false
+}
@@ -0,0 +1,360 @@
+package probe
+
+import (
+	"bufio"
+	"bytes"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"gobox/internal/sysfs"
+)
+
+const (
+	fixtureT480     = "testdata/thinkpad-t480"
+	fixtureOptiPlex = "testdata/optiplex-7070" // tour : iGPU + carte NVIDIA, HDD SATA, pas de batterie
+)
+
+// useFixture redirige les sondes vers une arborescence capturée, lspci
+// compris, et restaure le système réel en fin de test.
+func useFixture(t *testing.T, root string) {
+	t.Helper()
+	SetFS(sysfs.NewFS(root))
+	SetCommandRunner(func(name string, args ...string) ([]byte, error) {
+		return fixtureLspci(root, args)
+	})
+	t.Cleanup(func() {
+		SetFS(sysfs.Host)
+		SetCommandRunner(nil)
+	})
+}
+
+// fixtureLspci rejoue gobox/cmd/lspci.txt en filtrant sur le slot demandé.
+func fixtureLspci(root string, args []string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(root, "gobox", "cmd", "lspci.txt"))
+	if err != nil {
+		return nil, err
+	}
+	i := slices.Index(args, "-s")
+	if i < 0 || i+1 >= len(args) {
+		return data, nil
+	}
+	var out bytes.Buffer
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if strings.HasPrefix(scanner.Text(), args[i+1]+" ") {
+			out.WriteString(scanner.Text() + "\n")
+		}
+	}
+	return out.Bytes(), nil
+}
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 1e-6
+}
+
+func TestGetCPUInfoFixture(t *testing.T) {
+	useFixture(t, fixtureT480)
+
+	info, err := GetCPUInfo()
+	if err != nil {
+		t.Fatalf("GetCPUInfo: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		got, want any
+	}{
+		{"VendorID", info.VendorID, "GenuineIntel"},
+		{"ModelName", info.ModelName, "Intel(R) Core(TM) i5-8350U CPU @ 1.70GHz"},
+		{"NumberCore", info.NumberCore, 2},
+		// L1d + L1i + L2 par cœur, L3 partagé compté une seule fois
+		{"CacheSize", info.CacheSize, int64((32+32+256)*2+6144) << 10},
+		{"FreqMaxMHz", info.FreqMaxMHz, 3600.0},
+		{"FreqMinMHz", info.FreqMinMHz, 400.0},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestGetMemoryInfoFixture(t *testing.T) {
+	useFixture(t, fixtureT480)
+
+	info, err := GetMemoryInfo()
+	if err != nil {
+		t.Fatalf("GetMemoryInfo: %v", err)
+	}
+	if info.TotalMB != 16384 {
+		t.Errorf("TotalMB = %d, want 16384", info.TotalMB)
+	}
+	if len(info.Slots) != 2 {
+		t.Fatalf("got %d slots, want 2", len(info.Slots))
+	}
+
+	tests := []struct {
+		slot   int
+		name   string
+		got    any
+		expect any
+	}{
+		{0, "Slot", info.Slots[0].Slot, "ChannelA-DIMM0"},
+		{1, "Slot", info.Slots[1].Slot, "ChannelB-DIMM0"},
+		{0, "BankLocator", info.Slots[0].BankLocator, "BANK 0"},
+		{0, "FormFactor", info.Slots[0].FormFactor, "SODIMM"},
+		{0, "Type", info.Slots[0].Type, "DDR4"},
+		{0, "TypeDetail", info.Slots[0].TypeDetail, "Synchronous"},
+		{0, "Manufacturer", info.Slots[0].Manufacturer, "Samsung"},
+		{1, "SerialNumber", info.Slots[1].SerialNumber, "4735802B"},
+		{0, "PartNumber", info.Slots[0].PartNumber, "M471A1K43CB1-CRC"},
+		{0, "SizeMB", info.Slots[0].SizeMB, 8192},
+		{0, "Speed", info.Slots[0].Speed, 2400},
+		{0, "ConfiguredSpeed", info.Slots[0].ConfiguredSpeed, 2400},
+		{0, "ConfiguredVoltage", info.Slots[0].ConfiguredVoltage, 1.2},
+		{0, "Rank", info.Slots[0].Rank, 1},
+		{0, "ErrorCorrection", info.Slots[0].ErrorCorrection, "None"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.expect {
+			t.Errorf("slot %d %s = %v, want %v", tt.slot, tt.name, tt.got, tt.expect)
+		}
+	}
+}
+
+func TestDiskFixture(t *testing.T) {
+	useFixture(t, fixtureT480)
+
+	disks, err := ListDisks()
+	if err != nil {
+		t.Fatalf("ListDisks: %v", err)
+	}
+	if !slices.Equal(disks, []string{"nvme0n1"}) {
+		t.Fatalf("ListDisks = %v, want [nvme0n1]", disks)
+	}
+
+	info, err := GetDiskInfo("nvme0n1")
+	if err != nil {
+		t.Fatalf("GetDiskInfo: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		got, want any
+	}{
+		{"SizeBytes", info.SizeBytes, int64(1000215216) * 512},
+		{"Type", info.Type, "SSD"},
+		{"Bus", info.Bus, BusNVMe},
+		{"Model", info.Model, "SAMSUNG MZVLB512HAJQ-000L7"},
+		{"Serial", info.Serial, "S3TNNX0K123456"},
+		{"Partitions", strings.Join(info.Partitions, ","), "nvme0n1p1,nvme0n1p2"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+
+	if _, err := GetDiskInfo("../nvme0n1"); err == nil {
+		t.Error("GetDiskInfo accepted a path traversal")
+	}
+}
+
+func TestBatteryFixture(t *testing.T) {
+	useFixture(t, fixtureT480)
+
+	status, err := GetPowerStatus()
+	if err != nil {
+		t.Fatalf("GetPowerStatus: %v", err)
+	}
+	if status.OnAC {
+		t.Error("OnAC = true, want false (AC/online = 0)")
+	}
+	if len(status.Supplies) != 1 || status.Supplies[0].Name != "AC" || status.Supplies[0].Type != "Mains" {
+		t.Errorf("Supplies = %+v, want one Mains supply named AC", status.Supplies)
+	}
+	if len(status.Batteries) != 1 {
+		t.Fatalf("got %d batteries, want 1", len(status.Batteries))
+	}
+
+	b := status.Batteries[0]
+	tests := []struct {
+		name string
+		ok   bool
+		got  any
+	}{
+		{"Name", b.Name == "BAT0", b.Name},
+		{"Status", b.Status == "Discharging", b.Status},
+		{"Capacity", b.Capacity == 87, b.Capacity},
+		{"Cycle", b.Cycle == 412, b.Cycle},
+		{"Unit", b.Unit == UnitEnergy, b.Unit},
+		{"FullWh", approx(b.FullWh, 19.24), b.FullWh},
+		{"DesignWh", approx(b.DesignWh, 24.05), b.DesignWh},
+		{"NowWh", approx(b.NowWh, 16.74), b.NowWh},
+		{"DesignAh", approx(b.DesignAh, 24.05/11.1), b.DesignAh},
+		{"Serial", b.Serial != nil && *b.Serial == "1234", b.Serial},
+		{"CapacityLevel", b.CapacityLevel != nil && *b.CapacityLevel == "Normal", b.CapacityLevel},
+		{"TemperatureC", b.TemperatureC == nil, b.TemperatureC},
+	}
+	for _, tt := range tests {
+		if !tt.ok {
+			t.Errorf("%s: unexpected value %v", tt.name, tt.got)
+		}
+	}
+
+	if _, err := ReadBattery("AC/../BAT0"); err == nil {
+		t.Error("ReadBattery accepted a path traversal")
+	}
+}
+
+func TestListNetworkInterfacesFixture(t *testing.T) {
+	useFixture(t, fixtureT480)
+
+	ifaces, err := ListNetworkInterfaces()
+	if err != nil {
+		t.Fatalf("ListNetworkInterfaces: %v", err)
+	}
+
+	tests := []NetworkInterface{
+		{
+			Name: "enp0s31f6", MACAddress: "8c:16:45:12:34:56", Type: "ethernet",
+			IsUp: true, Carrier: true, Speed: "1000 Mbps", MTU: 1500,
+			Driver: "e1000e", Bus: "pci", VendorID: "0x8086", DeviceID: "0x15d7",
+			Vendor: "Intel Corporation", Product: "Ethernet Connection (4) I219-V",
+		},
+		{
+			Name: "wlp59s0", MACAddress: "34:e1:2d:ab:cd:ef", Type: "wifi",
+			MTU:    1500,
+			Driver: "iwlwifi", Bus: "pci", VendorID: "0x8086", DeviceID: "0x24fd",
+			Vendor: "Intel Corporation", Product: "Wireless 8265 / 8275",
+		},
+	}
+	if len(ifaces) != len(tests) {
+		t.Fatalf("got %d interfaces, want %d (lo filtered)", len(ifaces), len(tests))
+	}
+	for i, want := range tests {
+		got := ifaces[i]
+		// Adresses, liens ethtool et nl80211 ne sont lus que sur le système réel
+		if got.Addresses != nil || got.Link != nil || got.WoL != nil || got.Wifi != nil {
+			t.Errorf("%s: live-only fields set in replay", got.Name)
+		}
+		got.Addresses, got.Gateways = nil, nil
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("interface %d:\n got %+v\nwant %+v", i, got, want)
+		}
+	}
+
+	if model := ifaces[0].Model(); model != "Intel Ethernet Connection (4) I219-V (e1000e)" {
+		t.Errorf("Model() = %q", model)
+	}
+}
+
+func TestDetectGPUsFixture(t *testing.T) {
+	useFixture(t, fixtureOptiPlex)
+
+	gpus, err := DetectGPUs()
+	if err != nil {
+		t.Fatalf("DetectGPUs: %v", err)
+	}
+
+	// renderD128 et les connecteurs ne sont pas des cartes
+	tests := []GPUInfo{
+		{
+			Model: "CoffeeLake-S GT2 [UHD Graphics 630]", Vendor: "Intel Corporation",
+			Driver: "i915", Version: "inconnu", VendorID: "0x8086",
+			Outputs: []string{"DP-1"},
+		},
+		{
+			Model: "GP108 [GeForce GT 1030]", Vendor: "NVIDIA Corporation",
+			Driver: "nvidia", Version: "535.183.01", VendorID: "0x10de",
+			Outputs: []string{"DVI-D-1", "HDMI-A-2"},
+		},
+	}
+	if len(gpus) != len(tests) {
+		t.Fatalf("got %d GPUs, want %d", len(gpus), len(tests))
+	}
+	for i, want := range tests {
+		if !reflect.DeepEqual(gpus[i], want) {
+			t.Errorf("GPU %d:\n got %+v\nwant %+v", i, gpus[i], want)
+		}
+	}
+}
+
+func TestGetUSBInfoFixture(t *testing.T) {
+	useFixture(t, fixtureOptiPlex)
+
+	info, err := GetUSBInfo()
+	if err != nil {
+		t.Fatalf("GetUSBInfo: %v", err)
+	}
+
+	wantControllers := []USBController{{Type: "USB 3.0 (xHCI)", PCIAddr: "0000:00:14.0"}}
+	if !reflect.DeepEqual(info.Controllers, wantControllers) {
+		t.Errorf("Controllers = %+v, want %+v", info.Controllers, wantControllers)
+	}
+	if len(info.USBCPorts) != 0 {
+		t.Errorf("USBCPorts = %+v, want none (no /sys/class/typec)", info.USBCPorts)
+	}
+
+	// Hubs racines, interfaces (1-3:1.0) et lien mort (1-9) sont écartés
+	tests := []USBDevice{
+		{Name: "1-3", Speed: "1.5", SpeedClass: "USB 2.0", Product: "Dell KB216 Wired Keyboard", Vendor: "Dell", BusNum: "1", DevNum: "2"},
+		{Name: "1-4", Speed: "12", SpeedClass: "USB 2.0", Product: "USB Optical Mouse", BusNum: "1", DevNum: "3"},
+		{Name: "2-2", Speed: "5000", SpeedClass: "USB 3.0", Product: "Ultra", Vendor: "SanDisk", BusNum: "2", DevNum: "2"},
+	}
+	if len(info.Devices) != len(tests) {
+		t.Fatalf("got %d devices %+v, want %d", len(info.Devices), info.Devices, len(tests))
+	}
+	for i, want := range tests {
+		if info.Devices[i] != want {
+			t.Errorf("device %d:\n got %+v\nwant %+v", i, info.Devices[i], want)
+		}
+	}
+}
+
+func TestDesktopFixture(t *testing.T) {
+	useFixture(t, fixtureOptiPlex)
+
+	disks, err := ListDisks()
+	if err != nil {
+		t.Fatalf("ListDisks: %v", err)
+	}
+	if !slices.Equal(disks, []string{"sda"}) {
+		t.Fatalf("ListDisks = %v, want [sda]", disks)
+	}
+	info, err := GetDiskInfo("sda")
+	if err != nil {
+		t.Fatalf("GetDiskInfo: %v", err)
+	}
+
+	status, err := GetPowerStatus()
+	if err != nil {
+		t.Fatalf("GetPowerStatus: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		got, want any
+	}{
+		{"SizeBytes", info.SizeBytes, int64(1953525168) * 512},
+		{"Type", info.Type, "HDD"},
+		{"Bus", info.Bus, BusSATA}, // lien /sys/block/sda via ata1
+		{"Vendor", info.Vendor, "ATA"},
+		{"Model", info.Model, "ST1000DM010-2EP102"},
+		{"Serial", info.Serial, "Z9AB1CDE"}, // page VPD 0x80
+		{"Partitions", strings.Join(info.Partitions, ","), "sda1,sda2"},
+		// La batterie de la souris sans fil (scope Device) n'est pas une batterie système
+		{"Batteries", len(status.Batteries), 0},
+		{"Supplies", len(status.Supplies), 0},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
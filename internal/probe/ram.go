@@ -1,9 +1,10 @@
 package probe
 
 import (
-	"os/exec"
-	"strconv"
+	"fmt"
 	"strings"
+
+	"gobox/internal/smbios"
 )
 
 type MemoryInfo struct {
@@ -28,42 +29,85 @@ type MemorySlot struct {
 	Rank              int     // ex: 1
 	Technology        string  // ex: DRAM
 	OperatingMode     string  // ex: Volatile memory
+	ErrorCorrection   string  // ex: None, Single-bit ECC (depuis le Type 16 parent)
 }
 
-func cutPrefixString(line, prefix string, field *string) {
-	if after, ok := strings.CutPrefix(line, prefix); ok {
-		*field = strings.TrimSpace(after)
-	}
+// Offsets du Type 17 (Memory Device), SMBIOS 3.x.
+const (
+	t17ArrayHandle      = 0x04
+	t17Size             = 0x0C
+	t17FormFactor       = 0x0E
+	t17Locator          = 0x10
+	t17BankLocator      = 0x11
+	t17MemoryType       = 0x12
+	t17TypeDetail       = 0x13
+	t17Speed            = 0x15
+	t17Manufacturer     = 0x17
+	t17Serial           = 0x18
+	t17AssetTag         = 0x19
+	t17PartNumber       = 0x1A
+	t17Attributes       = 0x1B
+	t17ExtendedSize     = 0x1C
+	t17ConfiguredSpeed  = 0x20
+	t17ConfiguredVolt   = 0x26
+	t17Technology       = 0x28
+	t17OperatingMode    = 0x29
+	t17ExtSpeed         = 0x54
+	t17ExtConfiguredSpd = 0x58
+
+	t16ErrorCorrection = 0x06
+)
+
+var memoryFormFactors = []string{
+	0x01: "Other", 0x02: "Unknown", 0x03: "SIMM", 0x04: "SIP", 0x05: "Chip",
+	0x06: "DIP", 0x07: "ZIP", 0x08: "Proprietary Card", 0x09: "DIMM",
+	0x0A: "TSOP", 0x0B: "Row Of Chips", 0x0C: "RIMM", 0x0D: "SODIMM",
+	0x0E: "SRIMM", 0x0F: "FB-DIMM", 0x10: "Die", 0x11: "CAMM",
 }
 
-func parseIntField(line, prefix string, field *int, mult int, suffixes ...string) bool {
-	if after, ok := strings.CutPrefix(line, prefix); ok {
-		val := strings.TrimSpace(after)
-		for _, s := range suffixes {
-			val = strings.TrimSuffix(val, s)
-		}
-		val = strings.TrimSpace(val)
+var memoryTypes = []string{
+	0x01: "Other", 0x02: "Unknown", 0x03: "DRAM", 0x04: "EDRAM", 0x05: "VRAM",
+	0x06: "SRAM", 0x07: "RAM", 0x08: "ROM", 0x09: "Flash", 0x0A: "EEPROM",
+	0x0B: "FEPROM", 0x0C: "EPROM", 0x0D: "CDRAM", 0x0E: "3DRAM", 0x0F: "SDRAM",
+	0x10: "SGRAM", 0x11: "RDRAM", 0x12: "DDR", 0x13: "DDR2", 0x14: "DDR2 FB-DIMM",
+	0x18: "DDR3", 0x19: "FBD2", 0x1A: "DDR4", 0x1B: "LPDDR", 0x1C: "LPDDR2",
+	0x1D: "LPDDR3", 0x1E: "LPDDR4", 0x1F: "Logical non-volatile device",
+	0x20: "HBM", 0x21: "HBM2", 0x22: "DDR5", 0x23: "LPDDR5", 0x24: "HBM3",
+}
 
-		if val == "" || val == "Unknown" || val == "0" || strings.Contains(val, "No Module") {
-			return false
-		}
+// memoryTypeDetails est indexé par numéro de bit.
+var memoryTypeDetails = []string{
+	1: "Other", 2: "Unknown", 3: "Fast-paged", 4: "Static Column",
+	5: "Pseudo-static", 6: "RAMBus", 7: "Synchronous", 8: "CMOS", 9: "EDO",
+	10: "Window DRAM", 11: "Cache DRAM", 12: "Non-Volatile",
+	13: "Registered (Buffered)", 14: "Unbuffered (Unregistered)", 15: "LRDIMM",
+}
 
-		num, err := strconv.Atoi(val)
-		if err == nil {
-			*field = num * mult
-			return true
-		}
-	}
-	return false
+var memoryTechnologies = []string{
+	0x01: "Other", 0x02: "Unknown", 0x03: "DRAM", 0x04: "NVDIMM-N",
+	0x05: "NVDIMM-F", 0x06: "NVDIMM-P", 0x07: "Intel Optane persistent memory",
+}
+
+// memoryOperatingModes est indexé par numéro de bit.
+var memoryOperatingModes = []string{
+	1: "Other", 2: "Unknown", 3: "Volatile memory",
+	4: "Byte-accessible persistent memory", 5: "Block-accessible persistent memory",
+}
+
+var memoryErrorCorrections = []string{
+	0x01: "Other", 0x02: "Unknown", 0x03: "None", 0x04: "Parity",
+	0x05: "Single-bit ECC", 0x06: "Multi-bit ECC", 0x07: "CRC",
 }
 
+// GetMemoryInfo décode les barrettes installées depuis les tables SMBIOS
+// (Type 17, ECC depuis le Type 16), sans dépendre de dmidecode.
 func GetMemoryInfo() (MemoryInfo, error) {
-	cmd := exec.Command("dmidecode", "-t", "memory")
-	out, err := cmd.Output()
+	_, structures, err := readSMBIOS()
 	if err != nil {
-		return MemoryInfo{}, err
+		return MemoryInfo{}, fmt.Errorf("lecture SMBIOS: %w", err)
 	}
-	slots := parseDMIDecodeOutput(out)
+
+	slots := parseMemoryDevices(structures)
 
 	total := 0
 	for _, s := range slots {
@@ -76,42 +120,97 @@ func GetMemoryInfo() (MemoryInfo, error) {
 	}, nil
 }
 
-func parseDMIDecodeOutput(data []byte) []MemorySlot {
-	seen := map[string]bool{}
+// parseMemoryDevices convertit les structures Type 17 peuplées en MemorySlot.
+// Les emplacements vides sont ignorés, ainsi qu'une structure répétée (même
+// handle). Le Locator n'est pas une clé : certaines cartes le laissent vide.
+func parseMemoryDevices(structures []smbios.Structure) []MemorySlot {
+	ecc := map[uint16]string{}
+	for _, s := range smbios.Filter(structures, smbios.TypePhysicalMemoryArray) {
+		ecc[s.Handle] = enumName(memoryErrorCorrections, s.U8(t16ErrorCorrection))
+	}
+
+	seen := map[uint16]bool{}
 	var slots []MemorySlot
-	var slot MemorySlot
 
-	for line := range strings.SplitSeq(string(data), "\n") {
+	for _, s := range smbios.Filter(structures, smbios.TypeMemoryDevice) {
+		sizeMB := memoryDeviceSizeMB(s)
+		if sizeMB <= 0 {
+			continue
+		}
 
-		line = strings.TrimSpace(line)
+		slot := MemorySlot{
+			Slot:            s.String(t17Locator),
+			BankLocator:     s.String(t17BankLocator),
+			FormFactor:      enumName(memoryFormFactors, s.U8(t17FormFactor)),
+			Type:            enumName(memoryTypes, s.U8(t17MemoryType)),
+			TypeDetail:      bitNames(memoryTypeDetails, s.U16(t17TypeDetail)),
+			Manufacturer:    s.String(t17Manufacturer),
+			SerialNumber:    s.String(t17Serial),
+			PartNumber:      s.String(t17PartNumber),
+			AssetTag:        s.String(t17AssetTag),
+			SizeMB:          sizeMB,
+			Speed:           memorySpeed(s, t17Speed, t17ExtSpeed),
+			ConfiguredSpeed: memorySpeed(s, t17ConfiguredSpeed, t17ExtConfiguredSpd),
+			Rank:            int(s.U8(t17Attributes) & 0x0F),
+			Technology:      enumName(memoryTechnologies, s.U8(t17Technology)),
+			OperatingMode:   bitNames(memoryOperatingModes, s.U16(t17OperatingMode)),
+			ErrorCorrection: ecc[s.U16(t17ArrayHandle)],
+		}
 
-		if line == "Memory Device" {
-			slot = MemorySlot{}
-			continue
+		if mv := s.U16(t17ConfiguredVolt); mv > 0 {
+			slot.ConfiguredVoltage = float64(mv) / 1000
 		}
 
-		cutPrefixString(line, "Locator:", &slot.Slot)
-		cutPrefixString(line, "Type:", &slot.Type)
-		cutPrefixString(line, "Manufacturer:", &slot.Manufacturer)
-		cutPrefixString(line, "Serial Number:", &slot.SerialNumber)
-		cutPrefixString(line, "Bank Locator:", &slot.BankLocator)
-		cutPrefixString(line, "Form Factor:", &slot.FormFactor)
-		cutPrefixString(line, "Type Detail:", &slot.TypeDetail)
-		cutPrefixString(line, "Serial Number:", &slot.SerialNumber)
-		cutPrefixString(line, "Part Number:", &slot.PartNumber)
-		cutPrefixString(line, "Asset Tag:", &slot.AssetTag)
-		cutPrefixString(line, "Memory Technology:", &slot.Technology)
-		cutPrefixString(line, "Memory Operating Mode Capability:", &slot.OperatingMode)
-
-		parseIntField(line, "Size:", &slot.SizeMB, 1024, "GB", "MB")
-		parseIntField(line, "Speed:", &slot.Speed, 1, "MT/s", "MHz")
-		parseIntField(line, "Configured Memory Speed:", &slot.ConfiguredSpeed, 1, "MT/s", "MHz")
-
-		if slot.SizeMB > 0 && line == "" && !seen[slot.Slot] {
-			slots = append(slots, slot)
-			seen[slot.Slot] = true
+		if seen[s.Handle] {
+			continue
 		}
+		seen[s.Handle] = true
+		slots = append(slots, slot)
 	}
 
 	return slots
 }
+
+// memoryDeviceSizeMB décode le champ Size (et Extended Size si 0x7FFF).
+// Retourne 0 pour un emplacement vide ou une taille inconnue.
+func memoryDeviceSizeMB(s smbios.Structure) int {
+	size := s.U16(t17Size)
+	switch {
+	case size == 0 || size == 0xFFFF:
+		return 0
+	case size == 0x7FFF:
+		return int(s.U32(t17ExtendedSize) & 0x7FFFFFFF)
+	case size&0x8000 != 0:
+		return int(size&0x7FFF) / 1024 // granularité KB
+	default:
+		return int(size)
+	}
+}
+
+// memorySpeed lit une vitesse en MT/s, avec repli sur le champ étendu (3.3+).
+func memorySpeed(s smbios.Structure, off, extOff int) int {
+	speed := s.U16(off)
+	if speed == 0xFFFF {
+		return int(s.U32(extOff) & 0x7FFFFFFF)
+	}
+	return int(speed)
+}
+
+// enumName traduit une valeur d'énumération SMBIOS, "" si inconnue.
+func enumName(names []string, v uint8) string {
+	if int(v) >= len(names) {
+		return ""
+	}
+	return names[v]
+}
+
+// bitNames traduit un champ de bits SMBIOS en libellés séparés par des espaces.
+func bitNames(names []string, v uint16) string {
+	var parts []string
+	for bit := 1; bit < len(names); bit++ {
+		if v&(1<<bit) != 0 && names[bit] != "" {
+			parts = append(parts, names[bit])
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,81 @@
+package probe
+
+import (
+	"os"
+	"testing"
+
+	"gobox/internal/smbios"
+)
+
+// parseTableFixture décode une table SMBIOS brute (contenu de
+// /sys/firmware/dmi/tables/DMI) de testdata.
+func parseTableFixture(t *testing.T, name string) []smbios.Structure {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	structures, err := smbios.ParseTable(data)
+	if err != nil {
+		t.Fatalf("ParseTable(%s): %v", name, err)
+	}
+	return structures
+}
+
+func TestParseMemoryDevices(t *testing.T) {
+	type want struct {
+		slot, serial string
+		sizeMB       int
+	}
+	tests := []struct {
+		fixture string
+		want    []want
+	}{
+		// Carte bas de gamme : Locator et Bank Locator vides sur les deux barrettes
+		{"smbios-empty-locator.bin", []want{
+			{"", "0000A001", 4096},
+			{"", "0000A002", 4096},
+		}},
+		// Emplacement vide, Extended Size (0x7FFF) et granularité KB (bit 15)
+		{"smbios-mixed-sizes.bin", []want{
+			{"DIMM B", "0000B002", 65536},
+			{"DIMM C", "0000B003", 16},
+		}},
+		{"thinkpad-t480/sys/firmware/dmi/tables/DMI", []want{
+			{"ChannelA-DIMM0", "4735802A", 8192},
+			{"ChannelB-DIMM0", "4735802B", 8192},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			slots := parseMemoryDevices(parseTableFixture(t, tt.fixture))
+			if len(slots) != len(tt.want) {
+				t.Fatalf("got %d slots, want %d: %+v", len(slots), len(tt.want), slots)
+			}
+			for i, w := range tt.want {
+				got := want{slots[i].Slot, slots[i].SerialNumber, slots[i].SizeMB}
+				if got != w {
+					t.Errorf("slot %d = %+v, want %+v", i, got, w)
+				}
+				if slots[i].Type != "DDR4" || slots[i].ErrorCorrection != "None" {
+					t.Errorf("slot %d type %q ECC %q, want DDR4 / None", i, slots[i].Type, slots[i].ErrorCorrection)
+				}
+			}
+		})
+	}
+}
+
+func TestParseMemoryDevicesRepeatedStructure(t *testing.T) {
+	structures := parseTableFixture(t, "smbios-empty-locator.bin")
+	// Une même structure listée deux fois ne compte qu'une barrette
+	var repeated []smbios.Structure
+	for _, s := range structures {
+		repeated = append(repeated, s)
+		if s.Type == smbios.TypeMemoryDevice {
+			repeated = append(repeated, s)
+		}
+	}
+	if slots := parseMemoryDevices(repeated); len(slots) != 2 {
+		t.Errorf("got %d slots, want 2", len(slots))
+	}
+}
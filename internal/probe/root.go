@@ -0,0 +1,44 @@
+package probe
+
+import (
+	"os/exec"
+
+	"gobox/internal/sysfs"
+)
+
+// CommandRunner exécute une commande externe et retourne sa sortie standard.
+type CommandRunner func(name string, args ...string) ([]byte, error)
+
+// rootFS est la racine sous laquelle toutes les sondes lisent sysfs/procfs.
+// Par défaut le système réel ; remplaçable par une arborescence capturée.
+var rootFS = sysfs.Host
+
+// runCommand exécute les outils externes (lspci) ; remplaçable
+// pour rejouer leurs sorties capturées.
+var runCommand CommandRunner = execCommand
+
+func execCommand(name string, args ...string) ([]byte, error) {
+	return exec.Command(name, args...).Output()
+}
+
+// SetFS redirige toutes les sondes vers la racine fournie.
+//
+// Utilisé pour rejouer une machine capturée ou exécuter les sondes
+// contre une arborescence de test.
+func SetFS(fsys sysfs.FS) {
+	rootFS = fsys
+}
+
+// FS retourne la racine actuellement utilisée par les sondes.
+func FS() sysfs.FS {
+	return rootFS
+}
+
+// SetCommandRunner remplace l'exécution des commandes externes.
+// Un runner nil restaure l'exécution réelle.
+func SetCommandRunner(run CommandRunner) {
+	if run == nil {
+		run = execCommand
+	}
+	runCommand = run
+}
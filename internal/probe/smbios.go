@@ -0,0 +1,38 @@
+package probe
+
+import (
+	"fmt"
+
+	"gobox/internal/smbios"
+)
+
+const (
+	pathSMBIOSEntryPoint = "/sys/firmware/dmi/tables/smbios_entry_point"
+	pathSMBIOSTable      = "/sys/firmware/dmi/tables/DMI"
+)
+
+// readSMBIOS lit et décode les tables SMBIOS exposées par le noyau.
+// Nécessite les droits root (tables en 0400).
+func readSMBIOS() (smbios.EntryPoint, []smbios.Structure, error) {
+	epData, err := rootFS.ReadBytes(pathSMBIOSEntryPoint)
+	if err != nil {
+		return smbios.EntryPoint{}, nil, fmt.Errorf("lecture %s: %w", pathSMBIOSEntryPoint, err)
+	}
+
+	ep, err := smbios.ParseEntryPoint(epData)
+	if err != nil {
+		return smbios.EntryPoint{}, nil, err
+	}
+
+	table, err := rootFS.ReadBytes(pathSMBIOSTable)
+	if err != nil {
+		return smbios.EntryPoint{}, nil, fmt.Errorf("lecture %s: %w", pathSMBIOSTable, err)
+	}
+
+	structures, err := smbios.ParseTable(table)
+	if err != nil && len(structures) == 0 {
+		return smbios.EntryPoint{}, nil, err
+	}
+
+	return ep, structures, nil
+}
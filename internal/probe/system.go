@@ -0,0 +1,227 @@
+package probe
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gobox/internal/smbios"
+)
+
+const dmiIDRoot = "/sys/class/dmi/id"
+
+// Catégories de châssis retenues pour la fiche d'intake.
+const (
+	ChassisLaptop   = "laptop"
+	ChassisDesktop  = "desktop"
+	ChassisTablet   = "tablet"
+	ChassisAllInOne = "all-in-one"
+	ChassisServer   = "server"
+	ChassisOther    = "other"
+)
+
+// SystemInfo identifie la machine elle-même (et non ses composants).
+type SystemInfo struct {
+	Manufacturer   string // ex: "LENOVO"
+	Product        string // ex: "20L5CTO1WW"
+	Version        string // ex: "ThinkPad T480"
+	Family         string // ex: "ThinkPad T480"
+	SKU            string // ex: "LENOVO_MT_20L5_BU_Think_FM_ThinkPad T480"
+	Serial         string // ex: "PF1ABCDE"
+	UUID           string // ex: "4c4c4544-0042-3510-8052-b4c04f4b4e32"
+	BoardVendor    string // ex: "LENOVO"
+	BoardName      string // ex: "20L5CTO1WW"
+	BoardVersion   string // ex: "SDK0J40697 WIN"
+	BoardSerial    string // ex: "L1HF81N00AB"
+	ChassisCode    int    // code SMBIOS brut (ex: 10)
+	ChassisName    string // libellé SMBIOS (ex: "Notebook")
+	ChassisType    string // catégorie : laptop, desktop, tablet, all-in-one...
+	ChassisVendor  string // ex: "LENOVO"
+	ChassisSerial  string // ex: "PF1ABCDE"
+	BIOSVendor     string // ex: "LENOVO"
+	BIOSVersion    string // ex: "N24ET75W (1.50 )"
+	BIOSDate       string // ex: "03/28/2023"
+	FromSMBIOSOnly bool   // true si /sys/class/dmi/id était absent
+}
+
+// chassisNames est indexé par code de type de châssis SMBIOS (7.4.1).
+var chassisNames = []string{
+	1: "Other", 2: "Unknown", 3: "Desktop", 4: "Low Profile Desktop",
+	5: "Pizza Box", 6: "Mini Tower", 7: "Tower", 8: "Portable", 9: "Laptop",
+	10: "Notebook", 11: "Hand Held", 12: "Docking Station", 13: "All In One",
+	14: "Sub Notebook", 15: "Space-saving", 16: "Lunch Box",
+	17: "Main Server Chassis", 18: "Expansion Chassis", 19: "Sub Chassis",
+	20: "Bus Expansion Chassis", 21: "Peripheral Chassis", 22: "RAID Chassis",
+	23: "Rack Mount Chassis", 24: "Sealed-case PC", 25: "Multi-system",
+	26: "CompactPCI", 27: "AdvancedTCA", 28: "Blade", 29: "Blade Enclosing",
+	30: "Tablet", 31: "Convertible", 32: "Detachable", 33: "IoT Gateway",
+	34: "Embedded PC", 35: "Mini PC", 36: "Stick PC",
+}
+
+// ChassisCategory regroupe un code de châssis SMBIOS en catégorie d'intake.
+func ChassisCategory(code int) string {
+	switch code {
+	case 8, 9, 10, 14, 31, 32:
+		return ChassisLaptop
+	case 3, 4, 5, 6, 7, 15, 16, 24, 34, 35, 36:
+		return ChassisDesktop
+	case 11, 30:
+		return ChassisTablet
+	case 13:
+		return ChassisAllInOne
+	case 17, 23, 25, 28, 29:
+		return ChassisServer
+	default:
+		return ChassisOther
+	}
+}
+
+// dmiPlaceholders sont les valeurs par défaut laissées par les intégrateurs.
+var dmiPlaceholders = map[string]bool{
+	"to be filled by o.e.m.": true,
+	"default string":         true,
+	"system serial number":   true,
+	"system product name":    true,
+	"system manufacturer":    true,
+	"system version":         true,
+	"not specified":          true,
+	"not applicable":         true,
+	"none":                   true,
+	"0123456789":             true,
+	"o.e.m.":                 true,
+}
+
+// cleanDMIString vide les valeurs de remplissage sans signification.
+func cleanDMIString(s string) string {
+	s = strings.TrimSpace(s)
+	if dmiPlaceholders[strings.ToLower(s)] {
+		return ""
+	}
+	return s
+}
+
+// GetSystemInfo identifie la machine depuis /sys/class/dmi/id, complété
+// par les tables SMBIOS (Types 0 à 3) pour les champs absents ou réservés
+// à root (numéros de série, UUID).
+func GetSystemInfo() (SystemInfo, error) {
+	var info SystemInfo
+
+	_, dmiErr := rootFS.Stat(dmiIDRoot)
+	if dmiErr == nil {
+		readDMIID(&info)
+	}
+
+	ep, structures, smbiosErr := readSMBIOS()
+	if smbiosErr == nil {
+		fillFromSMBIOS(&info, ep, structures)
+	}
+
+	if dmiErr != nil && smbiosErr != nil {
+		return SystemInfo{}, fmt.Errorf("ni %s ni SMBIOS disponibles: %w", dmiIDRoot, smbiosErr)
+	}
+	info.FromSMBIOSOnly = dmiErr != nil
+
+	if info.ChassisCode > 0 {
+		info.ChassisName = enumName(chassisNames, uint8(info.ChassisCode))
+	}
+	info.ChassisType = ChassisCategory(info.ChassisCode)
+
+	return info, nil
+}
+
+func readDMIID(info *SystemInfo) {
+	read := func(name string) string {
+		s, _ := rootFS.ReadFileOptional(filepath.Join(dmiIDRoot, name))
+		return cleanDMIString(s)
+	}
+
+	info.Manufacturer = read("sys_vendor")
+	info.Product = read("product_name")
+	info.Version = read("product_version")
+	info.Family = read("product_family")
+	info.SKU = read("product_sku")
+	info.Serial = read("product_serial")
+	info.UUID = strings.ToLower(read("product_uuid"))
+	info.BoardVendor = read("board_vendor")
+	info.BoardName = read("board_name")
+	info.BoardVersion = read("board_version")
+	info.BoardSerial = read("board_serial")
+	info.ChassisVendor = read("chassis_vendor")
+	info.ChassisSerial = read("chassis_serial")
+	info.BIOSVendor = read("bios_vendor")
+	info.BIOSVersion = read("bios_version")
+	info.BIOSDate = read("bios_date")
+
+	if code, err := strconv.Atoi(read("chassis_type")); err == nil {
+		info.ChassisCode = code
+	}
+}
+
+// fillFromSMBIOS ne complète que les champs restés vides.
+func fillFromSMBIOS(info *SystemInfo, ep smbios.EntryPoint, structures []smbios.Structure) {
+	fill := func(field *string, value string) {
+		if *field == "" {
+			*field = cleanDMIString(value)
+		}
+	}
+
+	if bios := smbios.Filter(structures, smbios.TypeBIOS); len(bios) > 0 {
+		s := bios[0]
+		fill(&info.BIOSVendor, s.String(0x04))
+		fill(&info.BIOSVersion, s.String(0x05))
+		fill(&info.BIOSDate, s.String(0x08))
+	}
+
+	if system := smbios.Filter(structures, smbios.TypeSystem); len(system) > 0 {
+		s := system[0]
+		fill(&info.Manufacturer, s.String(0x04))
+		fill(&info.Product, s.String(0x05))
+		fill(&info.Version, s.String(0x06))
+		fill(&info.Serial, s.String(0x07))
+		if s.Has(0x08, 16) {
+			fill(&info.UUID, formatSMBIOSUUID(s.Formatted[0x08:0x18], ep.AtLeast(2, 6)))
+		}
+		fill(&info.SKU, s.String(0x19))
+		fill(&info.Family, s.String(0x1A))
+	}
+
+	if board := smbios.Filter(structures, smbios.TypeBaseboard); len(board) > 0 {
+		s := board[0]
+		fill(&info.BoardVendor, s.String(0x04))
+		fill(&info.BoardName, s.String(0x05))
+		fill(&info.BoardVersion, s.String(0x06))
+		fill(&info.BoardSerial, s.String(0x07))
+	}
+
+	if chassis := smbios.Filter(structures, smbios.TypeChassis); len(chassis) > 0 {
+		s := chassis[0]
+		fill(&info.ChassisVendor, s.String(0x04))
+		fill(&info.ChassisSerial, s.String(0x07))
+		if info.ChassisCode == 0 {
+			info.ChassisCode = int(s.U8(0x05) & 0x7F) // bit 7 = verrou
+		}
+	}
+}
+
+// formatSMBIOSUUID formate un UUID SMBIOS. Depuis la 2.6 (littleEndian),
+// les trois premiers champs sont stockés en little-endian ; avant, tout
+// l'UUID est en ordre réseau. Le noyau applique la même règle pour
+// product_uuid.
+// Retourne "" pour les valeurs « absent » (tout à 0xFF) et « non défini » (tout à 0).
+func formatSMBIOSUUID(b []byte, littleEndian bool) string {
+	allFF, all00 := true, true
+	for _, c := range b {
+		allFF = allFF && c == 0xFF
+		all00 = all00 && c == 0x00
+	}
+	if allFF || all00 {
+		return ""
+	}
+
+	if littleEndian {
+		b = []byte{b[3], b[2], b[1], b[0], b[5], b[4], b[7], b[6],
+			b[8], b[9], b[10], b[11], b[12], b[13], b[14], b[15]}
+	}
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
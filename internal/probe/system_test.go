@@ -0,0 +1,26 @@
+package probe
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFormatSMBIOSUUID(t *testing.T) {
+	raw := []byte{0x44, 0x45, 0x4c, 0x4c, 0x42, 0x00, 0x10, 0x35, 0x80, 0x52, 0xb4, 0xc0, 0x4f, 0x4b, 0x4e, 0x32}
+	tests := []struct {
+		name         string
+		b            []byte
+		littleEndian bool
+		want         string
+	}{
+		{"SMBIOS 2.6+", raw, true, "4c4c4544-0042-3510-8052-b4c04f4b4e32"},
+		{"before 2.6", raw, false, "44454c4c-4200-1035-8052-b4c04f4b4e32"},
+		{"not present", bytes.Repeat([]byte{0xFF}, 16), true, ""},
+		{"not set", make([]byte, 16), false, ""},
+	}
+	for _, tt := range tests {
+		if got := formatSMBIOSUUID(tt.b, tt.littleEndian); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
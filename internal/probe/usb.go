@@ -85,9 +85,9 @@ func PrintUSBInfo() {
 		return
 	}
 
-	fmt.Printf("\n" + strings.Repeat("=", 70) + "\n")
+	fmt.Print("\n" + strings.Repeat("=", 70) + "\n")
 	fmt.Printf("  CONNECTIVITÉ USB\n")
-	fmt.Printf(strings.Repeat("=", 70) + "\n")
+	fmt.Print(strings.Repeat("=", 70) + "\n")
 
 	// Section 1 : Contrôleurs
 	fmt.Println("\n  Contrôleurs USB détectés")
@@ -185,9 +185,9 @@ func PrintSerialPorts() {
 		return
 	}
 
-	fmt.Printf("\n" + strings.Repeat("=", 70) + "\n")
+	fmt.Print("\n" + strings.Repeat("=", 70) + "\n")
 	fmt.Printf("  Ports série détectés : %d\n", len(ports))
-	fmt.Printf(strings.Repeat("=", 70) + "\n\n")
+	fmt.Print(strings.Repeat("=", 70) + "\n\n")
 
 	for i, port := range ports {
 		fmt.Printf("Port série #%d\n", i+1)
@@ -231,7 +231,7 @@ func validateSysfsName(name string) error {
 
 // readSysfsFile lit un fichier sysfs avec limite de taille (sécurité)
 func readSysfsFile(path string, buf []byte) (string, error) {
-	file, err := os.Open(path)
+	file, err := rootFS.Open(path)
 	if err != nil {
 		return "", err
 	}
@@ -248,7 +248,7 @@ func readSysfsFile(path string, buf []byte) (string, error) {
 
 // ListUSBControllers détecte les contrôleurs USB via PCI
 func ListUSBControllers() ([]USBController, error) {
-	entries, err := os.ReadDir(pciRoot)
+	entries, err := rootFS.ReadDir(pciRoot)
 	if err != nil {
 		return nil, fmt.Errorf("lecture %s: %w", pciRoot, err)
 	}
@@ -313,7 +313,7 @@ func ListUSBControllers() ([]USBController, error) {
 // ListUSBDevices liste tous les devices USB connectés
 func ListUSBDevices() ([]USBDevice, error) {
 	buf := make([]byte, maxSysfsFileSize)
-	entries, err := os.ReadDir(usbRoot)
+	entries, err := rootFS.ReadDir(usbRoot)
 	if err != nil {
 		return nil, fmt.Errorf("lecture %s: %w", usbRoot, err)
 	}
@@ -336,7 +336,7 @@ func ListUSBDevices() ([]USBDevice, error) {
 		devicePath := filepath.Join(usbRoot, name)
 
 		// Vérifier que c'est un device réel (pas un lien mort)
-		if _, err := os.Stat(devicePath); os.IsNotExist(err) {
+		if _, err := rootFS.Stat(devicePath); os.IsNotExist(err) {
 			continue
 		}
 
@@ -389,11 +389,11 @@ func ListUSBDevices() ([]USBDevice, error) {
 func ListUSBCPorts() ([]USBCPort, error) {
 	// Vérifier si le système expose les infos USB-C
 	buf := make([]byte, maxSysfsFileSize)
-	if _, err := os.Stat(typecRoot); os.IsNotExist(err) {
+	if _, err := rootFS.Stat(typecRoot); os.IsNotExist(err) {
 		return []USBCPort{}, nil // Pas d'erreur, juste vide
 	}
 
-	entries, err := os.ReadDir(typecRoot)
+	entries, err := rootFS.ReadDir(typecRoot)
 	if err != nil {
 		return nil, fmt.Errorf("lecture %s: %w", typecRoot, err)
 	}
@@ -444,7 +444,7 @@ type SerialPort struct {
 
 // ListSerialPorts liste tous les ports série physiques
 func ListSerialPorts() ([]SerialPort, error) {
-	entries, err := os.ReadDir(ttyRoot)
+	entries, err := rootFS.ReadDir(ttyRoot)
 	if err != nil {
 		return nil, fmt.Errorf("lecture %s: %w", ttyRoot, err)
 	}
@@ -471,7 +471,7 @@ func ListSerialPorts() ([]SerialPort, error) {
 
 		// Vérifier présence de /device/ (indique port physique ou USB)
 		deviceLinkPath := filepath.Join(devicePath, "device")
-		if _, err := os.Stat(deviceLinkPath); os.IsNotExist(err) {
+		if _, err := rootFS.Stat(deviceLinkPath); os.IsNotExist(err) {
 			continue
 		}
 
@@ -482,7 +482,7 @@ func ListSerialPorts() ([]SerialPort, error) {
 
 		// Lire nom du driver
 		driverPath := filepath.Join(devicePath, "device", "driver")
-		if target, err := os.Readlink(driverPath); err == nil {
+		if target, err := rootFS.Readlink(driverPath); err == nil {
 			port.Driver = filepath.Base(target)
 
 			// Filtrer ports fantômes avec driver "port"
@@ -0,0 +1,223 @@
+package smbios
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Structure types decoded by gobox.
+const (
+	TypeBIOS                = 0
+	TypeSystem              = 1
+	TypeBaseboard           = 2
+	TypeChassis             = 3
+	TypePhysicalMemoryArray = 16
+	TypeMemoryDevice        = 17
+	TypeEndOfTable          = 127
+)
+
+// ═══════════════════════════════════════════════════════════════════
+// ENTRY POINT
+// ═══════════════════════════════════════════════════════════════════
+
+// EntryPoint is the decoded SMBIOS entry point structure (2.x or 3.x).
+type EntryPoint struct {
+	Major        int
+	Minor        int
+	Revision     int    // docrev on 3.x, 0 otherwise
+	TableAddress uint64 // physical address, informational only
+	TableLength  int    // exact length (2.x) or maximum size (3.x)
+	Structures   int    // structure count (2.x only, 0 on 3.x)
+}
+
+// Version returns the version as "major.minor".
+func (e EntryPoint) Version() string {
+	return fmt.Sprintf("%d.%d", e.Major, e.Minor)
+}
+
+// AtLeast reports whether the entry point declares version >= major.minor.
+func (e EntryPoint) AtLeast(major, minor int) bool {
+	return e.Major > major || (e.Major == major && e.Minor >= minor)
+}
+
+// ParseEntryPoint decodes a "_SM_" (2.1+), "_SM3_" (3.0+) or legacy "_DMI_"
+// entry point as exposed by /sys/firmware/dmi/tables/smbios_entry_point.
+//
+// Returns an error on truncated input, unknown anchor or bad checksum.
+func ParseEntryPoint(b []byte) (EntryPoint, error) {
+	switch {
+	case bytes.HasPrefix(b, []byte("_SM3_")):
+		if len(b) < 0x18 || len(b) < int(b[0x06]) {
+			return EntryPoint{}, errors.New("smbios: truncated 3.x entry point")
+		}
+		if !checksumOK(b[:b[0x06]]) {
+			return EntryPoint{}, errors.New("smbios: bad 3.x entry point checksum")
+		}
+		return EntryPoint{
+			Major:        int(b[0x07]),
+			Minor:        int(b[0x08]),
+			Revision:     int(b[0x09]),
+			TableLength:  int(binary.LittleEndian.Uint32(b[0x0C:])),
+			TableAddress: binary.LittleEndian.Uint64(b[0x10:]),
+		}, nil
+
+	case bytes.HasPrefix(b, []byte("_SM_")):
+		if len(b) < 0x1F || len(b) < int(b[0x05]) {
+			return EntryPoint{}, errors.New("smbios: truncated 2.x entry point")
+		}
+		if !checksumOK(b[:b[0x05]]) {
+			return EntryPoint{}, errors.New("smbios: bad 2.x entry point checksum")
+		}
+		if !bytes.Equal(b[0x10:0x15], []byte("_DMI_")) || !checksumOK(b[0x10:0x1F]) {
+			return EntryPoint{}, errors.New("smbios: bad intermediate entry point")
+		}
+		return EntryPoint{
+			Major:        int(b[0x06]),
+			Minor:        int(b[0x07]),
+			TableLength:  int(binary.LittleEndian.Uint16(b[0x16:])),
+			TableAddress: uint64(binary.LittleEndian.Uint32(b[0x18:])),
+			Structures:   int(binary.LittleEndian.Uint16(b[0x1C:])),
+		}, nil
+
+	case bytes.HasPrefix(b, []byte("_DMI_")):
+		if len(b) < 0x0F {
+			return EntryPoint{}, errors.New("smbios: truncated legacy entry point")
+		}
+		if !checksumOK(b[:0x0F]) {
+			return EntryPoint{}, errors.New("smbios: bad legacy entry point checksum")
+		}
+		return EntryPoint{
+			Major:        int(b[0x0E] >> 4),
+			Minor:        int(b[0x0E] & 0x0F),
+			TableLength:  int(binary.LittleEndian.Uint16(b[0x06:])),
+			TableAddress: uint64(binary.LittleEndian.Uint32(b[0x08:])),
+			Structures:   int(binary.LittleEndian.Uint16(b[0x0C:])),
+		}, nil
+	}
+
+	return EntryPoint{}, errors.New("smbios: unknown entry point anchor")
+}
+
+func checksumOK(b []byte) bool {
+	var sum byte
+	for _, c := range b {
+		sum += c
+	}
+	return sum == 0
+}
+
+// ═══════════════════════════════════════════════════════════════════
+// STRUCTURE TABLE
+// ═══════════════════════════════════════════════════════════════════
+
+// Structure is one SMBIOS structure: formatted area plus string set.
+type Structure struct {
+	Type      uint8
+	Handle    uint16
+	Formatted []byte   // formatted area, header included (offsets match the spec)
+	Strings   []string // string set, Strings[0] is string number 1
+}
+
+// ParseTable splits a raw structure table (/sys/firmware/dmi/tables/DMI)
+// into structures, stopping at the end-of-table marker (type 127).
+//
+// A truncated trailing structure is an error; structures decoded before
+// it are still returned.
+func ParseTable(b []byte) ([]Structure, error) {
+	structures := make([]Structure, 0, 64)
+
+	for off := 0; off+4 <= len(b); {
+		length := int(b[off+1])
+		if length < 4 || off+length > len(b) {
+			return structures, fmt.Errorf("smbios: malformed structure at offset %d", off)
+		}
+
+		s := Structure{
+			Type:      b[off],
+			Handle:    binary.LittleEndian.Uint16(b[off+2:]),
+			Formatted: b[off : off+length],
+		}
+
+		// String set: NUL-terminated strings ended by an extra NUL.
+		// A structure without strings is followed by two NULs.
+		end := bytes.Index(b[off+length:], []byte{0, 0})
+		if end < 0 {
+			return structures, fmt.Errorf("smbios: unterminated strings at offset %d", off)
+		}
+		if end > 0 {
+			for str := range bytes.SplitSeq(b[off+length:off+length+end], []byte{0}) {
+				s.Strings = append(s.Strings, string(str))
+			}
+		}
+
+		structures = append(structures, s)
+		if s.Type == TypeEndOfTable {
+			break
+		}
+		off += length + end + 2
+	}
+
+	return structures, nil
+}
+
+// Has reports whether the formatted area contains size bytes at offset off.
+// Fields added by later spec versions are only present in longer structures.
+func (s Structure) Has(off, size int) bool {
+	return off+size <= len(s.Formatted)
+}
+
+// U8 returns the byte at offset off, or 0 if absent.
+func (s Structure) U8(off int) uint8 {
+	if !s.Has(off, 1) {
+		return 0
+	}
+	return s.Formatted[off]
+}
+
+// U16 returns the little-endian word at offset off, or 0 if absent.
+func (s Structure) U16(off int) uint16 {
+	if !s.Has(off, 2) {
+		return 0
+	}
+	return binary.LittleEndian.Uint16(s.Formatted[off:])
+}
+
+// U32 returns the little-endian dword at offset off, or 0 if absent.
+func (s Structure) U32(off int) uint32 {
+	if !s.Has(off, 4) {
+		return 0
+	}
+	return binary.LittleEndian.Uint32(s.Formatted[off:])
+}
+
+// U64 returns the little-endian qword at offset off, or 0 if absent.
+func (s Structure) U64(off int) uint64 {
+	if !s.Has(off, 8) {
+		return 0
+	}
+	return binary.LittleEndian.Uint64(s.Formatted[off:])
+}
+
+// String resolves the string number stored at offset off.
+// Returns "" for string number 0 ("none"), out-of-range numbers or absent fields.
+func (s Structure) String(off int) string {
+	n := int(s.U8(off))
+	if n == 0 || n > len(s.Strings) {
+		return ""
+	}
+	return strings.TrimSpace(s.Strings[n-1])
+}
+
+// Filter returns the structures of the given type, in table order.
+func Filter(structures []Structure, typ uint8) []Structure {
+	var out []Structure
+	for _, s := range structures {
+		if s.Type == typ {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,123 @@
+package sysfs
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FS is the filesystem root under which sysfs and procfs paths are resolved.
+//
+// Probes always work with absolute, logical paths such as "/sys/block/sda/size".
+// [FS.Path] maps them below Root, so the same code can read the live machine
+// ([Host]) or a captured tree (e.g. FS{Root: "testdata/thinkpad-t480"}).
+type FS struct {
+	Root string // "" or "/" means the live system
+}
+
+// Host is the live system root.
+var Host = FS{Root: "/"}
+
+// NewFS returns an FS rooted at the given directory.
+func NewFS(root string) FS {
+	return FS{Root: filepath.Clean(root)}
+}
+
+// IsHost reports whether f reads the live system.
+func (f FS) IsHost() bool {
+	return f.Root == "" || f.Root == "/"
+}
+
+// Path resolves a logical absolute path below the root.
+func (f FS) Path(p string) string {
+	if f.IsHost() {
+		return p
+	}
+	return filepath.Join(f.Root, filepath.Clean("/"+p))
+}
+
+// Logical converts a resolved path back to its logical absolute form.
+//
+// It is the inverse of [FS.Path] and is used for paths coming back from
+// directory walks or globbing.
+func (f FS) Logical(p string) string {
+	if f.IsHost() {
+		return p
+	}
+	rel, found := strings.CutPrefix(p, f.Root)
+	if !found || (rel != "" && rel[0] != '/') {
+		return p
+	}
+	if rel == "" {
+		return "/"
+	}
+	return rel
+}
+
+// ReadFile is [ReadFile] resolved below the root.
+func (f FS) ReadFile(p string) (string, error) {
+	return ReadFile(f.Path(p))
+}
+
+// ReadFileWithBuffer is [ReadFileWithBuffer] resolved below the root.
+func (f FS) ReadFileWithBuffer(p string, buf []byte) (string, error) {
+	return ReadFileWithBuffer(f.Path(p), buf)
+}
+
+// ReadFileOptional is [ReadFileOptional] resolved below the root.
+func (f FS) ReadFileOptional(p string) (string, error) {
+	return ReadFileOptional(f.Path(p))
+}
+
+// ReadInt is [ReadInt] resolved below the root.
+func (f FS) ReadInt(p string) (int, error) {
+	return ReadInt(f.Path(p))
+}
+
+// ReadFloat is [ReadFloat] resolved below the root.
+func (f FS) ReadFloat(p string) (float64, error) {
+	return ReadFloat(f.Path(p))
+}
+
+// ReadBool is [ReadBool] resolved below the root.
+func (f FS) ReadBool(p string) (bool, error) {
+	return ReadBool(f.Path(p))
+}
+
+// ReadBytes reads a whole file below the root without the
+// [MaxSysfsFileSize] limit. Reserved for binary blobs and procfs files.
+func (f FS) ReadBytes(p string) ([]byte, error) {
+	return os.ReadFile(f.Path(p))
+}
+
+// Open opens a file below the root.
+func (f FS) Open(p string) (*os.File, error) {
+	return os.Open(f.Path(p))
+}
+
+// ReadDir lists a directory below the root.
+func (f FS) ReadDir(p string) ([]os.DirEntry, error) {
+	return os.ReadDir(f.Path(p))
+}
+
+// Stat follows symlinks and describes a file below the root.
+func (f FS) Stat(p string) (os.FileInfo, error) {
+	return os.Stat(f.Path(p))
+}
+
+// Readlink returns the target of a symlink below the root.
+func (f FS) Readlink(p string) (string, error) {
+	return os.Readlink(f.Path(p))
+}
+
+// Glob is [filepath.Glob] below the root; matches are returned as logical paths.
+func (f FS) Glob(pattern string) ([]string, error) {
+	matches, err := filepath.Glob(f.Path(pattern))
+	if err != nil {
+		return nil, err
+	}
+	for i, m := range matches {
+		matches[i] = f.Logical(m)
+	}
+	return matches, nil
+}
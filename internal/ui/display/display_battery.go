@@ -2,34 +2,55 @@ package ui
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	diagBattery "gobox/internal/diagnostic/battery"
 	"gobox/internal/probe"
 )
 
 func DisplayBatteryReport() error {
-	// 1. Récupérer les données brutes
-	info, err := probe.GetBatteryInfo()
+	// 1. Exécuter le test de santé de chaque batterie
+	report, err := diagBattery.RunAllBatteryTests()
 	if err != nil {
-		return fmt.Errorf("erreur batterie: %w", err)
+		return fmt.Errorf("test batterie échoué: %w", err)
 	}
 
-	if info.Capacity < 0 {
+	if len(report.Batteries) == 0 {
 		fmt.Println("❌ Batterie        : Pas de batterie détectée")
+		printPowerSupplies(report)
 		return nil
 	}
 
-	// 2. Exécuter le test de santé
-	result, err := diagBattery.RunBatteryTest()
-	if err != nil {
-		return fmt.Errorf("test batterie échoué: %w", err)
-	}
-
-	// 3. Afficher le rapport unifié
+	// 2. Afficher le rapport unifié
 	fmt.Println("\n╭─────────────────────────────────────────╮")
 	fmt.Println("│         RAPPORT BATTERIE                │")
 	fmt.Println("╰─────────────────────────────────────────╯")
 
+	for _, result := range report.Batteries {
+		if len(report.Batteries) > 1 {
+			fmt.Printf("\n━━━ %s ━━━\n", result.Name)
+		}
+		printBattery(result)
+	}
+
+	// Ensemble (portables à deux batteries)
+	if len(report.Batteries) > 1 {
+		fmt.Printf("\n🔋 Ensemble\n")
+		fmt.Printf("   Charge globale   : %d%%\n", report.Capacity)
+		fmt.Printf("   Santé globale    : %.1f%%\n", report.HealthPercentage)
+		fmt.Printf("   Note             : [%s]\n", report.Grade)
+	}
+
+	printPowerSupplies(report)
+
+	fmt.Println()
+	return nil
+}
+
+func printBattery(result diagBattery.BatteryHealthTest) {
+	info := result.Info
+
 	// État actuel
 	fmt.Printf("\n📊 État actuel\n")
 	fmt.Printf("   Charge           : %d%% (%s)\n", info.Capacity, info.Status)
@@ -38,11 +59,15 @@ func DisplayBatteryReport() error {
 
 	// Capacités
 	fmt.Printf("\n🔋 Capacités\n")
-	fmt.Printf("   Capacité actuelle : %.0f mWh (%.0f mAh)\n",
-		info.CurrentCapacity, info.EnergyAH)
-	fmt.Printf("   Capacité neuve    : %.0f mWh\n", info.DesignCapacity)
+	fmt.Printf("   Capacité actuelle : %s\n", capacityText(info.FullWh, info.FullAh))
+	fmt.Printf("   Capacité neuve    : %s\n", capacityText(info.DesignWh, info.DesignAh))
+	fmt.Printf("   Charge restante   : %s\n", capacityText(info.NowWh, info.NowAh))
 	fmt.Printf("   Dégradation       : %.1f%%\n",
 		100-result.HealthPercentage)
+	if info.PowerNow > 0 {
+		fmt.Printf("   Puissance         : %.1f W (%.0f mA)\n",
+			info.PowerNow/1_000_000, info.CurrentNow/1_000)
+	}
 
 	// Détails techniques
 	fmt.Printf("\n⚙️  Détails techniques\n")
@@ -59,6 +84,26 @@ func DisplayBatteryReport() error {
 		fmt.Printf("   Technologie      : %s\n", *info.Technology)
 	}
 	fmt.Printf("   Tension actuelle : %.2f V\n", info.VoltageNow/1_000_000)
+	if info.VoltageDesign > 0 {
+		fmt.Printf("   Tension nominale : %.2f V\n", info.VoltageDesign/1_000_000)
+	}
+	fmt.Printf("   Compteurs        : %s\n", unitText(info.Unit))
+	if info.TemperatureC != nil {
+		fmt.Printf("   Température      : %.1f °C\n", *info.TemperatureC)
+	}
+	if info.Health != nil {
+		fmt.Printf("   État firmware    : %s\n", *info.Health)
+	}
+	if info.CapacityLevel != nil {
+		fmt.Printf("   Niveau           : %s\n", *info.CapacityLevel)
+	}
+	if info.Alarm != nil {
+		fmt.Printf("   Seuil d'alarme   : %s\n", alarmText(info))
+	}
+	if info.ManufactureDate != nil {
+		fmt.Printf("   Fabrication      : %s (%.1f ans)\n",
+			info.ManufactureDate.Format("02/01/2006"), result.AgeYears)
+	}
 
 	// Problèmes détectés
 	if len(result.Issues) > 0 {
@@ -69,7 +114,187 @@ func DisplayBatteryReport() error {
 	} else {
 		fmt.Printf("\n✅ Aucun problème détecté\n")
 	}
+}
+
+// capacityText affiche une capacité dans les deux unités, la seconde
+// seulement si la tension nominale a permis de la calculer.
+func capacityText(wh, ah float64) string {
+	switch {
+	case wh > 0 && ah > 0:
+		return fmt.Sprintf("%.1f Wh (%.0f mAh)", wh, ah*1000)
+	case ah > 0:
+		return fmt.Sprintf("%.0f mAh", ah*1000)
+	default:
+		return fmt.Sprintf("%.1f Wh", wh)
+	}
+}
+
+// alarmText convertit le seuil d'alarme, exprimé dans l'unité native.
+func alarmText(info probe.BatteryInfo) string {
+	if info.Unit == probe.UnitCharge {
+		return fmt.Sprintf("%.0f mAh", *info.Alarm/1_000)
+	}
+	return fmt.Sprintf("%.2f Wh", *info.Alarm/1_000_000)
+}
+
+func unitText(unit probe.BatteryUnit) string {
+	if unit == probe.UnitCharge {
+		return "charge_* (µAh)"
+	}
+	return "energy_* (µWh)"
+}
 
+func printPowerSupplies(report diagBattery.BatteryReport) {
+	if len(report.Supplies) == 0 {
+		return
+	}
+	fmt.Printf("\n🔌 Alimentations\n")
+	for _, s := range report.Supplies {
+		fmt.Printf("   %-16s : %s%s\n", s.Name, supplyKind(s), supplyState(s))
+	}
+}
+
+func supplyKind(s probe.PowerSupply) string {
+	if s.USBType != "" {
+		return s.Type + " " + s.USBType
+	}
+	return s.Type
+}
+
+func supplyState(s probe.PowerSupply) string {
+	if s.Online {
+		return " — branchée ✅"
+	}
+	return " — débranchée"
+}
+
+// DischargeCurve rend la courbe de charge (%) en fonction du temps en
+// histogramme de texte (cols × rows au plus), utilisable dans une vue TUI.
+func DischargeCurve(result *diagBattery.DischargeResult, cols, rows int) string {
+	samples := result.Samples
+	if len(samples) == 0 || cols <= 0 || rows <= 0 {
+		return ""
+	}
+	cols = min(cols, len(samples))
+
+	heights := make([]int, cols)
+	for c := range cols {
+		s := samples[c*len(samples)/cols]
+		heights[c] = (s.Capacity*rows + 50) / 100
+	}
+
+	var b strings.Builder
+	for row := rows; row > 0; row-- {
+		fmt.Fprintf(&b, "%4d%% │", row*100/rows)
+		for _, h := range heights {
+			if h >= row {
+				b.WriteRune('█')
+			} else {
+				b.WriteRune(' ')
+			}
+		}
+		b.WriteByte('\n')
+	}
+	fmt.Fprintf(&b, "      └%s\n", strings.Repeat("─", cols))
+	return b.String()
+}
+
+// DisplayDischarge affiche le bilan d'un test de décharge
+func DisplayDischarge(result *diagBattery.DischargeResult) {
+	fmt.Println("\n╭─────────────────────────────────────────╮")
+	fmt.Println("│         TEST DE DÉCHARGE                │")
+	fmt.Println("╰─────────────────────────────────────────╯")
+
+	load := "au repos"
+	if result.CPULoad {
+		load = "sous charge CPU"
+	}
+	fmt.Printf("\n🔋 %s : %s %s (%s)\n", result.Battery,
+		result.Duration.Round(time.Second), load, result.Stopped)
+	grade := string(result.Grade)
+	if grade == "" {
+		grade = "-"
+	}
+	fmt.Printf("   Note             : [%s]\n", grade)
+	fmt.Printf("   Charge perdue    : %d%% (%d points)\n", result.PercentDrop, len(result.Samples))
+	fmt.Printf("   Énergie mesurée  : %.2f Wh (jauge : %.2f Wh)\n", result.EnergyDrawnWh, result.CounterDrawnWh)
+	fmt.Printf("   Puissance moy.   : %.1f W\n", result.AvgPowerW)
+
+	fmt.Printf("\n📏 Capacité\n")
+	if result.MeasuredCapacityWh > 0 {
+		fmt.Printf("   Mesurée          : %.1f Wh (%.1f%% de la capacité neuve)\n",
+			result.MeasuredCapacityWh, result.MeasuredHealth)
+	} else {
+		fmt.Printf("   Mesurée          : non extrapolée\n")
+	}
+	fmt.Printf("   Annoncée         : %.1f Wh\n", result.ReportedCapacityWh)
+	fmt.Printf("   Neuve            : %.1f Wh\n", result.DesignCapacityWh)
+	if result.Runtime > 0 {
+		fmt.Printf("   Autonomie        : %s pour une charge complète\n", result.Runtime.Round(time.Minute))
+	}
+
+	if curve := DischargeCurve(result, 60, 10); curve != "" {
+		fmt.Printf("\n📉 Courbe\n%s", curve)
+	}
+
+	if len(result.Issues) > 0 {
+		fmt.Printf("\n⚠️  Problèmes détectés\n")
+		for _, issue := range result.Issues {
+			fmt.Printf("   • %s\n", issue)
+		}
+	} else {
+		fmt.Printf("\n✅ Aucun problème détecté\n")
+	}
+	fmt.Println()
+}
+
+// DisplayChargeControl affiche les réglages de charge d'une batterie
+func DisplayChargeControl(name string, control probe.ChargeControl) {
+	fmt.Printf("\n🔌 Pilotage de la charge (%s)\n", name)
+	if !control.Supported() {
+		fmt.Println("   Non supporté par le pilote")
+		return
+	}
+	if control.StartThreshold != nil {
+		fmt.Printf("   Reprise de charge : %d%%\n", *control.StartThreshold)
+	}
+	if control.EndThreshold != nil {
+		fmt.Printf("   Arrêt de charge   : %d%%\n", *control.EndThreshold)
+	}
+	if control.Behaviour != "" {
+		fmt.Printf("   Comportement      : %s (%s)\n", control.Behaviour, strings.Join(control.Behaviours, ", "))
+	}
+}
+
+// DisplayCalibration affiche le bilan d'une calibration
+func DisplayCalibration(result *diagBattery.CalibrationResult) {
+	fmt.Println("\n╭─────────────────────────────────────────╮")
+	fmt.Println("│         CALIBRATION BATTERIE            │")
+	fmt.Println("╰─────────────────────────────────────────╯")
+
+	state := "interrompue"
+	if result.Completed {
+		state = "terminée"
+	}
+	fmt.Printf("\n🔋 %s : calibration %s en %s\n", result.Battery, state, result.Duration.Round(time.Minute))
+	for _, phase := range []diagBattery.CalibrationPhase{
+		diagBattery.PhaseCharge, diagBattery.PhaseDischarge, diagBattery.PhaseRecharge,
+	} {
+		if d, ok := result.Phases[phase]; ok {
+			fmt.Printf("   %-17s: %s\n", phase, d.Round(time.Minute))
+		}
+	}
+	if result.ForcedDischarge {
+		fmt.Println("   Décharge pilotée par charge_behaviour (secteur branché)")
+	}
+
+	fmt.Printf("\n📏 Capacité annoncée\n")
+	fmt.Printf("   Avant            : %.1f Wh\n", result.FullBeforeWh)
+	if result.Completed {
+		fmt.Printf("   Après            : %.1f Wh\n", result.FullAfterWh)
+	}
+	if result.DesignWh > 0 {
+		fmt.Printf("   Neuve            : %.1f Wh\n", result.DesignWh)
+	}
 	fmt.Println()
-	return nil
 }
@@ -0,0 +1,48 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	diagDisk "gobox/internal/diagnostic/disk"
+)
+
+// DisplayBenchmark affiche un benchmark disque et la note qui en résulte
+func DisplayBenchmark(test *diagDisk.DiskHealthTest) {
+	bench := test.Benchmark
+	if bench == nil {
+		return
+	}
+	fmt.Println("\n╭─────────────────────────────────────────╮")
+	fmt.Println("│         BENCHMARK DISQUE                │")
+	fmt.Println("╰─────────────────────────────────────────╯")
+
+	fmt.Printf("\n💽 %s : %s (attendu pour %s)\n", test.DiskName, test.Model, bench.Expected.Profile)
+	fmt.Printf("   Note benchmark   : [%s]\n", bench.Grade)
+	fmt.Printf("   Note disque      : [%s]\n", test.Grade)
+
+	fmt.Printf("\n🚀 Débits\n")
+	fmt.Printf("   Lecture séq.     : %.0f MB/s (attendu %.0f)\n", bench.SeqReadMBps, bench.Expected.SeqReadMBps)
+	if bench.WriteTested {
+		fmt.Printf("   Écriture séq.    : %.0f MB/s (attendu %.0f)\n", bench.SeqWriteMBps, bench.Expected.SeqWriteMBps)
+	} else {
+		fmt.Printf("   Écriture séq.    : non testée\n")
+	}
+	fmt.Printf("   Lecture 4K       : %.0f IOPS (attendu %.0f, %d en parallèle)\n",
+		bench.RandReadIOPS, bench.Expected.RandReadIOPS, bench.RandomWorkers)
+
+	fmt.Printf("\n⏱️  Latences 4K\n")
+	fmt.Printf("   p50 / p95 / p99  : %s / %s / %s\n", bench.LatencyP50.Round(time.Microsecond),
+		bench.LatencyP95.Round(time.Microsecond), bench.LatencyP99.Round(time.Microsecond))
+	fmt.Printf("   Max              : %s\n", bench.LatencyMax.Round(time.Microsecond))
+
+	if len(test.Issues) > 0 {
+		fmt.Printf("\n⚠️  Problèmes détectés\n")
+		for _, issue := range test.Issues {
+			fmt.Printf("   • %s\n", issue)
+		}
+	} else {
+		fmt.Printf("\n✅ Aucun problème détecté\n")
+	}
+	fmt.Println()
+}
@@ -0,0 +1,84 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"gobox/internal/probe"
+)
+
+// PrintBluetoothAdapters displays all detected Bluetooth controllers
+func PrintBluetoothAdapters() {
+	adapters, err := probe.ListBluetoothAdapters()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		return
+	}
+
+	if len(adapters) == 0 {
+		fmt.Println("\nNo Bluetooth adapters detected.")
+		return
+	}
+
+	fmt.Print("\n" + strings.Repeat("=", 70) + "\n")
+	fmt.Printf("  BLUETOOTH ADAPTERS DETECTED: %d\n", len(adapters))
+	fmt.Print(strings.Repeat("=", 70) + "\n\n")
+
+	for i, a := range adapters {
+		fmt.Printf("Adapter #%d\n", i+1)
+		fmt.Println(strings.Repeat("-", 70))
+		fmt.Printf("  Name            : %s\n", a.Name)
+
+		if model := strings.TrimSpace(a.Vendor + " " + a.Product); model != "" {
+			fmt.Printf("  Model           : %s\n", model)
+		}
+
+		if a.VendorID != "" {
+			fmt.Printf("  ID              : %s:%s\n", a.VendorID, a.ProductID)
+		}
+
+		if a.Type != "" {
+			fmt.Printf("  Bus             : %s\n", a.Type)
+		}
+
+		if a.Driver != "" {
+			fmt.Printf("  Driver          : %s\n", a.Driver)
+		}
+
+		if a.MACAddress != "" {
+			fmt.Printf("  Address         : %s\n", a.MACAddress)
+		}
+
+		if a.HCIVersion != "" {
+			fmt.Printf("  Version         : Bluetooth %s (%s, subversion 0x%04x)\n",
+				a.HCIVersion, a.Manufacturer, a.LMPSubversion)
+		}
+
+		fmt.Printf("  Radio           : ")
+		switch {
+		case a.HardBlocked:
+			fmt.Printf("Hard blocked 🔒\n")
+		case a.SoftBlocked:
+			fmt.Printf("Soft blocked ❌\n")
+		default:
+			fmt.Printf("Unblocked ✅\n")
+		}
+
+		// HCI state is only readable on the live system
+		if a.MACAddress != "" {
+			fmt.Printf("  State           : ")
+			if a.IsUp {
+				fmt.Printf("UP ✅\n")
+			} else {
+				fmt.Printf("DOWN ❌\n")
+			}
+		}
+
+		fmt.Println(strings.Repeat("-", 70))
+
+		if i < len(adapters)-1 {
+			fmt.Println()
+		}
+	}
+	fmt.Println()
+}
@@ -20,9 +20,9 @@ func DiskInfo() {
 		return
 	}
 
-	fmt.Printf("\n" + strings.Repeat("=", 70) + "\n")
+	fmt.Print("\n" + strings.Repeat("=", 70) + "\n")
 	fmt.Printf("  Disques détectés : %d\n", len(disks))
-	fmt.Printf(strings.Repeat("=", 70) + "\n\n")
+	fmt.Print(strings.Repeat("=", 70) + "\n\n")
 
 	for i, diskName := range disks {
 		info, err := probe.GetDiskInfo(diskName)
@@ -52,8 +52,14 @@ func DiskInfo() {
 		if info.Model != "" {
 			fmt.Printf("  Modèle          : %s\n", info.Model)
 		}
+		if info.Serial != "" {
+			fmt.Printf("  N° de série     : %s\n", info.Serial)
+		}
 
 		fmt.Printf("  Type            : %s\n", info.Type)
+		if info.Bus != "" {
+			fmt.Printf("  Bus             : %s\n", info.Bus)
+		}
 		fmt.Printf("  Taille          : %s (%d octets)\n", sizeStr, info.SizeBytes)
 
 		if len(info.Partitions) > 0 {
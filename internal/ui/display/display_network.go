@@ -20,14 +20,23 @@ func PrintNetworkInterfaces() {
 		return
 	}
 
-	fmt.Printf("\n" + strings.Repeat("=", 70) + "\n")
+	fmt.Print("\n" + strings.Repeat("=", 70) + "\n")
 	fmt.Printf("  NETWORK INTERFACES DETECTED: %d\n", len(interfaces))
-	fmt.Printf(strings.Repeat("=", 70) + "\n\n")
+	fmt.Print(strings.Repeat("=", 70) + "\n\n")
 
 	for i, iface := range interfaces {
 		fmt.Printf("Interface #%d\n", i+1)
 		fmt.Println(strings.Repeat("-", 70))
 		fmt.Printf("  Name            : %s\n", iface.Name)
+		fmt.Printf("  Model           : %s\n", iface.Model())
+
+		if iface.VendorID != "" {
+			fmt.Printf("  ID              : %s %s:%s\n", strings.ToUpper(iface.Bus), iface.VendorID, iface.DeviceID)
+		}
+
+		if iface.FirmwareVersion != "" {
+			fmt.Printf("  Firmware        : %s\n", iface.FirmwareVersion)
+		}
 
 		if iface.MACAddress != "" {
 			fmt.Printf("  MAC Address     : %s\n", iface.MACAddress)
@@ -44,10 +53,47 @@ func PrintNetworkInterfaces() {
 			fmt.Printf("DOWN ❌\n")
 		}
 
+		if w := iface.Wifi; w != nil {
+			fmt.Printf("  Wi-Fi           : %s (802.11%s)\n", w.Generation, strings.Join(w.PHYModes, "/"))
+			fmt.Printf("  Bands           : %s, %d channels\n", strings.Join(w.Bands, ", "), w.Channels)
+			if w.AntennasTX > 0 || w.AntennasRX > 0 {
+				fmt.Printf("  Antennas        : %dT%dR\n", w.AntennasTX, w.AntennasRX)
+			} else if w.SpatialStreams > 0 {
+				fmt.Printf("  Streams         : %d\n", w.SpatialStreams)
+			}
+		}
+
 		if iface.Speed != "" {
 			fmt.Printf("  Speed           : %s\n", iface.Speed)
 		}
 
+		if l := iface.Link; l != nil {
+			if l.Duplex != "" {
+				autoneg := "off"
+				if l.Autoneg {
+					autoneg = "on"
+				}
+				fmt.Printf("  Duplex          : %s (autoneg %s)\n", l.Duplex, autoneg)
+			}
+			if len(l.Supported) > 0 {
+				fmt.Printf("  Supported modes : %s\n", strings.Join(l.Supported, " "))
+			}
+			if len(l.Advertised) > 0 {
+				fmt.Printf("  Advertised      : %s\n", strings.Join(l.Advertised, " "))
+			}
+			if len(l.Partner) > 0 {
+				fmt.Printf("  Partner         : %s\n", strings.Join(l.Partner, " "))
+			}
+		}
+
+		if w := iface.WoL; w != nil && w.Supported != "" {
+			enabled := w.Enabled
+			if enabled == "" {
+				enabled = "d"
+			}
+			fmt.Printf("  Wake-on-LAN     : supports %s, enabled %s\n", w.Supported, enabled)
+		}
+
 		fmt.Printf("  Carrier         : ")
 		if iface.Carrier {
 			fmt.Printf("Connected 🔌\n")
@@ -55,8 +101,20 @@ func PrintNetworkInterfaces() {
 			fmt.Printf("Disconnected\n")
 		}
 
-		if iface.IPAddress != "" {
-			fmt.Printf("  IP              : %s\n", iface.IPAddress)
+		if iface.MTU > 0 {
+			fmt.Printf("  MTU             : %d\n", iface.MTU)
+		}
+
+		for i, addr := range iface.Addresses {
+			label := ""
+			if i == 0 {
+				label = "Addresses"
+			}
+			fmt.Printf("  %-15s : %s\n", label, addr)
+		}
+
+		if len(iface.Gateways) > 0 {
+			fmt.Printf("  Gateway         : %s\n", strings.Join(iface.Gateways, ", "))
 		}
 
 		fmt.Println(strings.Repeat("-", 70))
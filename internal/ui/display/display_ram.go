@@ -30,6 +30,10 @@ func DisplayRamInfo() {
 			fmt.Printf("  Tension       : %.1f V\n", s.ConfiguredVoltage)
 		}
 
+		if s.ErrorCorrection != "" {
+			fmt.Printf("  Correction    : %s\n", s.ErrorCorrection)
+		}
+
 		if s.PartNumber != "" {
 			fmt.Printf("  Numéro pièce  : %s\n", s.PartNumber)
 		}
@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	diagDisk "gobox/internal/diagnostic/disk"
+)
+
+// surfaceGlyphs sont les symboles de la carte, par classe de zone
+var surfaceGlyphs = map[diagDisk.SurfaceClass]rune{
+	diagDisk.SurfacePending:    '·',
+	diagDisk.SurfaceOK:         '█',
+	diagDisk.SurfaceSlow:       '▒',
+	diagDisk.SurfaceVerySlow:   '░',
+	diagDisk.SurfaceUnreadable: 'X',
+}
+
+// SurfaceMap rend la carte du scan en grille de texte (cols × rows au plus),
+// utilisable telle quelle dans une vue TUI.
+func SurfaceMap(result *diagDisk.SurfaceScanResult, cols, rows int) string {
+	var b strings.Builder
+	for _, line := range result.Grid(cols, rows) {
+		for _, class := range line {
+			b.WriteRune(surfaceGlyphs[class])
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// SurfaceLegend est la légende de SurfaceMap
+func SurfaceLegend() string {
+	return "█ OK   ▒ lent   ░ très lent   X illisible   · non lu"
+}
+
+// DisplaySurfaceScan affiche le bilan d'un scan de surface
+func DisplaySurfaceScan(result *diagDisk.SurfaceScanResult) {
+	fmt.Println("\n╭─────────────────────────────────────────╮")
+	fmt.Println("│         SCAN DE SURFACE                 │")
+	fmt.Println("╰─────────────────────────────────────────╯")
+
+	fmt.Printf("\n💽 %s : %.1f GB par zones de %d KiB\n",
+		result.DiskName, float64(result.SizeBytes)/1e9, result.ChunkSize>>10)
+	fmt.Printf("   Note             : [%s]\n", result.Grade)
+	fmt.Printf("   Durée            : %s\n", result.Duration.Round(1e9))
+	fmt.Printf("   Zones lentes     : %d\n", result.Slow)
+	fmt.Printf("   Zones très lentes: %d\n", result.VerySlow)
+	fmt.Printf("   Zones illisibles : %d\n", result.Unreadable)
+
+	fmt.Printf("\n⏱️  Latences\n")
+	for i, count := range result.Histogram {
+		label := fmt.Sprintf("≥ %s", diagDisk.SurfaceHistogramBounds[len(diagDisk.SurfaceHistogramBounds)-1])
+		if i < len(diagDisk.SurfaceHistogramBounds) {
+			label = fmt.Sprintf("< %s", diagDisk.SurfaceHistogramBounds[i])
+		}
+		fmt.Printf("   %-8s : %d\n", label, count)
+	}
+
+	fmt.Printf("\n🗺️  Carte\n")
+	fmt.Print(SurfaceMap(result, 64, 16))
+	fmt.Println(SurfaceLegend())
+
+	if len(result.Issues) > 0 {
+		fmt.Printf("\n⚠️  Problèmes détectés\n")
+		for _, issue := range result.Issues {
+			fmt.Printf("   • %s\n", issue)
+		}
+	} else {
+		fmt.Printf("\n✅ Aucun problème détecté\n")
+	}
+	fmt.Println()
+}
@@ -0,0 +1,61 @@
+package ui
+
+import (
+	"fmt"
+
+	"gobox/internal/probe"
+)
+
+// DisplaySystemInfo affiche l'identité de la machine (fiche d'intake).
+func DisplaySystemInfo() error {
+	info, err := probe.GetSystemInfo()
+	if err != nil {
+		return fmt.Errorf("erreur identification système: %w", err)
+	}
+
+	fmt.Println("\n╭─────────────────────────────────────────╮")
+	fmt.Println("│         IDENTITÉ MACHINE                │")
+	fmt.Println("╰─────────────────────────────────────────╯")
+
+	fmt.Printf("\n🖥️  Système\n")
+	printField("Fabricant", info.Manufacturer)
+	printField("Produit", info.Product)
+	printField("Version", info.Version)
+	printField("Famille", info.Family)
+	printField("Numéro série", info.Serial)
+	printField("UUID", info.UUID)
+
+	fmt.Printf("\n📦 Châssis\n")
+	if info.ChassisName != "" {
+		fmt.Printf("   Type             : %s (%s)\n", info.ChassisType, info.ChassisName)
+	} else {
+		fmt.Printf("   Type             : %s\n", info.ChassisType)
+	}
+	printField("Numéro série", info.ChassisSerial)
+
+	fmt.Printf("\n🔧 Carte mère\n")
+	printField("Fabricant", info.BoardVendor)
+	printField("Modèle", info.BoardName)
+	printField("Version", info.BoardVersion)
+	printField("Numéro série", info.BoardSerial)
+
+	fmt.Printf("\n⚙️  BIOS\n")
+	printField("Fabricant", info.BIOSVendor)
+	printField("Version", info.BIOSVersion)
+	printField("Date", info.BIOSDate)
+
+	if info.Serial == "" && info.UUID == "" {
+		fmt.Printf("\n⚠️  Numéro série et UUID illisibles (droits root requis ?)\n")
+	}
+
+	fmt.Println()
+	return nil
+}
+
+// printField n'affiche une ligne que si la valeur est renseignée.
+func printField(label, value string) {
+	if value == "" {
+		return
+	}
+	fmt.Printf("   %-16s : %s\n", label, value)
+}
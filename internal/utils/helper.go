@@ -1,20 +1,23 @@
 package utils
 
-import (
-	"fmt"
-	"path/filepath"
+import "errors"
 
-	"gobox/internal/sysfs"
-)
+var errNoVoltage = errors.New("unknown design voltage")
 
-func ConvertWattToAmpere(batteryPath string, energyFull float64) (float64, error) {
-	voltagePath := filepath.Join(batteryPath, "voltage_min_design")
-
-	voltDesign, err := sysfs.ReadFloat(voltagePath)
-	if err != nil {
-		return 0, fmt.Errorf("reading voltage: %w", err)
+// ConvertWattToAmpere convertit une énergie (µWh) en charge (µAh) à la
+// tension nominale de la batterie (µV, voltage_min_design).
+func ConvertWattToAmpere(energy, voltage float64) (float64, error) {
+	if voltage <= 0 {
+		return 0, errNoVoltage
 	}
+	return energy / (voltage / 1_000_000), nil
+}
 
-	mAh := (energyFull / 1_000_000) * 1000 / (voltDesign / 1_000_000)
-	return mAh, nil
+// ConvertAmpereToWatt convertit une charge (µAh) en énergie (µWh) à la
+// tension nominale de la batterie (µV).
+func ConvertAmpereToWatt(charge, voltage float64) (float64, error) {
+	if voltage <= 0 {
+		return 0, errNoVoltage
+	}
+	return charge * (voltage / 1_000_000), nil
 }
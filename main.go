@@ -5,7 +5,8 @@ import (
 	"os/exec"
 	"strings"
 
-	dimension "gobox/bin"
+	"gobox/internal/probe"
+	display "gobox/internal/ui/display"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -14,11 +15,19 @@ type model struct {
 	cursor  int
 	choices []string
 	output  string
+	wipe    *wipeState
 }
 
 func main() {
+	// Fiche d'identité de la machine avant le menu des scripts
+	if err := display.DisplaySystemInfo(); err != nil {
+		fmt.Println("Error:", err)
+	}
+
+	// L'effacement des disques ne passe plus par SHRED.sh mais par le
+	// moteur natif (voir wipe.go)
 	m := model{
-		choices: []string{"MAJ.sh", "CLEAN.sh", "SHRED.sh", "TEST_HW.sh", "PRINT.sh"},
+		choices: []string{"MAJ.sh", "CLEAN.sh", "TEST_HW.sh", wipeChoice, "PRINT.sh"},
 	}
 	p := tea.NewProgram(m)
 	if _, err := p.Run(); err != nil {
@@ -29,6 +38,14 @@ func main() {
 func (m model) Init() tea.Cmd { return nil }
 
 func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.wipe != nil {
+		cmd, done := m.wipe.update(msg)
+		if done {
+			m.wipe = nil
+		}
+		return m, cmd
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.String() {
@@ -40,13 +57,17 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 
 		case "d":
-			dimension.Run()
+			probe.DetectDisplay()
 		case "down":
 			if m.cursor < len(m.choices)-1 {
 				m.cursor++
 			}
 		case "enter":
 			choice := m.choices[m.cursor]
+			if choice == wipeChoice {
+				m.wipe, m.output = newWipeState(), ""
+				return m, nil
+			}
 			out, _ := exec.Command("bash", "core/bin/"+choice).CombinedOutput()
 			m.output = string(out)
 		}
@@ -55,6 +76,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m model) View() string {
+	if m.wipe != nil {
+		return m.wipe.view()
+	}
 	s := "Select script to run:\n\n"
 	for i, choice := range m.choices {
 		cursor := " "
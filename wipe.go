@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gobox/internal/diagnostic/disk/operations"
+	"gobox/internal/probe"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// wipeChoice est l'entrée du menu qui remplace SHRED.sh
+const wipeChoice = "WIPE (effacement disque)"
+
+type wipeStep int
+
+const (
+	wipeSelect  wipeStep = iota // choix du disque et de la méthode
+	wipeConfirm                 // saisie du nom du disque
+	wipeRunning
+	wipeDone
+)
+
+// wipeState est l'écran d'effacement, actif tant qu'il n'est pas nil
+type wipeState struct {
+	step    wipeStep
+	disks   []string
+	methods []string
+	cursor  int
+	method  int
+	typed   string
+	err     error
+
+	events   chan tea.Msg
+	cancel   context.CancelFunc
+	progress operations.Progress
+	result   operations.WipeResult
+}
+
+type wipeProgressMsg operations.Progress
+
+type wipeDoneMsg struct {
+	result operations.WipeResult
+	err    error
+}
+
+func newWipeState() *wipeState {
+	w := &wipeState{}
+	for name := range operations.Methods {
+		w.methods = append(w.methods, name)
+	}
+	sort.Strings(w.methods)
+	w.method = sort.SearchStrings(w.methods, operations.MethodZero.Name)
+
+	// Les disques montés ou système ne sont pas proposés
+	disks, err := probe.ListDisks()
+	if err != nil {
+		w.err = err
+	}
+	for _, disk := range disks {
+		if operations.CheckNotInUse(disk) == nil {
+			w.disks = append(w.disks, disk)
+		}
+	}
+	return w
+}
+
+// update traite une touche ou un message d'effacement ; done indique le
+// retour au menu principal.
+func (w *wipeState) update(msg tea.Msg) (cmd tea.Cmd, done bool) {
+	switch msg := msg.(type) {
+	case wipeProgressMsg:
+		w.progress = operations.Progress(msg)
+		return waitWipe(w.events), false
+
+	case wipeDoneMsg:
+		w.cancel()
+		w.step, w.result, w.err = wipeDone, msg.result, msg.err
+		return nil, false
+
+	case tea.KeyMsg:
+		key := msg.String()
+		switch w.step {
+		case wipeSelect:
+			switch key {
+			case "esc", "q", "ctrl+c":
+				return nil, true
+			case "up":
+				if w.cursor > 0 {
+					w.cursor--
+				}
+			case "down":
+				if w.cursor < len(w.disks)-1 {
+					w.cursor++
+				}
+			case "m":
+				w.method = (w.method + 1) % len(w.methods)
+			case "enter":
+				if len(w.disks) > 0 {
+					w.step, w.typed = wipeConfirm, ""
+				}
+			}
+
+		case wipeConfirm:
+			switch msg.Type {
+			case tea.KeyEsc, tea.KeyCtrlC:
+				w.step = wipeSelect
+			case tea.KeyBackspace:
+				if w.typed != "" {
+					w.typed = w.typed[:len(w.typed)-1]
+				}
+			case tea.KeyEnter:
+				if w.typed != w.disks[w.cursor] {
+					w.step = wipeSelect
+					w.err = errors.New("confirmation refusée, rien n'a été écrit")
+					return nil, false
+				}
+				return w.start(), false
+			case tea.KeyRunes:
+				w.typed += string(msg.Runes)
+			}
+
+		case wipeRunning:
+			// Interruption propre : Wipe rend la main avec un résultat partiel
+			if key == "ctrl+c" || key == "esc" {
+				w.cancel()
+			}
+
+		case wipeDone:
+			if key == "enter" || key == "esc" || key == "q" || key == "ctrl+c" {
+				return nil, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// start lance l'effacement en tâche de fond ; la progression remonte par
+// w.events, lue par waitWipe.
+func (w *wipeState) start() tea.Cmd {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.step, w.err, w.cancel = wipeRunning, nil, cancel
+	w.events = make(chan tea.Msg, 1)
+
+	target := w.disks[w.cursor]
+	method := operations.Methods[w.methods[w.method]]
+	events := w.events
+	go func() {
+		result, err := operations.Wipe(ctx, target, operations.Options{
+			Method: method,
+			Verify: true,
+			OnProgress: func(p operations.Progress) {
+				// Ne jamais bloquer le moteur sur l'affichage
+				select {
+				case events <- wipeProgressMsg(p):
+				default:
+				}
+			},
+		})
+		events <- wipeDoneMsg{result, err}
+	}()
+	return waitWipe(events)
+}
+
+func waitWipe(events chan tea.Msg) tea.Cmd {
+	return func() tea.Msg { return <-events }
+}
+
+func (w *wipeState) view() string {
+	var s strings.Builder
+	s.WriteString("Effacement sécurisé d'un disque\n\n")
+
+	switch w.step {
+	case wipeSelect:
+		if len(w.disks) == 0 {
+			s.WriteString("Aucun disque effaçable (les disques montés ou système sont exclus).\n")
+		}
+		for i, disk := range w.disks {
+			cursor := " "
+			if i == w.cursor {
+				cursor = ">"
+			}
+			fmt.Fprintf(&s, "%s %s\n", cursor, disk)
+		}
+		method := operations.Methods[w.methods[w.method]]
+		fmt.Fprintf(&s, "\nMéthode : %s (%d passe(s), relecture finale)\n", method.Name, len(method.Passes))
+		s.WriteString("\nm : changer de méthode, entrée : effacer, échap : retour\n")
+
+	case wipeConfirm:
+		disk := w.disks[w.cursor]
+		fmt.Fprintf(&s, "⚠️  Toutes les données de %s seront détruites (%s).\n", disk, w.methods[w.method])
+		fmt.Fprintf(&s, "Tapez %q puis entrée pour confirmer, échap pour annuler : %s\n", disk, w.typed)
+
+	case wipeRunning:
+		p := w.progress
+		pct := 0.0
+		if p.BytesTotal > 0 {
+			pct = float64(p.BytesDone) * 100 / float64(p.BytesTotal)
+		}
+		fmt.Fprintf(&s, "%s : [%d/%d] %-6s %-6s %5.1f%%  %7.1f MB/s  ETA %s\n",
+			w.disks[w.cursor], p.Pass, p.Passes, p.Phase, p.Pattern, pct,
+			p.BytesPerSec/1e6, p.ETA.Round(time.Second))
+		s.WriteString("\néchap : interrompre\n")
+
+	case wipeDone:
+		r := w.result
+		for i, pass := range r.Passes {
+			fmt.Fprintf(&s, "Passe %d (%s) : %d octets en %s (%.1f MB/s)\n",
+				i+1, pass.Pattern, pass.Bytes, pass.Duration.Round(time.Second), pass.BytesPerSec/1e6)
+		}
+		switch {
+		case r.Verified:
+			fmt.Fprintf(&s, "Effacement de %s terminé et vérifié.\n", r.Target)
+		case r.VerifyFailures > 0:
+			fmt.Fprintf(&s, "⚠️  %d bloc(s) non conformes, premier écart à l'offset %d.\n",
+				r.VerifyFailures, r.FirstMismatch)
+		case w.err == nil:
+			fmt.Fprintf(&s, "Effacement de %s terminé.\n", r.Target)
+		}
+		s.WriteString("\nCertificat signé : utiliser \"gobox wipe\".\nentrée : retour au menu\n")
+	}
+
+	if w.err != nil {
+		fmt.Fprintf(&s, "\nErreur : %v\n", w.err)
+	}
+	return s.String()
+}